@@ -29,11 +29,13 @@ import (
 
 	"github.com/cloudwego/eino-examples/adk/multiagent/deep/params"
 	"github.com/cloudwego/eino-examples/adk/multiagent/deep/tools"
-	"github.com/cloudwego/eino-examples/adk/multiagent/deep/utils"
+
+	"github.com/jettjia/ai-code-example/eino/adk/02-multiagent/deep/utils"
 )
 
 func NewCodeAgent(ctx context.Context, operator commandline.Operator) (adk.Agent, error) {
 	cm, err := utils.NewChatModel(ctx,
+		utils.WithAgent("CodeAgent"),
 		utils.WithMaxTokens(14125),
 		utils.WithTemperature(float32(1)),
 		utils.WithTopP(float32(1)),
@@ -24,11 +24,11 @@ import (
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 
-	"github.com/cloudwego/eino-examples/adk/multiagent/deep/utils"
+	"github.com/jettjia/ai-code-example/eino/adk/02-multiagent/deep/utils"
 )
 
 func NewWebSearchAgent(ctx context.Context) (adk.Agent, error) {
-	cm, err := utils.NewChatModel(ctx)
+	cm, err := utils.NewChatModel(ctx, utils.WithAgent("WebSearchAgent"))
 	if err != nil {
 		return nil, err
 	}
@@ -18,9 +18,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/cloudwego/eino/adk"
@@ -35,12 +38,17 @@ import (
 	"github.com/jettjia/ai-code-example/eino/adk/02-multiagent/deep/params"
 	"github.com/jettjia/ai-code-example/eino/adk/02-multiagent/deep/tools"
 	"github.com/jettjia/ai-code-example/eino/adk/02-multiagent/deep/utils"
+	"github.com/jettjia/ai-code-example/eino/shared/checkpoint"
 
 	"github.com/cloudwego/eino-examples/adk/common/prints"
 	"github.com/cloudwego/eino-examples/adk/common/trace"
 )
 
+var resume = flag.String("resume", "", "task id of a stopped run, resumes it from its checkpoint")
+
 func main() {
+	flag.Parse()
+
 	// Set your own query here. e.g.
 	// query := schema.UserMessage("统计附件文件中推荐的小说名称及推荐次数，并将结果写到文件中。凡是带有《》内容都是小说名称，形成表格，表头为小说名称和推荐次数，同名小说只列一行，推荐次数相加")
 	// query := schema.UserMessage("Count the recommended novel names and recommended times in the attachment file, and write the results into the file. The content with "" is the name of the novel, forming a table. The header is the name of the novel and the number of recommendations. The novels with the same name are listed in one row, and the number of recommendations is added")
@@ -61,12 +69,28 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// uuid as task id
+	ckptConfig, err := checkpoint.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	store, err := checkpoint.NewStore(ckptConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// uuid as task id, it is the checkpoint id as well
 	id := uuid.New().String()
+	if *resume != "" {
+		if store == nil {
+			log.Fatal("-resume needs checkpoints, CHECKPOINT is off")
+		}
+		id = *resume
+	}
 
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		Agent:           agent,
 		EnableStreaming: true,
+		CheckPointStore: store,
 	})
 
 	wd, err := os.Getwd()
@@ -87,13 +111,19 @@ func main() {
 		workdir = filepath.Join(wd, "playground", id)
 	}
 
-	if err = os.Mkdir(workdir, 0755); err != nil {
-		log.Fatal(err)
-	}
+	if *resume == "" {
+		if err = os.Mkdir(workdir, 0755); err != nil {
+			log.Fatal(err)
+		}
 
-	if err = os.CopyFS(workdir, os.DirFS(inputFileDir)); err != nil {
-		log.Fatal(err)
+		if err = os.CopyFS(workdir, os.DirFS(inputFileDir)); err != nil {
+			log.Fatal(err)
+		}
+	} else if _, err = os.Stat(workdir); err != nil {
+		// the files the stopped run wrote are kept in its work dir
+		log.Fatalf("work dir of task %s: %v", id, err)
 	}
+	log.Printf("task %s, work dir %s", id, workdir)
 
 	previews, err := generic.PreviewPath(workdir)
 	if err != nil {
@@ -110,33 +140,72 @@ func main() {
 
 	ctx, endSpanFn := startSpanFn(ctx, "plan-execute-replan", query)
 
-	iter := runner.Run(ctx, []*schema.Message{query})
+	ctx, stop := checkpoint.WithStop(ctx)
+	go stopOnSignal(stop)
+	if store != nil {
+		// every tool call saves a checkpoint first, so that a crashed run can be resumed
+		ctx = checkpoint.WithSteps(ctx)
+	}
+
+	var iter *adk.AsyncIterator[*adk.AgentEvent]
+	if *resume != "" {
+		iter, err = runner.Resume(ctx, id)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		iter = runner.Run(ctx, []*schema.Message{query}, adk.WithCheckPointID(id))
+	}
 
 	var (
 		lastMessage       adk.Message
 		lastMessageStream *schema.StreamReader[adk.Message]
+		interrupted       bool
+		failed            bool
 	)
 
 	for {
-		event, ok := iter.Next()
-		if !ok {
-			break
-		}
-		if event.Output != nil && event.Output.MessageOutput != nil {
-			if lastMessageStream != nil {
-				lastMessageStream.Close()
+		step := false
+		for {
+			event, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if event.Action != nil && event.Action.Interrupted != nil {
+				// the checkpoint of a step is saved, the run goes on below
+				if checkpoint.IsStep(event.Action.Interrupted.InterruptContexts) {
+					step = true
+					continue
+				}
+				interrupted = true
 			}
-			if event.Output.MessageOutput.IsStreaming {
-				cpStream := event.Output.MessageOutput.MessageStream.Copy(2)
-				event.Output.MessageOutput.MessageStream = cpStream[0]
-				lastMessage = nil
-				lastMessageStream = cpStream[1]
-			} else {
-				lastMessage = event.Output.MessageOutput.Message
-				lastMessageStream = nil
+			if event.Err != nil {
+				failed = true
 			}
+			if event.Output != nil && event.Output.MessageOutput != nil {
+				if lastMessageStream != nil {
+					lastMessageStream.Close()
+				}
+				if event.Output.MessageOutput.IsStreaming {
+					cpStream := event.Output.MessageOutput.MessageStream.Copy(2)
+					event.Output.MessageOutput.MessageStream = cpStream[0]
+					lastMessage = nil
+					lastMessageStream = cpStream[1]
+				} else {
+					lastMessage = event.Output.MessageOutput.Message
+					lastMessageStream = nil
+				}
+			}
+			prints.Event(event)
+		}
+		if !step || failed {
+			break
+		}
+		if iter, err = runner.Resume(ctx, id); err != nil {
+			log.Printf("failed to resume task %s after its checkpoint: %v", id, err)
+			failed = true
+			break
 		}
-		prints.Event(event)
 	}
 
 	if lastMessage != nil {
@@ -148,13 +217,36 @@ func main() {
 		endSpanFn(ctx, "finished without output message")
 	}
 
+	switch {
+	case interrupted:
+		log.Printf("task %s stopped, run with -resume %s to continue it", id, id)
+	case !failed && store != nil:
+		// a failed run keeps its checkpoint, so that it can be resumed again
+		if err = store.Delete(ctx, id); err != nil {
+			log.Printf("failed to delete the checkpoint of task %s: %v", id, err)
+		}
+	}
+
 	time.Sleep(time.Second * 30)
 }
 
+// stopOnSignal stops the run at its next tool call on the first Ctrl+C or SIGTERM, so that
+// it is checkpointed, and quits on the second.
+func stopOnSignal(stop func()) {
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+	log.Printf("stopping at the next tool call, press Ctrl+C again to quit now")
+	stop()
+	<-sig
+	os.Exit(1)
+}
+
 func newExcelAgent(ctx context.Context) (adk.Agent, error) {
 	operator := &LocalOperator{}
 
 	cm, err := utils.NewChatModel(ctx,
+		utils.WithAgent("ExcelAgent"),
 		utils.WithMaxTokens(4096),
 		utils.WithTemperature(float32(0)),
 		utils.WithTopP(float32(0)),
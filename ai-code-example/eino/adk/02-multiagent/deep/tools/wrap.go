@@ -28,6 +28,8 @@ import (
 	"github.com/cloudwego/eino/schema"
 	jsoniter "github.com/json-iterator/go"
 
+	"github.com/jettjia/ai-code-example/eino/shared/checkpoint"
+
 	"github.com/cloudwego/eino-examples/adk/multiagent/deep/utils"
 )
 
@@ -54,6 +56,11 @@ func (w *wrapTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 }
 
 func (w *wrapTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	// the run is checkpointed here before every call, a stopping process ends it here
+	if err := checkpoint.Save(ctx); err != nil {
+		return "", err
+	}
+
 	for _, pre := range w.preprocess {
 		var err error
 		argumentsInJSON, err = pre(ctx, w.baseTool, argumentsInJSON)
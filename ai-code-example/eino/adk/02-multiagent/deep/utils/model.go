@@ -18,82 +18,49 @@ package utils
 
 import (
 	"context"
-	"fmt"
-	"os"
 
-	"github.com/cloudwego/eino-ext/components/model/ark"
 	"github.com/cloudwego/eino-ext/components/model/openai"
 	"github.com/cloudwego/eino/components/model"
-	arkmodel "github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
 )
 
 type CreateChatModelOption func(o *option)
 
+// NewChatModel returns the model routed to the agent of WithAgent from llm.Default(),
+// see llm.FromEnv for the env vars and LLM_CONFIG.
 func NewChatModel(ctx context.Context, opts ...CreateChatModelOption) (cm model.ToolCallingChatModel, err error) {
 	o := &option{}
 	for _, opt := range opts {
 		opt(o)
 	}
 
-	if modelName := os.Getenv("ARK_MODEL"); modelName != "" {
-		conf := &ark.ChatModelConfig{
-			APIKey:      os.Getenv("ARK_API_KEY"),
-			BaseURL:     os.Getenv("ARK_BASE_URL"),
-			Region:      os.Getenv("ARK_REGION"),
-			Model:       modelName,
-			MaxTokens:   o.MaxTokens,
-			Temperature: o.Temperature,
-			TopP:        o.TopP,
-		}
-		if o.DisableThinking != nil && *o.DisableThinking {
-			conf.Thinking = &arkmodel.Thinking{
-				Type: arkmodel.ThinkingTypeDisabled,
-			}
-		}
-		if o.JsonSchema != nil {
-			conf.ResponseFormat = &ark.ResponseFormat{
-				Type: arkmodel.ResponseFormatJSONSchema,
-				JSONSchema: &arkmodel.ResponseFormatJSONSchemaJSONSchemaParam{
-					Name:        o.JsonSchema.Name,
-					Description: o.JsonSchema.Description,
-					Schema:      o.JsonSchema.JSONSchema,
-					Strict:      o.JsonSchema.Strict,
-				},
-			}
-		}
-		cm, err = ark.NewChatModel(ctx, conf)
-
-	} else if modelName = os.Getenv("OPENAI_MODEL"); modelName != "" {
-		conf := &openai.ChatModelConfig{
-			APIKey: os.Getenv("OPENAI_API_KEY"),
-			ByAzure: func() bool {
-				return os.Getenv("OPENAI_BY_AZURE") == "true"
-			}(),
-			BaseURL:     os.Getenv("OPENAI_BASE_URL"),
-			Model:       modelName,
-			MaxTokens:   o.MaxTokens,
-			Temperature: o.Temperature,
-			TopP:        o.TopP,
-		}
-		if o.JsonSchema != nil {
-			conf.ResponseFormat = &openai.ChatCompletionResponseFormat{
-				Type:       openai.ChatCompletionResponseFormatTypeJSONSchema,
-				JSONSchema: o.JsonSchema,
-			}
-		}
-		cm, err = openai.NewChatModel(ctx, conf)
-	}
+	registry, err := llm.Default()
 	if err != nil {
 		return nil, err
 	}
-	if cm == nil {
-		return nil, fmt.Errorf("no model config")
-	}
 
-	return cm, nil
+	var llmOpts []llm.Option
+	if o.MaxTokens != nil {
+		llmOpts = append(llmOpts, llm.WithMaxTokens(*o.MaxTokens))
+	}
+	if o.Temperature != nil {
+		llmOpts = append(llmOpts, llm.WithTemperature(*o.Temperature))
+	}
+	if o.TopP != nil {
+		llmOpts = append(llmOpts, llm.WithTopP(*o.TopP))
+	}
+	if o.DisableThinking != nil && *o.DisableThinking {
+		llmOpts = append(llmOpts, llm.WithDisableThinking())
+	}
+	if o.JsonSchema != nil {
+		llmOpts = append(llmOpts, llm.WithResponseFormatJsonSchema(o.JsonSchema))
+	}
+	return registry.For(ctx, o.Agent, llmOpts...)
 }
 
 type option struct {
+	Agent           string
 	MaxTokens       *int
 	Temperature     *float32
 	TopP            *float32
@@ -101,6 +68,13 @@ type option struct {
 	JsonSchema      *openai.ChatCompletionResponseFormatJSONSchema
 }
 
+// WithAgent selects the model by the routes of the registry, e.g. LLM_ROUTES=WebSearchAgent=qwen.
+func WithAgent(name string) CreateChatModelOption {
+	return func(o *option) {
+		o.Agent = name
+	}
+}
+
 func WithMaxTokens(maxTokens int) CreateChatModelOption {
 	return func(o *option) {
 		o.MaxTokens = &maxTokens
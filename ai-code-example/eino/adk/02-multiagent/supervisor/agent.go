@@ -9,12 +9,11 @@ import (
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 
-	"github.com/cloudwego/eino-examples/adk/common/model"
 	"github.com/cloudwego/eino-examples/flow/agent/multiagent/plan_execute/tools"
 )
 
 func buildSearchAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m := newChatModel(ctx, "research_agent")
 
 	type searchReq struct {
 		Query string `json:"query"`
@@ -61,7 +60,7 @@ func buildSearchAgent(ctx context.Context) (adk.Agent, error) {
 }
 
 func buildMathAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m := newChatModel(ctx, "math_agent")
 
 	type addReq struct {
 		A float64 `json:"a"`
@@ -146,7 +145,7 @@ func buildMathAgent(ctx context.Context) (adk.Agent, error) {
 }
 
 func buildSupervisor(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m := newChatModel(ctx, "supervisor")
 
 	sv, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "supervisor",
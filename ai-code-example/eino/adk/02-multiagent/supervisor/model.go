@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/cloudwego/eino/components/model"
+
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
+)
+
+// newChatModel returns the model routed to agent, see llm.FromEnv, e.g. LLM_ROUTES=math_agent=qwen.
+// The registry records or replays the model traffic when FAKE_CASSETTE_MODE is set,
+// replaying does not create the real model at all.
+func newChatModel(ctx context.Context, agent string) model.ToolCallingChatModel {
+	registry, err := llm.Default()
+	if err != nil {
+		log.Fatalf("load models failed: %v", err)
+	}
+	cm, err := registry.For(ctx, agent)
+	if err != nil {
+		log.Fatalf("create chat model failed: %v", err)
+	}
+	return cm
+}
@@ -6,10 +6,15 @@ import (
 
 	"github.com/cloudwego/eino-ext/components/model/openai"
 	"github.com/cloudwego/eino/components/model"
+
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
 )
 
 // newChatModel component initialization function of node 'ChatModel1' in graph 'test'
 func newChatModel(ctx context.Context) (cm model.BaseChatModel, err error) {
+	if fake.Enabled() {
+		return fake.NewChatModelFromEnv()
+	}
 	// TODO Modify component configuration here.
 	config := &openai.ChatModelConfig{
 		Model:   os.Getenv("OPENAI_MODEL"),
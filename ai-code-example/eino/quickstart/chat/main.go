@@ -3,6 +3,10 @@ package main
 import (
 	"context"
 	"log"
+
+	"github.com/cloudwego/eino/components/model"
+
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
 )
 
 func main() {
@@ -17,6 +21,7 @@ func main() {
 	log.Printf("===create llm===\n")
 	cm := createQwenChatModel(ctx)
 	// cm := createOllamaChatModel(ctx)
+	// cm := createChatModel(ctx, "") // LLM_CONFIG 中的默认模型
 	log.Printf("create llm success\n\n")
 
 	log.Printf("===llm generate===\n")
@@ -27,3 +32,16 @@ func main() {
 	streamResult := stream(ctx, cm, messages)
 	reportStream(streamResult)
 }
+
+// createChatModel 按名称从 LLM_CONFIG 配置的模型中创建，模型失败时会切换到配置的 fallback 模型
+func createChatModel(ctx context.Context, name string) model.BaseChatModel {
+	registry, err := llm.FromEnv()
+	if err != nil {
+		log.Fatalf("load models failed: %v", err)
+	}
+	chatModel, err := registry.Get(ctx, name)
+	if err != nil {
+		log.Fatalf("create chat model %s failed: %v", name, err)
+	}
+	return chatModel
+}
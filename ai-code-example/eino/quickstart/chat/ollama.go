@@ -4,14 +4,16 @@ import (
 	"context"
 	"log"
 
-	"github.com/cloudwego/eino-ext/components/model/ollama"
 	"github.com/cloudwego/eino/components/model"
+
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
 )
 
-func createOllamaChatModel(ctx context.Context) model.ChatModel {
-	chatModel, err := ollama.NewChatModel(ctx, &ollama.ChatModelConfig{
-		BaseURL: "http://localhost:11434", // Ollama 服务地址
-		Model:   "llama2",                 // 模型名称
+func createOllamaChatModel(ctx context.Context) model.BaseChatModel {
+	chatModel, err := llm.New(ctx, &llm.Config{
+		Provider: llm.ProviderOllama,
+		BaseURL:  "http://localhost:11434", // Ollama 服务地址
+		Model:    "llama2",                 // 模型名称
 	})
 	if err != nil {
 		log.Fatalf("create ollama chat model failed: %v", err)
@@ -5,15 +5,16 @@ import (
 	"log"
 	"os"
 
-	"github.com/cloudwego/eino-ext/components/model/openai"
 	"github.com/cloudwego/eino/components/model"
+
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
 )
 
-func createOpenAIChatModel(ctx context.Context) model.ChatModel {
-	key := os.Getenv("OPENAI_API_KEY")
-	chatModel, err := openai.NewChatModel(ctx, &openai.ChatModelConfig{
-		Model:  "gpt-4o", // 使用的模型版本
-		APIKey: key,      // OpenAI API 密钥
+func createOpenAIChatModel(ctx context.Context) model.BaseChatModel {
+	chatModel, err := llm.New(ctx, &llm.Config{
+		Provider: llm.ProviderOpenAI,
+		Model:    "gpt-4o",                    // 使用的模型版本
+		APIKey:   os.Getenv("OPENAI_API_KEY"), // OpenAI API 密钥
 	})
 	if err != nil {
 		log.Fatalf("create openai chat model failed, err=%v", err)
@@ -5,16 +5,16 @@ import (
 	"log"
 	"os"
 
-	"github.com/cloudwego/eino-ext/components/model/qwen"
 	"github.com/cloudwego/eino/components/model"
+
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
 )
 
-func createQwenChatModel(ctx context.Context) model.ChatModel {
-	apiKey := os.Getenv("DASHSCOPE_API_KEY")
-	chatModel, err := qwen.NewChatModel(ctx, &qwen.ChatModelConfig{
+func createQwenChatModel(ctx context.Context) model.BaseChatModel {
+	chatModel, err := llm.New(ctx, &llm.Config{
+		Provider:    llm.ProviderQwen,
 		BaseURL:     "https://dashscope.aliyuncs.com/compatible-mode/v1",
-		APIKey:      apiKey,
-		Timeout:     0,
+		APIKey:      os.Getenv("DASHSCOPE_API_KEY"),
 		Model:       "qwen-max",
 		MaxTokens:   of(2048),
 		Temperature: of(float32(0.7)),
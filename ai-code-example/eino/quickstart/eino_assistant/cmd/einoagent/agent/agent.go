@@ -18,76 +18,143 @@ package agent
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"sync"
 
-	"github.com/cloudwego/eino-ext/callbacks/langfuse"
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/eino/einoagent"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/event"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/limiter"
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/mem"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/metrics"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/trace"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tracing"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/usage"
 )
 
-var memory = mem.GetDefaultMemory()
+// memory keeps the conversations, it is created in Init
+var memory *mem.SimpleMemory
 
 var cbHandler callbacks.Handler
 
+// traces records the callbacks of every turn, see HandleTraces
+var traces *trace.Store
+
+// runner is built once in Init and shared by all conversations
+var runner *einoagent.Agent
+
+// runs keeps the events of the latest turn of every conversation for resuming
+var runs = event.NewHub()
+
+// turns serializes the turns of a conversation and limits the turns running at the same time
+var turns *limiter.Limiter
+
+// shutdownTracing flushes the traces of the tracing provider
+var shutdownTracing = func(context.Context) error { return nil }
+
 var once sync.Once
 
-func Init() error {
+// Init builds the agent and everything the handlers share from cfg, config.Get() if nil.
+// Only the first call takes effect.
+func Init(cfg *config.Config) error {
+	if cfg == nil {
+		cfg = config.Get()
+	}
 	var err error
 	once.Do(func() {
-		os.MkdirAll("log", 0755)
-		var f *os.File
-		f, err = os.OpenFile("log/eino.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if err != nil {
+		memory = mem.NewSimpleMemory(mem.SimpleMemoryConfig{
+			Dir:           cfg.Memory.Dir,
+			MaxWindowSize: cfg.Memory.MaxWindowSize,
+		})
+		if memory == nil {
+			err = fmt.Errorf("failed to create memory dir %s", cfg.Memory.Dir)
 			return
 		}
 
-		cbConfig := &LogCallbackConfig{
+		traces, err = trace.NewStore(&trace.Config{
+			Dir:    cfg.Server.LogDir,
 			Detail: true,
-			Writer: f,
-		}
-		if os.Getenv("DEBUG") == "true" {
-			cbConfig.Debug = true
+			Echo:   cfg.Server.Debug,
+		})
+		if err != nil {
+			return
 		}
 		// this is for invoke option of WithCallback
-		cbHandler = LogCallback(cbConfig)
+		cbHandler = traces.Handler()
 
 		// init global callback, for trace and metrics
-		if os.Getenv("LANGFUSE_PUBLIC_KEY") != "" && os.Getenv("LANGFUSE_SECRET_KEY") != "" {
-			fmt.Println("[eino agent] INFO: use langfuse as callback, watch at: https://cloud.langfuse.com")
-			cbh, _ := langfuse.NewLangfuseHandler(&langfuse.Config{
-				Host:      "https://cloud.langfuse.com",
-				PublicKey: os.Getenv("LANGFUSE_PUBLIC_KEY"),
-				SecretKey: os.Getenv("LANGFUSE_SECRET_KEY"),
-				Name:      "Eino Assistant",
-				Public:    true,
-				Release:   "release/v0.0.1",
-				UserID:    "eino_god",
-				Tags:      []string{"eino", "assistant"},
-			})
-			callbacks.InitCallbackHandlers([]callbacks.Handler{cbh})
+		handlers := []callbacks.Handler{metrics.Handler()}
+		var tracer callbacks.Handler
+		tracer, shutdownTracing, err = tracing.Init(context.Background(), &cfg.Tracing)
+		if err != nil {
+			return
+		}
+		if tracer != nil {
+			handlers = append(handlers, tracer)
 		}
+		callbacks.InitCallbackHandlers(handlers)
+
+		turns = limiter.NewLimiter(&limiter.Config{
+			MaxRunning: cfg.Agent.MaxRunningChats,
+			MaxQueued:  cfg.Agent.MaxQueuedChats,
+		})
+		metrics.Default.NewGaugeFunc("eino_assistant_turns_running", "Chat turns running.", func() float64 {
+			return float64(turns.Stats().Running)
+		})
+		metrics.Default.NewGaugeFunc("eino_assistant_turns_queued", "Chat turns waiting for their conversation or a slot.", func() float64 {
+			return float64(turns.Stats().Queued)
+		})
+
+		runner, err = einoagent.NewAgent(context.Background(), &einoagent.BuildConfig{Config: cfg})
 	})
 	return err
 }
 
-func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[*schema.Message], error) {
+// Close waits for the running chats until ctx is done and releases the agent.
+func Close(ctx context.Context) error {
+	if runner == nil {
+		return nil
+	}
+	err := runner.Close(ctx)
+	traces.Close()
+	if terr := shutdownTracing(ctx); terr != nil && err == nil {
+		err = terr
+	}
+	return err
+}
 
-	runner, err := einoagent.BuildEinoAgent(ctx, &einoagent.BuildConfig{
-		EinoAgent: &einoagent.EinoAgentBuildConfig{},
-	})
+// RunAgent streams the answer of one turn, cancelling ctx stops the turn.
+// The question is saved before the answer, so that it is kept even if the turn fails,
+// a cancelled or failed answer is saved with its status, see mem.AnswerMessage.
+// A turn waits for the previous turn of the conversation to be saved before reading the history,
+// it fails with limiter.ErrQueueFull if too many turns are waiting.
+func RunAgent(ctx context.Context, id string, msg string, opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+	release, err := acquireTurn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return runTurn(ctx, id, msg, release, opts...)
+}
+
+// acquireTurn waits for the previous turn of the conversation and a running slot.
+func acquireTurn(ctx context.Context, id string) (release func(), err error) {
+	release, err = turns.Acquire(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build agent graph: %w", err)
+		return nil, fmt.Errorf("failed to wait for turn: %w", err)
 	}
+	return release, nil
+}
 
+// runTurn is RunAgent once the turn is acquired, release is called when the answer is saved
+// or the turn fails to start.
+func runTurn(ctx context.Context, id string, msg string, release func(), opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
 	conversation := memory.GetConversation(id, true)
 
 	userMessage := &einoagent.UserMessage{
@@ -96,8 +163,36 @@ func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[
 		History: conversation.GetMessages(),
 	}
 
-	sr, err := runner.Stream(ctx, userMessage, compose.WithCallbacks(cbHandler))
+	// add user input to history, before the turn is checkpointed, so that a resumed turn has it
+	conversation.Append(schema.UserMessage(msg))
+
+	return saveTurn(ctx, conversation, release, func(opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+		return runner.Stream(ctx, userMessage, opts...)
+	}, opts...)
+}
+
+// resumeTurn streams the rest of the turn of the conversation from its checkpoint, e.g. after
+// a crash, it fails with einoagent.ErrNoCheckPoint if there is none. Its question is saved already.
+func resumeTurn(ctx context.Context, id string, release func(), opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+	conversation := memory.GetConversation(id, true)
+	return saveTurn(ctx, conversation, release, func(opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+		return runner.Resume(ctx, id, opts...)
+	}, opts...)
+}
+
+// saveTurn starts the turn and saves its answer to the conversation, a turn paused for
+// approval has no answer yet.
+func saveTurn(ctx context.Context, conversation *mem.Conversation, release func(),
+	start func(opts ...compose.Option) (*schema.StreamReader[*schema.Message], error), opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+	recorder := usage.NewRecorder()
+	sr, err := start(append(opts, compose.WithCallbacks(cbHandler, recorder.Handler()))...)
 	if err != nil {
+		if !errors.Is(err, einoagent.ErrNoCheckPoint) && approval.Pending(err) == nil {
+			answer := mem.AnswerMessage(ctx, nil, err)
+			mem.SetTurnUsage(answer, recorder.Report())
+			conversation.Append(answer)
+		}
+		release()
 		return nil, fmt.Errorf("failed to stream: %w", err)
 	}
 
@@ -105,78 +200,31 @@ func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[
 
 	go func() {
 		// for save to memory
-		fullMsgs := make([]*schema.Message, 0)
-
-		defer func() {
-			// close stream if you used it
-			srs[1].Close()
-
-			// add user input to history
-			conversation.Append(schema.UserMessage(msg))
+		var (
+			chunks []*schema.Message
+			err    error
+		)
+		defer release()
+		defer srs[1].Close()
 
-			fullMsg, err := schema.ConcatMessages(fullMsgs)
-			if err != nil {
-				fmt.Println("error concatenating messages: ", err.Error())
-			}
-			// add agent response to history
-			conversation.Append(fullMsg)
-		}()
-
-	outer:
 		for {
-			select {
-			case <-ctx.Done():
-				fmt.Println("context done", ctx.Err())
-				return
-			default:
-				chunk, err := srs[1].Recv()
-				if err != nil {
-					if errors.Is(err, io.EOF) {
-						break outer
-					}
-				}
-
-				fullMsgs = append(fullMsgs, chunk)
+			var chunk *schema.Message
+			chunk, err = srs[1].Recv()
+			if errors.Is(err, io.EOF) {
+				err = nil
+				break
 			}
+			if err != nil {
+				break
+			}
+			chunks = append(chunks, chunk)
 		}
+
+		// add agent response to history, with the tokens the turn used
+		answer := mem.AnswerMessage(ctx, chunks, err)
+		mem.SetTurnUsage(answer, recorder.Report())
+		conversation.Append(answer)
 	}()
 
 	return srs[0], nil
 }
-
-type LogCallbackConfig struct {
-	Detail bool
-	Debug  bool
-	Writer io.Writer
-}
-
-func LogCallback(config *LogCallbackConfig) callbacks.Handler {
-	if config == nil {
-		config = &LogCallbackConfig{
-			Detail: true,
-			Writer: os.Stdout,
-		}
-	}
-	if config.Writer == nil {
-		config.Writer = os.Stdout
-	}
-	builder := callbacks.NewHandlerBuilder()
-	builder.OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
-		fmt.Fprintf(config.Writer, "[view]: start [%s:%s:%s]\n", info.Component, info.Type, info.Name)
-		if config.Detail {
-			var b []byte
-			if config.Debug {
-				b, _ = json.MarshalIndent(input, "", "  ")
-			} else {
-				b, _ = json.Marshal(input)
-			}
-			fmt.Fprintf(config.Writer, "%s\n", string(b))
-		}
-		return ctx
-	})
-	builder.OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
-		fmt.Fprintf(config.Writer, "[view]: end [%s:%s:%s]\n", info.Component, info.Type, info.Name)
-		return ctx
-	})
-	return builder.Build()
-}
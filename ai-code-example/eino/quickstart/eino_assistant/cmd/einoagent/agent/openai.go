@@ -0,0 +1,338 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/sse"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/eino/einoagent"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/event"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/trace"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/usage"
+)
+
+// ModelName is the model reported to OpenAI clients, the request model is only echoed back.
+const ModelName = "eino-assistant"
+
+// ConversationHeader selects the mem conversation, same as the user field of the request.
+const ConversationHeader = "X-Conversation-ID"
+
+var conversationIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+type ChatCompletionRequest struct {
+	Model         string                   `json:"model"`
+	Messages      []*ChatCompletionMessage `json:"messages"`
+	Stream        bool                     `json:"stream"`
+	StreamOptions *StreamOptions           `json:"stream_options,omitempty"`
+	User          string                   `json:"user,omitempty"`
+	// Filter is not part of the OpenAI schema, it restricts retrieval, see redispkg.FilterSyntax.
+	Filter string `json:"filter,omitempty"`
+}
+
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type ChatCompletionMessage struct {
+	Role    string         `json:"role,omitempty"`
+	Content MessageContent `json:"content"`
+}
+
+// MessageContent accepts both a string and an array of content parts, only text parts are kept.
+type MessageContent string
+
+func (m *MessageContent) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*m = MessageContent(s)
+		return nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(b, &parts); err != nil {
+		return fmt.Errorf("content must be a string or an array of content parts")
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" {
+			texts = append(texts, p.Text)
+		}
+	}
+	*m = MessageContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+type ChatCompletionResponse struct {
+	ID      string                  `json:"id"`
+	Object  string                  `json:"object"`
+	Created int64                   `json:"created"`
+	Model   string                  `json:"model"`
+	Choices []*ChatCompletionChoice `json:"choices"`
+	Usage   *event.Usage            `json:"usage,omitempty"`
+}
+
+type ChatCompletionChoice struct {
+	Index        int                    `json:"index"`
+	Message      *ChatCompletionMessage `json:"message,omitempty"`
+	Delta        *ChatCompletionMessage `json:"delta,omitempty"`
+	FinishReason *string                `json:"finish_reason"`
+}
+
+type openAIError struct {
+	Error *openAIErrorBody `json:"error"`
+}
+
+type openAIErrorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// BindOpenAIRoutes serves the assistant with the OpenAI chat completions API, r is usually /v1.
+func BindOpenAIRoutes(r *route.RouterGroup, cfg *config.Config) error {
+	if err := Init(cfg); err != nil {
+		return err
+	}
+
+	r.POST("/chat/completions", HandleChatCompletions)
+	r.GET("/models", HandleModels)
+	return nil
+}
+
+func HandleModels(ctx context.Context, c *app.RequestContext) {
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"object": "list",
+		"data": []map[string]interface{}{
+			{"id": ModelName, "object": "model", "owned_by": "eino"},
+		},
+	})
+}
+
+// HandleChatCompletions runs the EinoAgent graph for the last user message.
+// With a conversation id (user field or X-Conversation-ID header) the history comes from
+// and is saved to that mem conversation, earlier messages of the request are ignored.
+// Without it the request messages are the history and nothing is saved.
+func HandleChatCompletions(ctx context.Context, c *app.RequestContext) {
+	req := &ChatCompletionRequest{}
+	if err := json.Unmarshal(c.Request.Body(), req); err != nil {
+		writeOpenAIError(c, consts.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if len(req.Messages) == 0 || req.Messages[len(req.Messages)-1].Role != string(schema.User) {
+		writeOpenAIError(c, consts.StatusBadRequest, "the last message must be a user message")
+		return
+	}
+	query := string(req.Messages[len(req.Messages)-1].Content)
+
+	conversationID := string(c.GetHeader(ConversationHeader))
+	if conversationID == "" {
+		conversationID = req.User
+	}
+	if conversationID != "" && !conversationIDPattern.MatchString(conversationID) {
+		writeOpenAIError(c, consts.StatusBadRequest, "invalid conversation id, only letters, digits, _ . - are allowed")
+		return
+	}
+
+	var opts []compose.Option
+	if req.Filter != "" {
+		opt, err := einoagent.WithRetrieverFilter(req.Filter)
+		if err != nil {
+			writeOpenAIError(c, consts.StatusBadRequest, err.Error())
+			return
+		}
+		opts = append(opts, opt)
+	}
+
+	completionID := fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano())
+	run := event.NewRun(ctx, conversationID)
+	collector := event.NewCollector(run)
+	opts = append(opts, compose.WithCallbacks(collector.Handler()))
+	// the run is not resumable, the turn stops as soon as the client goes away
+	defer run.Cancel()
+	runCtx := trace.WithRun(run.Context(), run.ID, conversationID)
+	c.Response.Header.Set(RunIDHeader, run.ID)
+
+	var (
+		sr  *schema.StreamReader[*schema.Message]
+		err error
+	)
+	if conversationID != "" {
+		sr, err = RunAgent(runCtx, conversationID, query, opts...)
+	} else {
+		// a stateless turn only takes a running slot, it is released once the response is written
+		var release func()
+		release, err = turns.Acquire(runCtx, completionID)
+		if err == nil {
+			defer release()
+			sr, err = runner.Stream(runCtx, &einoagent.UserMessage{
+				ID:      completionID,
+				Query:   query,
+				History: toHistory(req.Messages[:len(req.Messages)-1]),
+			}, append(opts, compose.WithCallbacks(cbHandler, usage.NewRecorder().Handler()))...)
+		}
+	}
+	if err != nil {
+		log.Printf("[OpenAI] Error running agent: %v\n", err)
+		writeOpenAIError(c, errorStatus(err), err.Error())
+		return
+	}
+	defer sr.Close()
+
+	resp := &ChatCompletionResponse{
+		ID:      completionID,
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+	}
+	if resp.Model == "" {
+		resp.Model = ModelName
+	}
+
+	if req.Stream {
+		streamCompletion(c, resp, sr, collector, req.StreamOptions != nil && req.StreamOptions.IncludeUsage)
+		return
+	}
+
+	var chunks []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeOpenAIError(c, errorStatus(err), err.Error())
+			return
+		}
+		chunks = append(chunks, chunk)
+	}
+	msg, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		writeOpenAIError(c, consts.StatusInternalServerError, err.Error())
+		return
+	}
+
+	stop := "stop"
+	resp.Object = "chat.completion"
+	resp.Choices = []*ChatCompletionChoice{{
+		Message:      &ChatCompletionMessage{Role: string(schema.Assistant), Content: MessageContent(msg.Content)},
+		FinishReason: &stop,
+	}}
+	resp.Usage = collector.Usage()
+	c.JSON(consts.StatusOK, resp)
+}
+
+func streamCompletion(c *app.RequestContext, resp *ChatCompletionResponse, sr *schema.StreamReader[*schema.Message], collector *event.Collector, includeUsage bool) {
+	s := sse.NewStream(c)
+	defer c.Flush()
+
+	resp.Object = "chat.completion.chunk"
+	publish := func(v any) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			log.Printf("[OpenAI] Error marshaling chunk: %v\n", err)
+			return false
+		}
+		if err = s.Publish(&sse.Event{Data: data}); err != nil {
+			log.Printf("[OpenAI] Error publishing chunk: %v\n", err)
+			return false
+		}
+		return true
+	}
+	chunk := func(delta *ChatCompletionMessage, finishReason *string) *ChatCompletionResponse {
+		r := *resp
+		r.Choices = []*ChatCompletionChoice{{Delta: delta, FinishReason: finishReason}}
+		return &r
+	}
+
+	if !publish(chunk(&ChatCompletionMessage{Role: string(schema.Assistant)}, nil)) {
+		return
+	}
+	for {
+		msg, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			publish(&openAIError{Error: &openAIErrorBody{Message: err.Error(), Type: "server_error"}})
+			return
+		}
+		if msg.Content == "" {
+			continue
+		}
+		if !publish(chunk(&ChatCompletionMessage{Content: MessageContent(msg.Content)}, nil)) {
+			return
+		}
+	}
+
+	stop := "stop"
+	if !publish(chunk(&ChatCompletionMessage{}, &stop)) {
+		return
+	}
+	if includeUsage {
+		r := *resp
+		r.Choices = []*ChatCompletionChoice{}
+		r.Usage = collector.Usage()
+		if !publish(&r) {
+			return
+		}
+	}
+	if err := s.Publish(&sse.Event{Data: []byte("[DONE]")}); err != nil {
+		log.Printf("[OpenAI] Error publishing chunk: %v\n", err)
+	}
+}
+
+func toHistory(messages []*ChatCompletionMessage) []*schema.Message {
+	history := make([]*schema.Message, 0, len(messages))
+	for _, m := range messages {
+		switch schema.RoleType(m.Role) {
+		case schema.System:
+			history = append(history, schema.SystemMessage(string(m.Content)))
+		case schema.User:
+			history = append(history, schema.UserMessage(string(m.Content)))
+		case schema.Assistant:
+			history = append(history, schema.AssistantMessage(string(m.Content), nil))
+		}
+	}
+	return history
+}
+
+func writeOpenAIError(c *app.RequestContext, status int, message string) {
+	typ := "invalid_request_error"
+	switch {
+	case status == consts.StatusTooManyRequests:
+		typ = "rate_limit_exceeded"
+	case status >= consts.StatusInternalServerError:
+		typ = "server_error"
+	}
+	c.JSON(status, &openAIError{Error: &openAIErrorBody{Message: message, Type: typ}})
+}
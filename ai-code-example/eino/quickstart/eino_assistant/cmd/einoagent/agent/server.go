@@ -17,42 +17,68 @@
 package agent
 
 import (
-	"bufio"
 	"context"
 	"embed"
+	"encoding/json"
 	"errors"
 	"io"
 	"log"
 	"mime"
-	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"github.com/cloudwego/hertz/pkg/route"
 	"github.com/hertz-contrib/sse"
 
-	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/mem"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/eino/einoagent"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/event"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/guardrail"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/limiter"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/trace"
 )
 
 //go:embed web
 var webContent embed.FS
 
+// RunIDHeader of a chat response is the run id of the turn, to query its traces and usage.
+const RunIDHeader = "X-Run-ID"
+
 type ChatRequest struct {
 	ID      string `json:"id"`
 	Message string `json:"message"`
+	Filter  string `json:"filter"`
+}
+
+// ApproveRequest decides on the approval_required event ApprovalID of the paused turn of conversation ID.
+type ApproveRequest struct {
+	ID         string `json:"id"`
+	ApprovalID string `json:"approval_id"`
+	Approved   bool   `json:"approved"`
+	Reason     string `json:"reason"`
 }
 
-func BindRoutes(r *route.RouterGroup) error {
-	if err := Init(); err != nil {
+func BindRoutes(r *route.RouterGroup, cfg *config.Config) error {
+	if err := Init(cfg); err != nil {
 		return err
 	}
 
 	// API 路由
-	r.GET("/api/chat", HandleChat)
-	r.GET("/api/log", HandleLog)
+	r.POST("/api/chat", HandleChat)
+	r.POST("/api/chat/cancel", HandleCancelChat)
+	r.POST("/api/chat/resume", HandleResumeChat)
+	r.POST("/api/approve", HandleApprove)
+	r.GET("/api/log", HandleTraceStream)
+	r.GET("/api/traces", HandleTraces)
+	r.GET("/api/traces/stream", HandleTraceStream)
 	r.GET("/api/history", HandleHistory)
+	r.GET("/api/health", HandleHealth)
 	r.DELETE("/api/history", HandleDeleteHistory)
 
 	// 静态文件服务
@@ -85,71 +111,334 @@ func BindRoutes(r *route.RouterGroup) error {
 	return nil
 }
 
+// HandleChat streams the events of a turn, see the event package for the protocol.
+// A request with the Last-Event-ID header resumes the turn of that event instead of starting one.
+// Parameters come from a ChatRequest json body only, so that messages never show up in access logs.
 func HandleChat(ctx context.Context, c *app.RequestContext) {
-	id := c.Query("id")
-	message := c.Query("message")
-	if id == "" || message == "" {
+	req := &ChatRequest{}
+	if len(c.Request.Body()) > 0 {
+		if err := json.Unmarshal(c.Request.Body(), req); err != nil {
+			c.JSON(consts.StatusBadRequest, map[string]string{
+				"status": "error",
+				"error":  "invalid request body: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	id := req.ID
+	if id == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"status": "error",
+			"error":  "missing id parameter",
+		})
+		return
+	}
+
+	if lastEventID := sse.GetLastEventID(c); lastEventID != "" {
+		run, seq, ok := runs.Resume(id, lastEventID)
+		if !ok {
+			c.JSON(consts.StatusNotFound, map[string]string{
+				"status": "error",
+				"error":  "run not found, it may have been replaced by a newer one",
+			})
+			return
+		}
+		log.Printf("[Chat] Resuming chat with ID: %s, after event: %s\n", id, lastEventID)
+		serveRun(ctx, c, run, seq)
+		return
+	}
+
+	message := req.Message
+	if message == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"status": "error",
+			"error":  "missing message parameter",
+		})
+		return
+	}
+
+	var opts []compose.Option
+	if filter := req.Filter; filter != "" {
+		opt, err := einoagent.WithRetrieverFilter(filter)
+		if err != nil {
+			c.JSON(consts.StatusBadRequest, map[string]string{
+				"status": "error",
+				"error":  err.Error(),
+			})
+			return
+		}
+		opts = append(opts, opt)
+	}
+
+	// the message is not logged, the guardrail redacts it only once the turn runs
+	log.Printf("[Chat] Starting chat with ID: %s, message of %d bytes\n", id, len(message))
+	serveTurn(ctx, c, id, func(ctx context.Context, release func(), opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+		return runTurn(ctx, id, message, release, opts...)
+	}, opts...)
+}
+
+// HandleResumeChat resumes the turn of conversation ID from its checkpoint, e.g. after the server
+// crashed or restarted during the turn, and streams its events like HandleChat. A paused turn
+// pauses again with its approvals. It fails with 404 if the conversation has no unfinished turn.
+func HandleResumeChat(ctx context.Context, c *app.RequestContext) {
+	req := &ChatRequest{}
+	if err := json.Unmarshal(c.Request.Body(), req); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"status": "error",
+			"error":  "invalid request body: " + err.Error(),
+		})
+		return
+	}
+	if req.ID == "" {
 		c.JSON(consts.StatusBadRequest, map[string]string{
 			"status": "error",
-			"error":  "missing id or message parameter",
+			"error":  "missing id parameter",
 		})
 		return
 	}
 
-	log.Printf("[Chat] Starting chat with ID: %s, Message: %s\n", id, message)
+	log.Printf("[Chat] Resuming turn of chat ID: %s from its checkpoint\n", req.ID)
+	serveTurn(ctx, c, req.ID, func(ctx context.Context, release func(), opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+		return resumeTurn(ctx, req.ID, release, opts...)
+	})
+}
 
-	sr, err := RunAgent(ctx, id, message)
+// serveTurn starts a turn of conversation id with start once the previous turn is done, and
+// serves its events.
+func serveTurn(ctx context.Context, c *app.RequestContext, id string,
+	start func(ctx context.Context, release func(), opts ...compose.Option) (*schema.StreamReader[*schema.Message], error), opts ...compose.Option) {
+	// the run is registered once the previous turn of the conversation is done, so that resuming
+	// and cancelling always reach the running turn, never a queued one
+	release, err := acquireTurn(ctx, id)
 	if err != nil {
+		log.Printf("[Chat] Error waiting for turn: %v\n", err)
+		c.JSON(errorStatus(err), map[string]string{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+	run := runs.Start(ctx, id)
+	c.Response.Header.Set(RunIDHeader, run.ID)
+	collector := event.NewCollector(run)
+	opts = append(opts, compose.WithCallbacks(collector.Handler()))
+
+	// a risky tool call pauses the turn before its answer streams, see HandleApprove
+	sr, err := start(approval.WithInterrupts(trace.WithRun(run.Context(), run.ID, id)), release, opts...)
+	if pending := approval.Pending(err); len(pending) > 0 {
+		for _, req := range pending {
+			run.Emit(event.TypeApprovalRequired, &event.ApprovalRequired{
+				ID:        req.ID,
+				Tool:      req.Tool,
+				Arguments: req.Arguments,
+				Risk:      string(req.Risk),
+			})
+		}
+		run.Emit(event.TypePaused, &event.Paused{Usage: collector.Usage()})
+	} else if err != nil {
 		log.Printf("[Chat] Error running agent: %v\n", err)
-		c.JSON(consts.StatusInternalServerError, map[string]string{
+		run.Emit(event.TypeError, &event.Error{Code: event.ErrorCode(run.Context(), err), Message: err.Error()})
+		c.JSON(errorStatus(err), map[string]string{
 			"status": "error",
 			"error":  err.Error(),
 		})
 		return
+	} else {
+		// the turn keeps running for a while if the client goes away, so that it can resume
+		go event.Forward(run, collector, sr)
 	}
 
+	serveRun(ctx, c, run, 0)
+}
+
+// errorStatus is the http status of a turn that failed to start.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, limiter.ErrQueueFull):
+		return consts.StatusTooManyRequests
+	case errors.Is(err, guardrail.ErrBlocked):
+		return consts.StatusBadRequest
+	case errors.Is(err, einoagent.ErrNoCheckPoint):
+		return consts.StatusNotFound
+	default:
+		return consts.StatusInternalServerError
+	}
+}
+
+// heartbeat is the interval of ping events, writing them is how a disconnected client is noticed.
+const heartbeat = 5 * time.Second
+
+// serveRun publishes the events of run after seq until the run finishes or the client goes away.
+func serveRun(ctx context.Context, c *app.RequestContext, run *event.Run, seq int) {
+	unsubscribe := run.Subscribe()
 	s := sse.NewStream(c)
 	defer func() {
-		sr.Close()
+		unsubscribe()
 		c.Flush()
-
-		log.Printf("[Chat] Finished chat with ID: %s\n", id)
+		log.Printf("[Chat] Finished chat with ID: %s\n", run.ConversationID)
 	}()
 
-outer:
 	for {
-		select {
-		case <-ctx.Done():
-			log.Printf("[Chat] Context done for chat ID: %s\n", id)
+		nextCtx, cancel := context.WithTimeout(ctx, heartbeat)
+		events, err := run.Next(nextCtx, seq)
+		cancel()
+		if errors.Is(err, io.EOF) {
 			return
-		default:
-			msg, err := sr.Recv()
-			if errors.Is(err, io.EOF) {
-				log.Printf("[Chat] EOF received for chat ID: %s\n", id)
-				break outer
+		}
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			if err = s.Publish(&sse.Event{Event: string(event.TypePing), Data: []byte("{}")}); err != nil {
+				log.Printf("[Chat] Client of chat ID %s is gone: %v\n", run.ConversationID, err)
+				return
 			}
+			continue
+		}
+		if err != nil {
+			log.Printf("[Chat] Context done for chat ID: %s\n", run.ConversationID)
+			return
+		}
+
+		for _, e := range events {
+			data, err := json.Marshal(e.Data)
 			if err != nil {
-				log.Printf("[Chat] Error receiving message: %v\n", err)
-				break outer
+				log.Printf("[Chat] Error marshaling event: %v\n", err)
+				return
 			}
-
 			err = s.Publish(&sse.Event{
-				Data: []byte(msg.Content),
+				ID:    e.ID,
+				Event: string(e.Type),
+				Data:  data,
 			})
 			if err != nil {
 				log.Printf("[Chat] Error publishing message: %v\n", err)
-				break outer
+				return
 			}
+			seq = e.Seq
 		}
 	}
 }
 
+// HandleCancelChat cancels the running turn of the conversation, its stream ends with
+// an error event of code cancelled and the partial answer is saved as cancelled.
+func HandleCancelChat(ctx context.Context, c *app.RequestContext) {
+	req := &ChatRequest{ID: c.Query("id")}
+	if len(c.Request.Body()) > 0 {
+		if err := json.Unmarshal(c.Request.Body(), req); err != nil {
+			c.JSON(consts.StatusBadRequest, map[string]string{
+				"status": "error",
+				"error":  "invalid request body: " + err.Error(),
+			})
+			return
+		}
+	}
+	if req.ID == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"status": "error",
+			"error":  "missing id parameter",
+		})
+		return
+	}
+
+	run, ok := runs.Running(req.ID)
+	if !ok {
+		c.JSON(consts.StatusNotFound, map[string]string{
+			"status": "error",
+			"error":  "no running chat",
+		})
+		return
+	}
+
+	log.Printf("[Chat] Cancelling chat with ID: %s\n", req.ID)
+	run.Cancel()
+	c.JSON(consts.StatusOK, map[string]string{
+		"status": "success",
+	})
+}
+
+// HandleApprove runs or rejects a tool call of a paused turn, a rejected call is not run and
+// the agent is told so. The turn resumes from its checkpoint and its events are streamed like
+// HandleChat. The other calls of the turn that wait for approval pause it again, with new
+// approval_required events, as does an approval_id that does not wait. It fails with 404 if
+// the conversation has no paused turn.
+func HandleApprove(ctx context.Context, c *app.RequestContext) {
+	req := &ApproveRequest{}
+	if err := json.Unmarshal(c.Request.Body(), req); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"status": "error",
+			"error":  "invalid request body: " + err.Error(),
+		})
+		return
+	}
+	if req.ID == "" || req.ApprovalID == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"status": "error",
+			"error":  "missing id or approval_id",
+		})
+		return
+	}
+
+	log.Printf("[Chat] Approval %s of chat ID %s: approved=%v\n", req.ApprovalID, req.ID, req.Approved)
+	decisions := map[string]*approval.Decision{
+		req.ApprovalID: {Approved: req.Approved, Reason: req.Reason},
+	}
+	serveTurn(ctx, c, req.ID, func(ctx context.Context, release func(), opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+		return resumeTurn(approval.Resume(ctx, decisions), req.ID, release, opts...)
+	})
+}
+
+func HandleHealth(ctx context.Context, c *app.RequestContext) {
+	if err := runner.Health(ctx); err != nil {
+		c.JSON(consts.StatusServiceUnavailable, map[string]string{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]any{
+		"status": "ok",
+		"turns":  turns.Stats(),
+	})
+}
+
+// HandleHealthz is the liveness probe, it only reports that the server answers.
+func HandleHealthz(ctx context.Context, c *app.RequestContext) {
+	c.JSON(consts.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+// HandleReadyz is the readiness probe, it fails with 503 until redis, the vector index
+// and the model config are all usable, the result of every check is in checks.
+func HandleReadyz(ctx context.Context, c *app.RequestContext) {
+	if runner == nil {
+		c.JSON(consts.StatusServiceUnavailable, map[string]string{
+			"status": "error",
+			"error":  "agent is not initialized",
+		})
+		return
+	}
+
+	status, code := "ok", consts.StatusOK
+	checks := make(map[string]string)
+	for name, err := range runner.Ready(ctx) {
+		checks[name] = "ok"
+		if err != nil {
+			checks[name] = err.Error()
+			status, code = "error", consts.StatusServiceUnavailable
+		}
+	}
+	c.JSON(code, map[string]any{
+		"status": status,
+		"checks": checks,
+	})
+}
+
 func HandleHistory(ctx context.Context, c *app.RequestContext) {
 	// query: id => get history, none => list all
 	id := c.Query("id")
 
 	if id == "" {
-		ids := mem.GetDefaultMemory().ListConversations()
+		ids := memory.ListConversations()
 
 		c.JSON(consts.StatusOK, map[string]interface{}{
 			"ids": ids,
@@ -157,7 +446,7 @@ func HandleHistory(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	conversation := mem.GetDefaultMemory().GetConversation(id, false)
+	conversation := memory.GetConversation(id, false)
 	if conversation == nil {
 		c.JSON(consts.StatusNotFound, map[string]string{
 			"error": "conversation not found",
@@ -167,6 +456,7 @@ func HandleHistory(ctx context.Context, c *app.RequestContext) {
 
 	c.JSON(consts.StatusOK, map[string]interface{}{
 		"conversation": conversation,
+		"usage":        conversation.Usage(),
 	})
 
 }
@@ -180,63 +470,81 @@ func HandleDeleteHistory(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	mem.GetDefaultMemory().DeleteConversation(id)
+	// stop the running turn of the conversation and forget its events
+	runs.Remove(id)
+	memory.DeleteConversation(id)
 	c.JSON(consts.StatusOK, map[string]string{
 		"status": "success",
 	})
 }
 
-func HandleLog(ctx context.Context, c *app.RequestContext) {
-	file, err := os.Open("log/eino.log")
-	if err != nil {
-		c.JSON(consts.StatusInternalServerError, map[string]string{
-			"status": "error",
-			"error":  err.Error(),
-		})
-		return
-	}
-	defer file.Close()
+// HandleTraces returns the recent trace events of a conversation (id) or a run (run_id),
+// at most limit of them, the older ones are only kept in log/trace.jsonl.
+func HandleTraces(ctx context.Context, c *app.RequestContext) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	events := traces.Query(&trace.Query{
+		ConversationID: c.Query("id"),
+		RunID:          c.Query("run_id"),
+		Limit:          limit,
+	})
+	c.JSON(consts.StatusOK, map[string]any{
+		"events": events,
+	})
+}
+
+// HandleTraceStream streams the trace events of a run (run_id) as they happen, starting with the
+// ones already recorded, or the new events of all runs without run_id. The stream ends when the
+// client goes away, which is noticed by the heartbeat.
+func HandleTraceStream(ctx context.Context, c *app.RequestContext) {
+	runID := c.Query("run_id")
+	// subscribe first, so that no event is lost between the query and the subscription
+	live, unsubscribe := traces.Subscribe(runID)
+	defer unsubscribe()
 
-	// Create a new SSE stream
 	s := sse.NewStream(c)
 	defer c.Flush()
 
-	// Seek to the end of the file
-	_, err = file.Seek(0, io.SeekEnd)
-	if err != nil {
-		log.Println("error seeking file:", err)
-		return
+	var seq int64
+	publish := func(e *trace.Event) error {
+		if e.Seq <= seq {
+			return nil
+		}
+		seq = e.Seq
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		return s.Publish(&sse.Event{
+			ID:    strconv.FormatInt(e.Seq, 10),
+			Event: string(e.Phase),
+			Data:  data,
+		})
 	}
 
-	// Use a goroutine to continuously read new lines
-	go func() {
-		reader := bufio.NewReader(file)
-		for {
-			line, err := reader.ReadString('\n')
-			if err != nil && err != io.EOF {
-				log.Println("error reading log:", err)
-				break
+	if runID != "" {
+		for _, e := range traces.Query(&trace.Query{RunID: runID}) {
+			if err := publish(e); err != nil {
+				log.Printf("[Trace] Error publishing event: %v\n", err)
+				return
 			}
+		}
+	}
 
-			// If we got a line, publish it
-			if line != "" {
-				err = s.Publish(&sse.Event{
-					Data: []byte(line),
-				})
-				if err != nil {
-					log.Println("error publishing log:", err)
-					break
-				}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Publish(&sse.Event{Event: string(event.TypePing), Data: []byte("{}")}); err != nil {
+				return
 			}
-
-			// If we hit EOF, wait a bit and try again
-			if err == io.EOF {
-				time.Sleep(100 * time.Millisecond)
-				continue
+		case e := <-live:
+			if err := publish(e); err != nil {
+				log.Printf("[Trace] Error publishing event: %v\n", err)
+				return
 			}
 		}
-	}()
-
-	// Keep the connection open
-	<-ctx.Done()
+	}
 }
@@ -1,3 +1,20 @@
+// 服务端配置了 auth_tokens 时，接口请求带上保存的 token，401 时提示输入后重试
+const nativeFetch = window.fetch.bind(window);
+window.fetch = async (url, options = {}) => {
+    const withToken = () => {
+        const token = localStorage.getItem('eino_token');
+        const headers = new Headers(options.headers || {});
+        if (token) headers.set('Authorization', 'Bearer ' + token);
+        return nativeFetch(url, { ...options, headers });
+    };
+    const response = await withToken();
+    if (response.status !== 401) return response;
+    const token = window.prompt('请输入访问 token');
+    if (!token) return response;
+    localStorage.setItem('eino_token', token);
+    return withToken();
+};
+
 document.addEventListener('DOMContentLoaded', () => {
     const messageInput = document.getElementById('message-input');
     const sendButton = document.getElementById('send-button');
@@ -26,6 +43,12 @@ document.addEventListener('DOMContentLoaded', () => {
             abortController.abort();
             abortController = null;
         }
+        // 通知服务端停止生成，已生成的部分会以已取消的状态保存
+        fetch('/agent/api/chat/cancel', {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify({id: chatId})
+        }).catch(error => console.error('Error cancelling chat:', error));
 
         // 隐藏取消按钮，显示发送按钮
         cancelButton.classList.add('hidden');
@@ -174,16 +197,24 @@ document.addEventListener('DOMContentLoaded', () => {
 
     function connectLogStream() {
         console.log('Connecting to log stream...');
-        const logSource = new EventSource('/agent/api/log');
+        const logSource = new EventSource('/agent/api/traces/stream?token=' + encodeURIComponent(localStorage.getItem('eino_token') || ''));
         
-        logSource.onmessage = (event) => {
-            const logMessage = event.data;
+        const appendTrace = (event) => {
+            const trace = JSON.parse(event.data);
             const wasAtBottom = isAutoScrollLog;
             
-            // 创建新的日志行
+            // 创建新的日志行：时间 [阶段] 组件:类型:节点 耗时 错误
             const logLine = document.createElement('div');
             logLine.className = 'log-line';
-            logLine.textContent = logMessage;
+            let text = `${new Date(trace.time).toLocaleTimeString()} [${trace.phase}] ${trace.component || ''}:${trace.type || ''}:${trace.name || ''}`;
+            if (trace.duration_ms) {
+                text += ` ${trace.duration_ms}ms`;
+            }
+            if (trace.error) {
+                text += ` ${trace.error}`;
+            }
+            logLine.textContent = text;
+            logLine.title = JSON.stringify(trace.input || trace.output || {}, null, 2);
             logMessages.appendChild(logLine);
             
             // 保持最新的1000行日志
@@ -197,6 +228,7 @@ document.addEventListener('DOMContentLoaded', () => {
                 logMessages.scrollTop = logMessages.scrollHeight;
             }
         };
+        ['start', 'end', 'error'].forEach((phase) => logSource.addEventListener(phase, appendTrace));
 
         logSource.onerror = (error) => {
             console.error('Log SSE Error:', error);
@@ -265,7 +297,16 @@ document.addEventListener('DOMContentLoaded', () => {
                 chatMessages.innerHTML = '';
                 
                 data.conversation.messages.forEach(msg => {
-                    appendMessage(msg.content, msg.role === 'user', false);
+                    let content = msg.content;
+                    const status = msg.extra && msg.extra.turn_status;
+                    if (status === 'cancelled') {
+                        content += '\n\n_(已取消)_';
+                    } else if (status === 'blocked') {
+                        content += '\n\n_(已拦截)_';
+                    } else if (status === 'failed') {
+                        content += `\n\n_(失败: ${msg.extra.turn_error})_`;
+                    }
+                    appendMessage(content, msg.role === 'user', false);
                 });
                 
                 highlightCurrentChat();
@@ -323,7 +364,13 @@ document.addEventListener('DOMContentLoaded', () => {
 
         appendMessage(message, true);
         messageInput.value = '';
-        
+        await streamTurn('/agent/api/chat', {id: chatId, message: message});
+    }
+
+    // streamTurn 发起一轮对话（或以 /agent/api/approve 恢复暂停的对话）并显示其事件
+    async function streamTurn(url, requestBody) {
+        const turnChatId = requestBody.id;
+
         // 禁用输入框和发送按钮，显示取消按钮
         messageInput.disabled = true;
         sendButton.disabled = true;
@@ -332,112 +379,217 @@ document.addEventListener('DOMContentLoaded', () => {
         cancelButton.classList.remove('hidden');
 
         try {
-            console.log('Starting chat with ID:', chatId);
-            
-            let hasReceivedMessage = false;
+            console.log('Starting chat with ID:', turnChatId);
+
             let currentMessageDiv = null;
-            let contentDiv = null;
+            let stepsDiv = null;
             let accumulatedContent = '';
-            let isFirstChunk = true;
             let lastRenderTime = 0;
+            let lastEventId = '';
+            let finished = false;
 
             // 创建新的 AbortController
             abortController = new AbortController();
 
-            // 使用 fetch 替代 EventSource，添加 signal
-            const response = await fetch(`/agent/api/chat?id=${chatId}&message=${encodeURIComponent(message)}`, {
-                signal: abortController.signal
-            });
+            // 第一个事件到达时创建消息框，工具调用等步骤显示在回答上方
+            function ensureMessageDiv() {
+                if (currentMessageDiv) return;
+                const messageDiv = document.createElement('div');
+                messageDiv.className = 'flex items-start gap-3 mb-4';
+
+                const avatar = document.createElement('div');
+                avatar.className = 'w-8 h-8 flex items-center justify-center rounded-full bg-gray-100 flex-shrink-0';
+                avatar.textContent = '🤖';
+                messageDiv.appendChild(avatar);
+
+                const wrapper = document.createElement('div');
+                wrapper.className = 'flex-1 min-w-0';
+                stepsDiv = document.createElement('div');
+                stepsDiv.className = 'text-xs text-gray-500 mb-1';
+                wrapper.appendChild(stepsDiv);
+
+                currentMessageDiv = document.createElement('div');
+                currentMessageDiv.className = 'message markdown-body rounded-lg p-4 bg-gray-50';
+                wrapper.appendChild(currentMessageDiv);
+
+                messageDiv.appendChild(wrapper);
+                chatMessages.appendChild(messageDiv);
+            }
 
-            // 检查响应状态
-            if (!response.ok) {
-                throw new Error(`HTTP error! status: ${response.status}`);
+            function addStep(text) {
+                ensureMessageDiv();
+                const step = document.createElement('div');
+                step.textContent = text;
+                stepsDiv.appendChild(step);
+                chatMessages.scrollTop = chatMessages.scrollHeight;
             }
 
-            const reader = response.body.getReader();
-            const decoder = new TextDecoder();
-            let buffer = '';  // 用于存储不完整的 SSE 消息
-
-            try {
-                while (true) {
-                    const {value, done} = await reader.read();
-                    if (done) break;
-
-                    // 解码新的数据块并添加到缓冲区
-                    buffer += decoder.decode(value, {stream: true});
-                    
-                    // 按行分割并处理每一行
-                    const lines = buffer.split(/\r\n|\r|\n/);
-                    // 保留最后一个可能不完整的行
-                    buffer = lines.pop() || '';
-
-                    for (const line of lines) {
-                        // 解析 SSE 格式的行
-                        if (line.startsWith('data:')) {
-                            // 保留 data: 后的所有内容，包括前导空格
-                            const rawData = line.slice(5);  // 直接截取 'data:' 后的内容
-                            // console.log(`Raw SSE data: |${rawData}|`);
-                            hasReceivedMessage = true;
-
-                            // 如果是第一个 chunk，创建新的消息框
-                            if (isFirstChunk) {
-                                const messageDiv = document.createElement('div');
-                                messageDiv.className = 'flex items-start gap-3 mb-4';
-
-                                // 添加头像
-                                const avatar = document.createElement('div');
-                                avatar.className = 'w-8 h-8 flex items-center justify-center rounded-full bg-gray-100 flex-shrink-0';
-                                avatar.textContent = '🤖';
-                                messageDiv.appendChild(avatar);
-
-                                // 消息内容
-                                contentDiv = document.createElement('div');
-                                contentDiv.className = 'message markdown-body rounded-lg p-4 bg-gray-50';
-                                messageDiv.appendChild(contentDiv);
-                                chatMessages.appendChild(messageDiv);
-                                
-                                currentMessageDiv = contentDiv;
-                                isFirstChunk = false;
-                                accumulatedContent = rawData;
-                            } else {
-                                if (rawData === '') {
-                                    // 如果是空数据，添加换行符
-                                    accumulatedContent += '\n';
-                                } else {
-                                    // 否则直接拼接数据
-                                    accumulatedContent += rawData;
-                                }
-                            }
+            // 有风险的工具调用暂停本轮对话，允许或拒绝其中一个后对话恢复，其余待确认的调用会重新发出
+            function addApproval(data) {
+                ensureMessageDiv();
+                const step = document.createElement('div');
+                step.className = 'approval flex items-center gap-2';
+                const text = document.createElement('span');
+                text.textContent = `⚠️ ${data.tool} (${data.risk}) ${data.arguments}`;
+                step.appendChild(text);
+
+                const decide = (approved) => {
+                    chatMessages.querySelectorAll('.approval button').forEach(button => button.remove());
+                    text.textContent += approved ? ' ✔ 已允许' : ' ✘ 已拒绝';
+                    streamTurn('/agent/api/approve', {id: turnChatId, approval_id: data.id, approved: approved});
+                };
+                [['允许', true, 'bg-blue-500 text-white'], ['拒绝', false, 'bg-gray-200']].forEach(([label, approved, style]) => {
+                    const button = document.createElement('button');
+                    button.className = `px-2 py-0.5 rounded ${style}`;
+                    button.textContent = label;
+                    button.addEventListener('click', () => decide(approved));
+                    step.appendChild(button);
+                });
 
-                            // 限制渲染频率
-                            const now = Date.now();
-                            if (now - lastRenderTime >= 100) {
-                                renderContent();
-                            } else {
-                                clearTimeout(window.renderTimeout);
-                                window.renderTimeout = setTimeout(renderContent, 100 - (now - lastRenderTime));
-                            }
+                stepsDiv.appendChild(step);
+                chatMessages.scrollTop = chatMessages.scrollHeight;
+            }
+
+            function renderContent() {
+                currentMessageDiv.innerHTML = marked.parse(accumulatedContent);
+                addCopyButtons();
+                chatMessages.scrollTop = chatMessages.scrollHeight;
+                lastRenderTime = Date.now();
+            }
+
+            function scheduleRender() {
+                // 限制渲染频率
+                const now = Date.now();
+                if (now - lastRenderTime >= 100) {
+                    renderContent();
+                } else {
+                    clearTimeout(window.renderTimeout);
+                    window.renderTimeout = setTimeout(renderContent, 100 - (now - lastRenderTime));
+                }
+            }
+
+            // 事件类型及数据格式见 pkg/event
+            function handleEvent(type, data) {
+                ensureMessageDiv();
+                switch (type) {
+                    case 'token':
+                        accumulatedContent += data.content;
+                        scheduleRender();
+                        break;
+                    case 'reasoning':
+                        addStep(`💭 ${data.content}`);
+                        break;
+                    case 'tool_call':
+                        addStep(`🔧 ${data.name} ${data.arguments}`);
+                        break;
+                    case 'tool_result':
+                        addStep(`✅ ${data.name || data.id} 返回 ${data.content.length} 字符`);
+                        break;
+                    case 'approval_required':
+                        addApproval(data);
+                        break;
+                    case 'retrieval':
+                        addStep(`📚 检索到 ${data.documents.length} 篇文档`);
+                        break;
+                    case 'error':
+                        finished = true;
+                        accumulatedContent += data.code === 'cancelled' ? '\n\n_(已取消)_' : `\n\n**Error:** ${data.message}`;
+                        renderContent();
+                        break;
+                    case 'paused':
+                        finished = true;
+                        accumulatedContent += '\n\n_(等待确认)_';
+                        renderContent();
+                        break;
+                    case 'done':
+                        finished = true;
+                        renderContent();
+                        if (data.usage && data.usage.total_tokens) {
+                            addStep(`tokens: ${data.usage.prompt_tokens} + ${data.usage.completion_tokens} = ${data.usage.total_tokens}`);
                         }
-                    }
+                        break;
+                }
+            }
+
+            // 连接中断时携带 Last-Event-ID 重连，继续接收本轮对话剩余的事件
+            for (let attempt = 0; !finished && attempt < 3; attempt++) {
+                const headers = {'Content-Type': 'application/json'};
+                let turnUrl = url;
+                let body = requestBody;
+                if (lastEventId) {
+                    headers['Last-Event-ID'] = lastEventId;
+                    turnUrl = '/agent/api/chat';
+                    body = {id: turnChatId};
+                }
+
+                let response;
+                try {
+                    // 使用 POST，避免消息内容出现在访问日志中
+                    response = await fetch(turnUrl, {
+                        method: 'POST',
+                        headers,
+                        body: JSON.stringify(body),
+                        signal: abortController.signal
+                    });
+                } catch (error) {
+                    if (error.name === 'AbortError' || !lastEventId) throw error;
+                    continue;
                 }
 
-                function renderContent() {
-                    currentMessageDiv.innerHTML = marked.parse(accumulatedContent);
-                    addCopyButtons();
-                    chatMessages.scrollTop = chatMessages.scrollHeight;
-                    lastRenderTime = Date.now();
+                // 检查响应状态
+                if (!response.ok) {
+                    throw new Error(`HTTP error! status: ${response.status}`);
                 }
 
-                // 请求完成后，隐藏取消按钮，显示发送按钮
-                cancelButton.classList.add('hidden');
-                sendButton.classList.remove('hidden');
-                abortController = null;
+                const reader = response.body.getReader();
+                const decoder = new TextDecoder();
+                let buffer = '';  // 用于存储不完整的 SSE 消息
+                let eventType = '';
+                let eventData = '';
 
-            } finally {
-                // 确保读取器被正确关闭
-                reader.cancel();
+                try {
+                    while (true) {
+                        const {value, done} = await reader.read();
+                        if (done) break;
+
+                        // 解码新的数据块并添加到缓冲区
+                        buffer += decoder.decode(value, {stream: true});
+
+                        // 按行分割并处理每一行
+                        const lines = buffer.split(/\r\n|\r|\n/);
+                        // 保留最后一个可能不完整的行
+                        buffer = lines.pop() || '';
+
+                        for (const line of lines) {
+                            // 空行表示一个事件结束
+                            if (line === '') {
+                                if (eventData !== '') {
+                                    handleEvent(eventType, JSON.parse(eventData));
+                                }
+                                eventType = '';
+                                eventData = '';
+                            } else if (line.startsWith('id:')) {
+                                lastEventId = line.slice(3).trim();
+                            } else if (line.startsWith('event:')) {
+                                eventType = line.slice(6).trim();
+                            } else if (line.startsWith('data:')) {
+                                eventData += line.slice(5);
+                            }
+                        }
+                    }
+                } catch (error) {
+                    if (error.name === 'AbortError' || !lastEventId) throw error;
+                } finally {
+                    // 确保读取器被正确关闭
+                    reader.cancel();
+                }
             }
 
+            // 请求完成后，隐藏取消按钮，显示发送按钮
+            cancelButton.classList.add('hidden');
+            sendButton.classList.remove('hidden');
+            abortController = null;
+
         } catch (error) {
             console.error('Error sending message:', error);
             if (error.name === 'AbortError') {
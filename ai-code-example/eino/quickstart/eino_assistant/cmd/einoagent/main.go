@@ -18,11 +18,15 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
+	"flag"
 	"log"
-	"os"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/env"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/metrics"
 
 	"github.com/cloudwego/eino-ext/devops"
 
@@ -31,31 +35,34 @@ import (
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
 
-func init() {
-	if os.Getenv("EINO_DEBUG") != "false" {
+var configPath = flag.String("config", "", "config file, default $EINO_CONFIG or config.yaml")
+
+func main() {
+	flag.Parse()
+
+	// 加载配置，配置有误时直接退出
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("[config] %v", err)
+	}
+	if err = cfg.Validate(); err != nil {
+		log.Fatalf("[config] invalid config:\n%v", err)
+	}
+
+	if cfg.Server.DevOps {
 		err := devops.Init(context.Background())
 		if err != nil {
 			log.Printf("[eino dev] init failed, err=%v", err)
 		}
 	}
 
-	// check some essential envs
-	env.MustHasEnvs("ARK_CHAT_MODEL", "ARK_EMBEDDING_MODEL", "ARK_API_KEY")
-}
-
-func main() {
-	// 获取端口
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
 	// 创建 Hertz 服务器
-	h := server.Default(server.WithHostPorts(":" + port))
+	h := server.Default(server.WithHostPorts(":" + strconv.Itoa(cfg.Server.Port)))
 
-	h.Use(LogMiddleware())
+	h.Use(LogMiddleware(), MetricsMiddleware(), AuthMiddleware(cfg.Server.AuthTokens))
 
 	// 注册 task 路由组
 	taskGroup := h.Group("/task")
@@ -65,9 +72,30 @@ func main() {
 
 	// 注册 agent 路由组
 	agentGroup := h.Group("/agent")
-	if err := agent.BindRoutes(agentGroup); err != nil {
+	if err := agent.BindRoutes(agentGroup, cfg); err != nil {
 		log.Fatal("failed to bind agent routes:", err)
 	}
+	// OpenAI 兼容接口
+	if err := agent.BindOpenAIRoutes(h.Group("/v1"), cfg); err != nil {
+		log.Fatal("failed to bind openai routes:", err)
+	}
+	h.OnShutdown = append(h.OnShutdown, func(ctx context.Context) {
+		if err := agent.Close(ctx); err != nil {
+			log.Printf("[eino agent] close failed, err=%v", err)
+		}
+	})
+
+	// 探针
+	h.GET("/healthz", agent.HandleHealthz)
+	h.GET("/readyz", agent.HandleReadyz)
+
+	// Prometheus 指标
+	h.GET("/metrics", func(ctx context.Context, c *app.RequestContext) {
+		c.Header("Content-Type", metrics.ContentType)
+		if err := metrics.Default.Write(c); err != nil {
+			log.Printf("[metrics] write failed, err=%v", err)
+		}
+	})
 
 	// Redirect root path to /agent
 	h.GET("/", func(ctx context.Context, c *app.RequestContext) {
@@ -94,3 +122,61 @@ func LogMiddleware() app.HandlerFunc {
 		log.Printf("[HTTP] %s %s %d %v\n", method, path, statusCode, latency)
 	}
 }
+
+// protectedPrefixes 为需要 token 的接口
+var protectedPrefixes = []string{"/agent/api/", "/task/api", "/v1/"}
+
+// AuthMiddleware 在配置了 tokens 时校验接口请求的 token，token 来自 Authorization: Bearer 请求头，
+// 或 token 查询参数（EventSource 无法设置请求头）
+func AuthMiddleware(tokens []string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		path := string(c.Request.URI().Path())
+		if len(tokens) == 0 || !isProtected(path) {
+			c.Next(ctx)
+			return
+		}
+
+		token := c.Query("token")
+		if auth := string(c.GetHeader("Authorization")); strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimPrefix(auth, "Bearer ")
+		}
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				c.Next(ctx)
+				return
+			}
+		}
+		if strings.HasPrefix(path, "/v1/") {
+			// OpenAI 客户端按其错误格式解析
+			c.AbortWithStatusJSON(consts.StatusUnauthorized, map[string]any{
+				"error": map[string]string{"message": "missing or invalid token", "type": "invalid_request_error"},
+			})
+			return
+		}
+		c.AbortWithStatusJSON(consts.StatusUnauthorized, map[string]string{
+			"status": "error",
+			"error":  "missing or invalid token",
+		})
+	}
+}
+
+func isProtected(path string) bool {
+	for _, prefix := range protectedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MetricsMiddleware 记录 HTTP 请求耗时，route 为注册的路由，未匹配的请求为空
+func MetricsMiddleware() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		start := time.Now()
+
+		c.Next(ctx)
+
+		metrics.HTTPDuration.Observe(time.Since(start).Seconds(),
+			string(c.Request.Method()), c.FullPath(), strconv.Itoa(c.Response.StatusCode()))
+	}
+}
@@ -1,3 +1,20 @@
+// 服务端配置了 auth_tokens 时，接口请求带上保存的 token，401 时提示输入后重试
+const nativeFetch = window.fetch.bind(window);
+window.fetch = async (url, options = {}) => {
+    const withToken = () => {
+        const token = localStorage.getItem('eino_token');
+        const headers = new Headers(options.headers || {});
+        if (token) headers.set('Authorization', 'Bearer ' + token);
+        return nativeFetch(url, { ...options, headers });
+    };
+    const response = await withToken();
+    if (response.status !== 401) return response;
+    const token = window.prompt('请输入访问 token');
+    if (!token) return response;
+    localStorage.setItem('eino_token', token);
+    return withToken();
+};
+
 // URL 参数处理
 function getQueryParams() {
     const params = new URLSearchParams(window.location.search);
@@ -17,7 +17,6 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
@@ -25,140 +24,292 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"math/rand"
 	"os"
-	"strconv"
+	"os/signal"
 	"strings"
+	"time"
 
-	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/env"
-
-	"github.com/cloudwego/eino-ext/callbacks/langfuse"
 	"github.com/cloudwego/eino-ext/devops"
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
+	"golang.org/x/term"
 
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/eino/einoagent"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/event"
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/mem"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/task"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/trace"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tracing"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/usage"
 )
 
-var id = flag.String("id", "", "conversation id")
+var id = flag.String("id", "", "conversation id, default a new one")
+
+var filter = flag.String("filter", "", "metadata filter of retrieval, e.g. doc_type:graph")
+
+var configPath = flag.String("config", "", "config file, default $EINO_CONFIG or config.yaml")
+
+var prompt = flag.String("p", "", "ask the question and exit, piped stdin is appended to it")
+
+var jsonOutput = flag.Bool("json", false, "print the answer, reasoning, tool calls and usage as json, without the REPL")
+
+var serverURL = flag.String("server", "", "url of a running einoagent, e.g. http://127.0.0.1:8080, default the agent runs in this process")
+
+var token = flag.String("token", "", "token of -server, default $EINO_TOKEN")
 
-var memory = mem.GetDefaultMemory()
+// agent runs the turns, in this process or on -server.
+var agent backend
+
+var memory *mem.SimpleMemory
 
 var cbHandler callbacks.Handler
 
+var runner *einoagent.Agent
+
+// conf is the config the runner was built with, see the /model command.
+var conf *config.Config
+
+var shutdownTracing = func(context.Context) error { return nil }
+
 func main() {
 	flag.Parse()
+	os.Exit(run())
+}
 
-	// 开启 Eino 的可视化调试能力
-	err := devops.Init(context.Background())
-	if err != nil {
-		log.Printf("[eino dev] init failed, err=%v", err)
-		return
-	}
-
+// run starts the REPL if stdin is a terminal, else it answers -p and the piped question once.
+// It returns the exit code, 1 if the turn failed.
+func run() int {
 	if *id == "" {
-		*id = strconv.Itoa(rand.Intn(1000000))
+		*id = newConversationID()
 	}
 
 	ctx := context.Background()
 
-	err = Init()
-	if err != nil {
-		log.Printf("[eino agent] init failed, err=%v", err)
-		return
-	}
-
-	// Start interactive dialogue
-	reader := bufio.NewReader(os.Stdin)
-	for {
-		fmt.Printf("🧑‍ : ")
-		input, err := reader.ReadString('\n')
+	if *serverURL != "" {
+		if *token == "" {
+			*token = os.Getenv("EINO_TOKEN")
+		}
+		agent = newRemote(*serverURL, *token, *filter)
+	} else {
+		l, err := newLocal(ctx)
 		if err != nil {
-			fmt.Printf("Error reading input: %v\n", err)
-			return
+			log.Printf("[eino agent] %v", err)
+			return 1
 		}
+		agent = l
+	}
+	defer agent.close(ctx)
+
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	if interactive && *prompt == "" && !*jsonOutput {
+		newREPL().loop(ctx)
+		return 0
+	}
+	return askOnce(ctx, !interactive)
+}
+
+// backend runs the turns and keeps the conversations and tasks.
+type backend interface {
+	// start runs a turn and emits its events to run until error or done, cancelling run
+	// stops the turn. approver is asked before the risky tool calls, none rejects them.
+	start(run *event.Run, question string, approver approval.Approver)
+	conversations() ([]string, error)
+	// messages returns the messages of a conversation, none if it does not exist.
+	messages(id string) ([]*schema.Message, error)
+	tasks() ([]*task.Task, error)
+	close(ctx context.Context)
+}
 
-		input = strings.TrimSpace(input)
-		if input == "" || input == "exit" || input == "quit" {
-			return
+// local runs the agent in this process, the conversations are kept in memory.dir of the config.
+type local struct {
+	opts []compose.Option
+}
+
+func newLocal(ctx context.Context) (*local, error) {
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err = cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	// 开启 Eino 的可视化调试能力
+	if cfg.Server.DevOps {
+		if err = devops.Init(ctx); err != nil {
+			return nil, fmt.Errorf("eino dev init failed, err=%w", err)
 		}
+	}
 
-		// Call RunAgent with the input
-		sr, err := RunAgent(ctx, *id, input)
+	l := &local{}
+	if *filter != "" {
+		opt, err := einoagent.WithRetrieverFilter(*filter)
 		if err != nil {
-			fmt.Printf("Error from RunAgent: %v\n", err)
-			continue
+			return nil, fmt.Errorf("invalid filter, err=%w", err)
 		}
+		l.opts = append(l.opts, opt)
+	}
 
-		// Print the response
-		fmt.Print("🤖 : ")
-		for {
-			msg, err := sr.Recv()
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				fmt.Printf("Error receiving message: %v\n", err)
-				break
+	if err = Init(cfg); err != nil {
+		return nil, fmt.Errorf("init failed, err=%w", err)
+	}
+	return l, nil
+}
+
+func (l *local) start(run *event.Run, question string, approver approval.Approver) {
+	collector := event.NewCollector(run)
+	turnCtx := trace.WithRun(run.Context(), run.ID, run.ConversationID)
+	if approver != nil {
+		turnCtx = approval.WithInterrupts(turnCtx)
+	}
+	opts := append(l.opts, compose.WithCallbacks(collector.Handler()))
+	sr, err := RunAgent(turnCtx, run.ConversationID, question, opts...)
+	// a risky tool call pauses the turn, it resumes once approver decided on the waiting calls
+	for pending := approval.Pending(err); len(pending) > 0; pending = approval.Pending(err) {
+		decisions := make(map[string]*approval.Decision, len(pending))
+		for _, req := range pending {
+			d, aerr := approver.Approve(run.Context(), req)
+			if aerr != nil {
+				d = &approval.Decision{Reason: "no decision"}
 			}
-			fmt.Print(msg.Content)
+			decisions[req.ID] = d
 		}
-		fmt.Println()
-		fmt.Println()
+		sr, err = ResumeAgent(approval.Resume(turnCtx, decisions), run.ConversationID, opts...)
 	}
+	if err != nil {
+		run.Emit(event.TypeError, &event.Error{Code: event.ErrorCode(run.Context(), err), Message: err.Error()})
+		return
+	}
+	event.Forward(run, collector, sr)
 }
 
-func Init() error {
-	// check some essential envs
-	env.MustHasEnvs("ARK_CHAT_MODEL", "ARK_EMBEDDING_MODEL", "ARK_API_KEY")
+func (l *local) conversations() ([]string, error) {
+	return memory.ListConversations(), nil
+}
 
-	os.MkdirAll("log", 0755)
-	var f *os.File
-	f, err := os.OpenFile("log/eino.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		return err
-	}
+func (l *local) messages(id string) ([]*schema.Message, error) {
+	return memory.GetConversation(id, false).GetFullMessages(), nil
+}
 
-	cbConfig := &LogCallbackConfig{
-		Detail: true,
-		Writer: f,
+func (l *local) tasks() ([]*task.Task, error) {
+	return task.GetDefaultStorage().List(&task.ListParams{})
+}
+
+func (l *local) close(ctx context.Context) {
+	runner.Close(ctx)
+	shutdownTracing(ctx)
+}
+
+// newConversationID is the time, so that the conversations of /list sort by their start.
+func newConversationID() string {
+	return time.Now().Format("20060102-150405")
+}
+
+// askOnce answers -p, followed by stdin if it is piped, for scripts. The answer is written
+// to stdout, the steps to stderr, or the whole turn as json with -json. Risky tool calls
+// are rejected, as there is no one to approve them.
+func askOnce(ctx context.Context, piped bool) int {
+	question := *prompt
+	if piped {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Printf("[eino agent] failed to read stdin, err=%v", err)
+			return 1
+		}
+		if text := strings.TrimSpace(string(input)); text != "" {
+			question = strings.TrimSpace(question + "\n\n" + text)
+		}
 	}
-	if os.Getenv("DEBUG") == "true" {
-		cbConfig.Debug = true
+	if question == "" {
+		log.Printf("[eino agent] no question, pass -p or pipe it to stdin")
+		return 1
 	}
-	// this is for invoke option of WithCallback
-	cbHandler = LogCallback(cbConfig)
 
-	// init global callback, for trace and metrics
-	if os.Getenv("LANGFUSE_PUBLIC_KEY") != "" && os.Getenv("LANGFUSE_SECRET_KEY") != "" {
-		fmt.Println("[eino agent] INFO: use langfuse as callback, watch at: https://cloud.langfuse.com")
-		cbh, _ := langfuse.NewLangfuseHandler(&langfuse.Config{
-			Host:      "https://cloud.langfuse.com",
-			PublicKey: os.Getenv("LANGFUSE_PUBLIC_KEY"),
-			SecretKey: os.Getenv("LANGFUSE_SECRET_KEY"),
-			Name:      "Eino Assistant",
-			Public:    true,
-			Release:   "release/v0.0.1",
-			UserID:    "eino_god",
-			Tags:      []string{"eino", "assistant"},
-		})
-		callbacks.InitCallbackHandlers([]callbacks.Handler{cbh})
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	handle := func(*event.Event) {}
+	if !*jsonOutput {
+		p := &printer{out: os.Stdout, steps: os.Stderr, dim: term.IsTerminal(int(os.Stderr.Fd()))}
+		handle = p.print
+	}
+	r := newResult(ask(ctx, question, nil, handle))
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(r)
 	}
+	if r.Error != nil {
+		return 1
+	}
+	return 0
+}
 
-	return nil
+// ask runs a turn of the conversation and passes its events to handle as they come, it
+// returns the finished run. Cancelling ctx stops the turn, approver is asked before the
+// risky tool calls, none rejects them.
+func ask(ctx context.Context, question string, approver approval.Approver, handle func(*event.Event)) *event.Run {
+	run := event.NewRun(ctx, *id)
+	stop := context.AfterFunc(ctx, run.Cancel)
+	defer stop()
+
+	// the tools run before the answer streams, their events are handled meanwhile
+	go agent.start(run, question, approver)
+
+	for seq := 0; ; {
+		events, err := run.Next(context.Background(), seq)
+		if err != nil {
+			// io.EOF, the run finished
+			return run
+		}
+		for _, e := range events {
+			handle(e)
+			seq = e.Seq
+		}
+	}
 }
 
-func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[*schema.Message], error) {
+func Init(cfg *config.Config) error {
+	conf = cfg
+	memory = mem.NewSimpleMemory(mem.SimpleMemoryConfig{
+		Dir:           cfg.Memory.Dir,
+		MaxWindowSize: cfg.Memory.MaxWindowSize,
+	})
+	if memory == nil {
+		return fmt.Errorf("failed to create memory dir %s", cfg.Memory.Dir)
+	}
 
-	runner, err := einoagent.BuildEinoAgent(ctx, &einoagent.BuildConfig{
-		EinoAgent: &einoagent.EinoAgentBuildConfig{},
+	traces, err := trace.NewStore(&trace.Config{
+		Dir:    cfg.Server.LogDir,
+		Detail: true,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to build agent graph: %w", err)
+		return err
+	}
+	// this is for invoke option of WithCallback
+	cbHandler = traces.Handler()
+
+	// init global callback, for trace
+	tracer, shutdown, err := tracing.Init(context.Background(), &cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	shutdownTracing = shutdown
+	if tracer != nil {
+		callbacks.InitCallbackHandlers([]callbacks.Handler{tracer})
 	}
 
+	runner, err = einoagent.NewAgent(context.Background(), &einoagent.BuildConfig{Config: cfg})
+	return err
+}
+
+// RunAgent streams the answer of one turn, cancelling ctx stops the turn.
+// The question is saved before the answer, so that it is kept even if the turn fails,
+// a cancelled or failed answer is saved with its status, see mem.AnswerMessage.
+func RunAgent(ctx context.Context, id string, msg string, opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
 	conversation := memory.GetConversation(id, true)
 
 	userMessage := &einoagent.UserMessage{
@@ -167,8 +318,34 @@ func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[
 		History: conversation.GetMessages(),
 	}
 
-	sr, err := runner.Stream(ctx, userMessage, compose.WithCallbacks(cbHandler))
+	// add user input to history, before the turn is checkpointed, so that a resumed turn has it
+	conversation.Append(schema.UserMessage(msg))
+
+	return saveTurn(ctx, conversation, func(opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+		return runner.Stream(ctx, userMessage, opts...)
+	}, opts...)
+}
+
+// ResumeAgent streams the rest of a turn paused for approval, see approval.Resume.
+func ResumeAgent(ctx context.Context, id string, opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+	conversation := memory.GetConversation(id, true)
+	return saveTurn(ctx, conversation, func(opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+		return runner.Resume(ctx, id, opts...)
+	}, opts...)
+}
+
+// saveTurn starts the turn and saves its answer to the conversation, a turn paused for
+// approval has no answer yet.
+func saveTurn(ctx context.Context, conversation *mem.Conversation,
+	start func(opts ...compose.Option) (*schema.StreamReader[*schema.Message], error), opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+	recorder := usage.NewRecorder()
+	sr, err := start(append(opts, compose.WithCallbacks(cbHandler, recorder.Handler()))...)
 	if err != nil {
+		if approval.Pending(err) == nil {
+			answer := mem.AnswerMessage(ctx, nil, err)
+			mem.SetTurnUsage(answer, recorder.Report())
+			conversation.Append(answer)
+		}
 		return nil, fmt.Errorf("failed to stream: %w", err)
 	}
 
@@ -176,78 +353,30 @@ func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[
 
 	go func() {
 		// for save to memory
-		fullMsgs := make([]*schema.Message, 0)
-
-		defer func() {
-			// close stream if you used it
-			srs[1].Close()
+		var (
+			chunks []*schema.Message
+			err    error
+		)
+		defer srs[1].Close()
 
-			// add user input to history
-			conversation.Append(schema.UserMessage(msg))
-
-			fullMsg, err := schema.ConcatMessages(fullMsgs)
-			if err != nil {
-				fmt.Println("error concatenating messages: ", err.Error())
-			}
-			// add agent response to history
-			conversation.Append(fullMsg)
-		}()
-
-	outer:
 		for {
-			select {
-			case <-ctx.Done():
-				fmt.Println("context done", ctx.Err())
-				return
-			default:
-				chunk, err := srs[1].Recv()
-				if err != nil {
-					if errors.Is(err, io.EOF) {
-						break outer
-					}
-				}
-
-				fullMsgs = append(fullMsgs, chunk)
+			var chunk *schema.Message
+			chunk, err = srs[1].Recv()
+			if errors.Is(err, io.EOF) {
+				err = nil
+				break
 			}
+			if err != nil {
+				break
+			}
+			chunks = append(chunks, chunk)
 		}
+
+		// add agent response to history, with the tokens the turn used
+		answer := mem.AnswerMessage(ctx, chunks, err)
+		mem.SetTurnUsage(answer, recorder.Report())
+		conversation.Append(answer)
 	}()
 
 	return srs[0], nil
 }
-
-type LogCallbackConfig struct {
-	Detail bool
-	Debug  bool
-	Writer io.Writer
-}
-
-func LogCallback(config *LogCallbackConfig) callbacks.Handler {
-	if config == nil {
-		config = &LogCallbackConfig{
-			Detail: true,
-			Writer: os.Stdout,
-		}
-	}
-	if config.Writer == nil {
-		config.Writer = os.Stdout
-	}
-	builder := callbacks.NewHandlerBuilder()
-	builder.OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
-		fmt.Fprintf(config.Writer, "[view]: start [%s:%s:%s]\n", info.Component, info.Type, info.Name)
-		if config.Detail {
-			var b []byte
-			if config.Debug {
-				b, _ = json.MarshalIndent(input, "", "  ")
-			} else {
-				b, _ = json.Marshal(input)
-			}
-			fmt.Fprintf(config.Writer, "%s\n", string(b))
-		}
-		return ctx
-	})
-	builder.OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
-		fmt.Fprintf(config.Writer, "[view]: end [%s:%s:%s]\n", info.Component, info.Type, info.Name)
-		return ctx
-	})
-	return builder.Build()
-}
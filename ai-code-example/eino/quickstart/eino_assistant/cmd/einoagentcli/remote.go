@@ -0,0 +1,296 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/event"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/task"
+)
+
+// remote runs the turns on a running einoagent server, see -server, so that the web UI
+// and the terminal share the conversations and tasks.
+type remote struct {
+	url    string
+	token  string
+	filter string
+	client *http.Client
+}
+
+func newRemote(serverURL, token, filter string) *remote {
+	return &remote{
+		url:    strings.TrimSuffix(serverURL, "/"),
+		token:  token,
+		filter: filter,
+		client: &http.Client{},
+	}
+}
+
+// start streams the turn from /agent/api/chat. Cancelling the run cancels the turn on the
+// server, which ends the stream with an error event of code cancelled. A turn paused for
+// approval is resumed with the decision of approver through /agent/api/approve.
+func (r *remote) start(run *event.Run, question string, approver approval.Approver) {
+	fail := func(err error) {
+		run.Emit(event.TypeError, &event.Error{Code: event.ErrorCode(run.Context(), err), Message: err.Error()})
+	}
+
+	// the request outlives the run context, so that the events of the cancellation are read
+	reqCtx, abort := context.WithCancel(context.Background())
+	defer abort()
+	stop := context.AfterFunc(run.Context(), func() {
+		if run.Finished() {
+			return
+		}
+		if err := r.post(context.Background(), "/agent/api/chat/cancel", map[string]string{"id": run.ConversationID}, nil); err != nil {
+			log.Printf("[remote] failed to cancel the turn, err=%v", err)
+			abort()
+		}
+	})
+	defer stop()
+
+	path, body := "/agent/api/chat", map[string]any{"id": run.ConversationID, "message": question, "filter": r.filter}
+	for {
+		pending, err := r.stream(reqCtx, run, path, body)
+		if err != nil {
+			fail(err)
+			return
+		}
+		if len(pending) == 0 {
+			if !run.Finished() {
+				fail(errors.New("the server closed the stream before the turn finished"))
+			}
+			return
+		}
+		// the server resumes the turn with one decision, the other calls pause it again
+		decision, err := decide(run, approver, pending[0])
+		if err != nil {
+			fail(err)
+			return
+		}
+		path, body = "/agent/api/approve", map[string]any{
+			"id":          run.ConversationID,
+			"approval_id": pending[0].ID,
+			"approved":    decision.Approved,
+			"reason":      decision.Reason,
+		}
+	}
+}
+
+// stream posts body to path and emits the events of the response to run, it returns the
+// calls waiting for approval if the turn was paused.
+func (r *remote) stream(ctx context.Context, run *event.Run, path string, body any) ([]*event.ApprovalRequired, error) {
+	b, _ := json.Marshal(body)
+	resp, err := r.do(ctx, http.MethodPost, path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if serverRunID := resp.Header.Get("X-Run-ID"); serverRunID != "" {
+		// the run id of the server, so that /trace points at its trace log
+		run.ID = serverRunID
+	}
+
+	var pending []*event.ApprovalRequired
+	err = readEvents(resp.Body, func(typ event.Type, data any) {
+		switch data := data.(type) {
+		case *event.ApprovalRequired:
+			pending = append(pending, data)
+		case *event.Paused:
+		default:
+			run.Emit(typ, data)
+		}
+	})
+	return pending, err
+}
+
+// decide asks approver about a tool call the turn waits for, the call is rejected without
+// an approver. It fails if the run was cancelled meanwhile.
+func decide(run *event.Run, approver approval.Approver, req *event.ApprovalRequired) (*approval.Decision, error) {
+	if approver == nil {
+		return &approval.Decision{Reason: "the client cannot ask the user"}, nil
+	}
+	decision, err := approver.Approve(run.Context(), &approval.Request{
+		ID:        req.ID,
+		Tool:      req.Tool,
+		Arguments: req.Arguments,
+		Risk:      approval.Risk(req.Risk),
+	})
+	if err != nil {
+		if run.Context().Err() != nil {
+			return nil, run.Context().Err()
+		}
+		decision = &approval.Decision{Reason: "no decision"}
+	}
+	return decision, nil
+}
+
+// readEvents parses the SSE stream of /agent/api/chat and passes every event but the pings
+// to handle until the stream ends.
+func readEvents(body io.Reader, handle func(typ event.Type, data any)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var (
+		typ  event.Type
+		data []byte
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch {
+		case line == "":
+			payload, err := event.Decode(typ, data)
+			if err != nil {
+				return err
+			}
+			if payload != nil {
+				handle(typ, payload)
+			}
+			typ, data = "", nil
+		case field == "event":
+			typ = event.Type(value)
+		case field == "data":
+			if data != nil {
+				data = append(data, '\n')
+			}
+			data = append(data, value...)
+		}
+	}
+	return scanner.Err()
+}
+
+func (r *remote) conversations() ([]string, error) {
+	var resp struct {
+		IDs []string `json:"ids"`
+	}
+	if err := r.get("/agent/api/history", &resp); err != nil {
+		return nil, err
+	}
+	return resp.IDs, nil
+}
+
+func (r *remote) messages(id string) ([]*schema.Message, error) {
+	var resp struct {
+		Conversation struct {
+			Messages []*schema.Message `json:"messages"`
+		} `json:"conversation"`
+	}
+	err := r.get("/agent/api/history?id="+url.QueryEscape(id), &resp)
+	var status *statusError
+	if errors.As(err, &status) && status.code == http.StatusNotFound {
+		return nil, nil
+	}
+	return resp.Conversation.Messages, err
+}
+
+func (r *remote) tasks() ([]*task.Task, error) {
+	resp := &task.TaskResponse{}
+	err := r.post(context.Background(), "/task/api", &task.TaskRequest{Action: task.ActionList, List: &task.ListParams{}}, resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.TaskList, nil
+}
+
+func (r *remote) close(context.Context) {}
+
+// statusError is a response of the server other than 200.
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string {
+	if e.code == http.StatusUnauthorized {
+		return "the server rejected the token, pass it with -token or $EINO_TOKEN"
+	}
+	return fmt.Sprintf("server responded %d: %s", e.code, e.message)
+}
+
+func (r *remote) get(path string, out any) error {
+	resp, err := r.do(context.Background(), http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// post sends in as json and decodes the response into out, if it is not nil.
+func (r *remote) post(ctx context.Context, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	resp, err := r.do(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// do sends the request with the token, a response other than 200 is a *statusError.
+func (r *remote) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, r.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var e struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &e) != nil || e.Error == "" {
+			e.Error = strings.TrimSpace(string(data))
+		}
+		return nil, &statusError{code: resp.StatusCode, message: e.Error}
+	}
+	return resp, nil
+}
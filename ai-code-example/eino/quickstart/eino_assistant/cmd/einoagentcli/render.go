@@ -0,0 +1,183 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/event"
+)
+
+// printer renders the events of a turn, the answer to out and the steps, the reasoning,
+// tool calls and retrievals, to steps, dimmed if dim is set.
+type printer struct {
+	out   io.Writer
+	steps io.Writer
+	dim   bool
+	// prefix is printed before the answer.
+	prefix string
+
+	// open is the type of the streamed event the last line belongs to, token or reasoning.
+	open event.Type
+}
+
+func (p *printer) print(e *event.Event) {
+	switch data := e.Data.(type) {
+	case *event.Token:
+		if p.open != event.TypeToken {
+			p.close()
+			fmt.Fprint(p.out, p.prefix)
+			p.open = event.TypeToken
+		}
+		fmt.Fprint(p.out, data.Content)
+	case *event.Reasoning:
+		if p.open != event.TypeReasoning {
+			p.close()
+			fmt.Fprint(p.steps, p.style("💭 "))
+			p.open = event.TypeReasoning
+		}
+		fmt.Fprint(p.steps, data.Content)
+	case *event.ToolCall:
+		p.step("🔧 %s %s", data.Name, data.Arguments)
+	case *event.ToolResult:
+		p.step("✅ %s: %s", data.Name, preview(data.Content, 120))
+	case *event.Retrieval:
+		p.step("📚 %d documents", len(data.Documents))
+	case *event.Error:
+		if data.Code == event.ErrorCodeCancelled {
+			p.step("(cancelled)")
+		} else {
+			p.step("Error: %s", data.Message)
+		}
+	case *event.Done:
+		p.close()
+		if data.Usage != nil && data.Usage.TotalTokens > 0 {
+			p.step("tokens: %d + %d = %d", data.Usage.PromptTokens, data.Usage.CompletionTokens, data.Usage.TotalTokens)
+		}
+	}
+}
+
+// step prints a line to steps.
+func (p *printer) step(format string, args ...any) {
+	p.close()
+	fmt.Fprint(p.steps, p.style(""), fmt.Sprintf(format, args...), p.reset(), "\n")
+}
+
+// close ends the line of the streamed event.
+func (p *printer) close() {
+	switch p.open {
+	case event.TypeReasoning:
+		fmt.Fprint(p.steps, p.reset(), "\n")
+	case event.TypeToken:
+		fmt.Fprintln(p.out)
+	}
+	p.open = ""
+}
+
+func (p *printer) style(text string) string {
+	if p.dim {
+		return "\x1b[2m" + text
+	}
+	return text
+}
+
+func (p *printer) reset() string {
+	if p.dim {
+		return "\x1b[0m"
+	}
+	return ""
+}
+
+// preview is the first line of text, cut to n runes.
+func preview(text string, n int) string {
+	text, _, cut := strings.Cut(strings.TrimSpace(text), "\n")
+	if r := []rune(text); len(r) > n {
+		return string(r[:n]) + "…"
+	} else if cut {
+		return text + " …"
+	}
+	return text
+}
+
+// result is the output of -json.
+type result struct {
+	ID        string       `json:"id"`
+	RunID     string       `json:"run_id"`
+	Answer    string       `json:"answer"`
+	Reasoning string       `json:"reasoning,omitempty"`
+	ToolCalls []*toolCall  `json:"tool_calls,omitempty"`
+	Usage     *event.Usage `json:"usage,omitempty"`
+	Error     *event.Error `json:"error,omitempty"`
+}
+
+type toolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result"`
+}
+
+// newResult sums up the events of a finished run.
+func newResult(run *event.Run) *result {
+	r := &result{ID: run.ConversationID, RunID: run.ID}
+	var answer, reasoning strings.Builder
+	events := runEvents(run)
+	for _, e := range events {
+		switch data := e.Data.(type) {
+		case *event.Token:
+			answer.WriteString(data.Content)
+		case *event.Reasoning:
+			reasoning.WriteString(data.Content)
+		case *event.Done:
+			r.Usage = data.Usage
+		case *event.Error:
+			r.Error = data
+		}
+	}
+	r.Answer = answer.String()
+	r.Reasoning = reasoning.String()
+	r.ToolCalls = toolCalls(events)
+	return r
+}
+
+// toolCalls pairs the tool calls with their results.
+func toolCalls(events []*event.Event) []*toolCall {
+	var calls []*toolCall
+	byID := make(map[string]*toolCall)
+	for _, e := range events {
+		switch data := e.Data.(type) {
+		case *event.ToolCall:
+			call := &toolCall{ID: data.ID, Name: data.Name, Arguments: data.Arguments}
+			calls = append(calls, call)
+			byID[data.ID] = call
+		case *event.ToolResult:
+			if call, ok := byID[data.ID]; ok {
+				call.Result = data.Content
+			}
+		}
+	}
+	return calls
+}
+
+// runEvents returns all events of a finished run.
+func runEvents(run *event.Run) []*event.Event {
+	events, _ := run.Next(context.Background(), 0)
+	return events
+}
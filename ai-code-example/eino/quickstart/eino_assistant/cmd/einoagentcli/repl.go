@@ -0,0 +1,355 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+	"golang.org/x/term"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/eino/einoagent"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/event"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/mem"
+)
+
+// console reads the lines of the REPL with editing and an in-memory history. The terminal
+// is raw only while a line is read, so that Ctrl+C cancels a running turn.
+type console struct {
+	fd int
+	t  *term.Terminal
+}
+
+func newConsole() *console {
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	t.SetBracketedPasteMode(true)
+	return &console{fd: int(os.Stdin.Fd()), t: t}
+}
+
+func (c *console) close() {
+	c.t.SetBracketedPasteMode(false)
+}
+
+// readLine returns io.EOF on Ctrl+C, or on Ctrl+D at an empty line. pasted is set for the
+// lines of a multi-line paste but the last.
+func (c *console) readLine(prompt string) (line string, pasted bool, err error) {
+	state, err := term.MakeRaw(c.fd)
+	if err != nil {
+		return "", false, err
+	}
+	defer term.Restore(c.fd, state)
+	if width, height, err := term.GetSize(c.fd); err == nil {
+		c.t.SetSize(width, height)
+	}
+
+	c.t.SetPrompt(prompt)
+	line, err = c.t.ReadLine()
+	if errors.Is(err, term.ErrPasteIndicator) {
+		return line, true, nil
+	}
+	return line, false, err
+}
+
+// readInput reads a question or a command. A line ending with \ continues on the next line,
+// """ starts and ends a block of lines, and the lines of a paste are kept together.
+func (c *console) readInput() (string, error) {
+	var lines []string
+	block := false
+	for {
+		prompt := "> "
+		if block || len(lines) > 0 {
+			prompt = ". "
+		}
+		line, pasted, err := c.readLine(prompt)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case strings.TrimSpace(line) == `"""`:
+			if block {
+				return strings.Join(lines, "\n"), nil
+			}
+			block = true
+		case block || pasted:
+			lines = append(lines, line)
+		case strings.HasSuffix(line, `\`):
+			lines = append(lines, strings.TrimSuffix(line, `\`))
+		default:
+			return strings.Join(append(lines, line), "\n"), nil
+		}
+	}
+}
+
+// askApproval asks on the terminal before a risky tool call, the turn does not read
+// the terminal while a tool runs.
+func (c *console) askApproval() approval.Approver {
+	return approval.ApproverFunc(func(ctx context.Context, req *approval.Request) (*approval.Decision, error) {
+		fmt.Printf("⚠️  %s risk call of tool %s: %s\n", req.Risk, req.Tool, req.Arguments)
+		answer, _, err := c.readLine("   run it? [y/N] ")
+		if err != nil {
+			return nil, err
+		}
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return &approval.Decision{Approved: answer == "y" || answer == "yes"}, nil
+	})
+}
+
+type repl struct {
+	console *console
+	// last is the run of the last turn, see /trace.
+	last *event.Run
+}
+
+func newREPL() *repl {
+	return &repl{console: newConsole()}
+}
+
+func (r *repl) loop(ctx context.Context) {
+	defer r.console.close()
+	if *serverURL != "" {
+		fmt.Printf("connected to %s\n", *serverURL)
+	}
+	fmt.Printf("conversation %s, /help lists the commands\n\n", *id)
+	for {
+		input, err := r.console.readInput()
+		if err != nil {
+			return
+		}
+		input = strings.TrimSpace(input)
+		switch {
+		case input == "":
+			continue
+		case input == "exit" || input == "quit":
+			return
+		case strings.HasPrefix(input, "/"):
+			if err = r.command(ctx, input); errors.Is(err, errQuit) {
+				return
+			}
+			if err != nil {
+				fmt.Printf("%v\n", err)
+			}
+			fmt.Println()
+			continue
+		}
+
+		// Ctrl+C cancels the current answer instead of quitting
+		turnCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		p := &printer{out: os.Stdout, steps: os.Stdout, dim: true, prefix: "🤖 : "}
+		r.last = ask(turnCtx, input, r.console.askApproval(), p.print)
+		stop()
+		fmt.Println()
+	}
+}
+
+var errQuit = errors.New("quit")
+
+var commands = [][2]string{
+	{"/new", "start a new conversation"},
+	{"/list", "list the conversations"},
+	{"/switch <id>", "continue another conversation"},
+	{"/history", "show the messages of the conversation"},
+	{"/export [file]", "write the conversation to file, markdown or .json, default <id>.md"},
+	{"/tasks", "list the tasks of the task manager tool"},
+	{"/model [name]", "list the chat models or answer with another one"},
+	{"/trace", "show the tool calls of the last turn"},
+	{"/exit", "quit, as Ctrl+D does"},
+}
+
+func (r *repl) command(ctx context.Context, input string) error {
+	args := strings.Fields(input)
+	switch args[0] {
+	case "/help":
+		for _, c := range commands {
+			fmt.Printf("%-16s %s\n", c[0], c[1])
+		}
+		fmt.Println(`end a line with \ or wrap lines in """ to ask a question of several lines`)
+	case "/new":
+		*id = newConversationID()
+		r.last = nil
+		fmt.Printf("conversation %s\n", *id)
+	case "/list":
+		ids, err := agent.conversations()
+		if err != nil {
+			return err
+		}
+		sort.Strings(ids)
+		for _, c := range ids {
+			mark := " "
+			if c == *id {
+				mark = "*"
+			}
+			fmt.Printf("%s %s\n", mark, c)
+		}
+	case "/switch":
+		if len(args) != 2 {
+			return errors.New("usage: /switch <id>")
+		}
+		messages, err := agent.messages(args[1])
+		if err != nil {
+			return err
+		}
+		*id = args[1]
+		r.last = nil
+		fmt.Printf("conversation %s, %d messages\n", *id, len(messages))
+	case "/history":
+		messages, err := agent.messages(*id)
+		if err != nil {
+			return err
+		}
+		for _, msg := range messages {
+			printMessage(msg)
+		}
+	case "/export":
+		path := *id + ".md"
+		if len(args) > 1 {
+			path = args[1]
+		}
+		messages, err := agent.messages(*id)
+		if err != nil {
+			return err
+		}
+		if err = export(path, messages); err != nil {
+			return err
+		}
+		fmt.Printf("exported to %s\n", path)
+	case "/tasks":
+		tasks, err := agent.tasks()
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			done := " "
+			if t.Completed {
+				done = "x"
+			}
+			fmt.Printf("[%s] %s  %s %s\n", done, t.Title, t.Deadline, t.ID)
+		}
+	case "/model":
+		if conf == nil {
+			return errors.New("the server answers with its own models, /model needs the agent of this process")
+		}
+		if len(args) == 1 {
+			listModels()
+			return nil
+		}
+		return switchModel(ctx, args[1])
+	case "/trace":
+		if r.last == nil {
+			return errors.New("no turn yet")
+		}
+		for _, call := range toolCalls(runEvents(r.last)) {
+			fmt.Printf("🔧 %s %s\n   %s\n", call.Name, call.Arguments, preview(call.Result, 200))
+		}
+		fmt.Printf("run %s, see log/trace.jsonl for all events\n", r.last.ID)
+	case "/exit", "/quit":
+		return errQuit
+	default:
+		return fmt.Errorf("unknown command %s, /help lists the commands", args[0])
+	}
+	return nil
+}
+
+func printMessage(msg *schema.Message) {
+	who := "🧑 : "
+	if msg.Role == schema.Assistant {
+		who = "🤖 : "
+	}
+	fmt.Print(who, msg.Content)
+	if status := mem.TurnStatus(msg); status != "" {
+		fmt.Printf(" (%s)", status)
+	}
+	fmt.Println()
+}
+
+// export writes the messages as json if path ends with .json, else as markdown.
+func export(path string, messages []*schema.Message) error {
+	var b strings.Builder
+	if filepath.Ext(path) == ".json" {
+		data, err := json.MarshalIndent(messages, "", "  ")
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+	} else {
+		fmt.Fprintf(&b, "# %s\n", *id)
+		for _, msg := range messages {
+			fmt.Fprintf(&b, "\n## %s\n\n%s\n", msg.Role, msg.Content)
+			if status := mem.TurnStatus(msg); status != "" {
+				fmt.Fprintf(&b, "\n_(%s)_\n", status)
+			}
+		}
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+func listModels() {
+	file := conf.LLMFile()
+	current := file.Default
+	if route, ok := file.Routes[einoagent.AgentName]; ok {
+		current = route
+	}
+	names := make([]string, 0, len(file.Models))
+	for name := range file.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		mark := " "
+		if name == current {
+			mark = "*"
+		}
+		fmt.Printf("%s %s (%s %s)\n", mark, name, file.Models[name].Provider, file.Models[name].Model)
+	}
+}
+
+// switchModel rebuilds the agent with the chat model name routed to it.
+func switchModel(ctx context.Context, name string) error {
+	if _, ok := conf.LLMFile().Models[name]; !ok {
+		return fmt.Errorf("model %s is not configured, /model lists them", name)
+	}
+	cfg := *conf
+	cfg.LLM.Routes = make(map[string]string, len(conf.LLM.Routes)+1)
+	for route, model := range conf.LLM.Routes {
+		cfg.LLM.Routes[route] = model
+	}
+	cfg.LLM.Routes[einoagent.AgentName] = name
+
+	agent, err := einoagent.NewAgent(ctx, &einoagent.BuildConfig{Config: &cfg})
+	if err != nil {
+		return err
+	}
+	old := runner
+	runner, conf = agent, &cfg
+	fmt.Printf("answering with %s\n", name)
+	return old.Close(ctx)
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	configpkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/eino/knowledgeindexing"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/embedder"
+	redispkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/redis"
+)
+
+var (
+	batchSize  = flag.Int("batch", 10, "number of documents embedded per request")
+	dropSource = flag.Bool("drop-old", false, "drop the old index and its documents after switching")
+	configPath = flag.String("config", "", "config file, default $EINO_CONFIG or config.yaml")
+)
+
+// re-embed the documents of the current index with model.embedding_model (ARK_EMBEDDING_MODEL),
+// then switch the index alias to the new index
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+
+	cfg, err := configpkg.Load(*configPath)
+	if err != nil {
+		log.Fatalf("[config] %v", err)
+	}
+	if err = cfg.ValidateIndexing(); err != nil {
+		log.Fatalf("[config] invalid config:\n%v", err)
+	}
+
+	arkEmb, err := knowledgeindexing.NewArkEmbedding(ctx, nil)
+	if err != nil {
+		panic(err)
+	}
+	emb, err := knowledgeindexing.NewBatchCachedEmbedding(ctx, arkEmb, cfg.Model.EmbeddingModel)
+	if err != nil {
+		panic(err)
+	}
+
+	dim, err := redispkg.ProbeDimension(ctx, emb)
+	if err != nil {
+		panic(err)
+	}
+
+	config := redispkg.NewConfig(cfg)
+	config.Dimension = dim
+
+	client := redispkg.NewClient(config.RedisAddr)
+	defer client.Close()
+
+	result, err := redispkg.Migrate(ctx, client, emb, config, &redispkg.MigrateOptions{
+		BatchSize:  *batchSize,
+		DropSource: *dropSource,
+		Progress: func(done int) {
+			fmt.Printf("[progress] migrated documents: %d\n", done)
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	if result.Source != nil && result.Source.Name == result.Target.Name {
+		fmt.Printf("index %s is up to date, nothing to migrate\n", result.Target.Name)
+		return
+	}
+
+	from := "<none>"
+	if result.Source != nil {
+		from = result.Source.Name
+	}
+	fmt.Printf("migrate success: %s -> %s, dim: %d, documents: %d\n", from, result.Target.Name, result.Target.Dimension, result.Migrated)
+	fmt.Printf("embedding: %s\n", embedder.DefaultMetrics)
+}
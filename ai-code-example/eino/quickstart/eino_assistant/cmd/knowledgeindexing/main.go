@@ -20,10 +20,11 @@ import (
 	"context"
 	"fmt"
 	"io/fs"
+	"log"
 	"path/filepath"
 	"strings"
 
-	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/env"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
 
 	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/markdown"
 	"github.com/cloudwego/eino/components/document"
@@ -31,11 +32,18 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/eino/knowledgeindexing"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/embedder"
 )
 
 func init() {
-	// check some essential envs
-	env.MustHasEnvs("ARK_API_KEY", "ARK_EMBEDDING_MODEL")
+	// check the config indexing needs, the embedding model and redis
+	cfg, err := config.Load("")
+	if err != nil {
+		log.Fatalf("[config] %v", err)
+	}
+	if err = cfg.ValidateIndexing(); err != nil {
+		log.Fatalf("[config] invalid config:\n%v", err)
+	}
 }
 
 func main() {
@@ -47,6 +55,7 @@ func main() {
 	}
 
 	fmt.Println("index success")
+	fmt.Printf("embedding: %s\n", embedder.DefaultMetrics)
 }
 
 func indexMarkdownFiles(ctx context.Context, dir string) error {
@@ -0,0 +1,258 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einoagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	redisCli "github.com/redis/go-redis/v9"
+
+	configpkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	redispkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/redis"
+	"github.com/jettjia/ai-code-example/eino/shared/checkpoint"
+)
+
+var ErrAgentClosed = errors.New("eino agent is closed")
+
+// Agent is the compiled EinoAgent graph together with the clients it owns.
+// It is built once and safe for concurrent use, things that vary per request
+// are passed as compose.Option, e.g. WithRetrieverFilter.
+//
+// A turn is checkpointed under the id of its UserMessage before every tool call, so that
+// Resume can finish it after a crash, the checkpoint is deleted when the turn succeeds.
+type Agent struct {
+	runner compose.Runnable[*UserMessage, *schema.Message]
+	client *redisCli.Client
+	config *configpkg.Config
+	// store is nil if checkpoint.backend is off
+	store checkpoint.Store
+
+	mu      sync.RWMutex
+	closed  bool
+	running sync.WaitGroup
+}
+
+// NewAgent builds the graph, the retriever node and the knowledge tool share the redis
+// client of the retriever config, which is closed by Close.
+func NewAgent(ctx context.Context, config *BuildConfig) (*Agent, error) {
+	if config == nil {
+		config = &BuildConfig{}
+	}
+	cfg := config.Config
+	if cfg == nil {
+		cfg = configpkg.Get()
+	}
+	agentConfig := &EinoAgentBuildConfig{}
+	if config.EinoAgent != nil {
+		*agentConfig = *config.EinoAgent
+	}
+
+	if agentConfig.RedisRetrieverKeyOfRetriever == nil {
+		rtrConfig, err := newRedisRetrieverConfig(ctx, redispkg.NewClient(cfg.Redis.Addr), cfg)
+		if err != nil {
+			return nil, err
+		}
+		agentConfig.RedisRetrieverKeyOfRetriever = rtrConfig
+	}
+	client := agentConfig.RedisRetrieverKeyOfRetriever.Client
+
+	store := config.CheckPointStore
+	if store == nil {
+		var err error
+		if store, err = checkpoint.NewStore(&cfg.Checkpoint); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	a, err := newAgent(ctx, client, agentConfig, cfg, store)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+func newAgent(ctx context.Context, client *redisCli.Client, config *EinoAgentBuildConfig, cfg *configpkg.Config, store checkpoint.Store) (*Agent, error) {
+	runner, err := BuildEinoAgent(ctx, &BuildConfig{EinoAgent: config, Config: cfg, CheckPointStore: store})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build agent graph: %w", err)
+	}
+	return &Agent{runner: runner, client: client, config: cfg, store: store}, nil
+}
+
+// Invoke runs the graph and waits for the whole answer.
+func (a *Agent) Invoke(ctx context.Context, input *UserMessage, opts ...compose.Option) (*schema.Message, error) {
+	if err := a.acquire(); err != nil {
+		return nil, err
+	}
+	defer a.running.Done()
+
+	out, err := runSteps(ctx, a.store, input.ID, false, opts, func(ctx context.Context, opts ...compose.Option) (*schema.Message, error) {
+		return a.runner.Invoke(ctx, input, opts...)
+	})
+	if err == nil {
+		a.deleteCheckPoint(ctx, input.ID)
+	}
+	return out, err
+}
+
+// Stream runs the graph, the run counts as in flight until the returned stream is
+// read to the end or closed.
+func (a *Agent) Stream(ctx context.Context, input *UserMessage, opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+	return a.stream(ctx, input, false, opts...)
+}
+
+// Resume streams the rest of the turn id from its checkpoint, e.g. of a process that crashed.
+// It fails with ErrNoCheckPoint if the turn finished or checkpoints are off.
+func (a *Agent) Resume(ctx context.Context, id string, opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+	if a.store == nil || id == "" {
+		return nil, ErrNoCheckPoint
+	}
+	_, ok, err := a.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the checkpoint: %w", err)
+	}
+	if !ok {
+		return nil, ErrNoCheckPoint
+	}
+	// the input is restored from the checkpoint
+	return a.stream(ctx, &UserMessage{ID: id}, true, opts...)
+}
+
+func (a *Agent) stream(ctx context.Context, input *UserMessage, resume bool, opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+	if err := a.acquire(); err != nil {
+		return nil, err
+	}
+
+	sr, err := runSteps(ctx, a.store, input.ID, resume, opts, func(ctx context.Context, opts ...compose.Option) (*schema.StreamReader[*schema.Message], error) {
+		return a.runner.Stream(ctx, input, opts...)
+	})
+	if err != nil {
+		a.running.Done()
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer a.running.Done()
+		defer sw.Close()
+		defer sr.Close()
+
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				// before the end of the stream, so that the next turn never sees the checkpoint
+				a.deleteCheckPoint(ctx, input.ID)
+				return
+			}
+			if closed := sw.Send(chunk, err); closed || err != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// deleteCheckPoint drops the checkpoint of a finished turn, a failed turn keeps it for Resume.
+func (a *Agent) deleteCheckPoint(ctx context.Context, id string) {
+	if a.store == nil || id == "" {
+		return
+	}
+	if err := a.store.Delete(context.WithoutCancel(ctx), id); err != nil {
+		log.Printf("[einoagent] failed to delete the checkpoint of turn %s, err=%v", id, err)
+	}
+}
+
+// Health checks that the agent is open and redis is reachable.
+func (a *Agent) Health(ctx context.Context) error {
+	a.mu.RLock()
+	closed := a.closed
+	a.mu.RUnlock()
+	if closed {
+		return ErrAgentClosed
+	}
+	if err := a.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis is unavailable: %w", err)
+	}
+	return nil
+}
+
+// Ready runs the checks a chat depends on: redis, the vector index behind the alias and the
+// model config. It returns the error of every check by name, nil for the ones that passed.
+func (a *Agent) Ready(ctx context.Context) map[string]error {
+	checks := map[string]error{
+		"redis": a.Health(ctx),
+		"model": a.config.ValidateModels(true),
+	}
+	if checks["redis"] != nil {
+		checks["index"] = errors.New("redis is unavailable")
+		return checks
+	}
+	idx, err := redispkg.ResolveAlias(ctx, a.client)
+	if err == nil && idx == nil {
+		err = fmt.Errorf("index %s not found, run knowledgeindexing first", redispkg.AliasName())
+	}
+	checks["index"] = err
+	return checks
+}
+
+// Close rejects new runs, waits for the runs in flight until ctx is done, then closes the redis client.
+func (a *Agent) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		a.running.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("runs still in flight: %w", ctx.Err())
+	}
+
+	if cerr := a.client.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (a *Agent) acquire() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return ErrAgentClosed
+	}
+	a.running.Add(1)
+	return nil
+}
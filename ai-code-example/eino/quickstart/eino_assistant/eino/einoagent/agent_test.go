@@ -0,0 +1,198 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einoagent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	configpkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	redispkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/redis"
+)
+
+// The benchmarks need a running redis (REDIS_ADDR, default localhost:6379), the models are faked,
+// so the difference between them is the cost of building the graph on every request.
+//
+// go test -run=^$ -bench=. -benchmem ./eino/einoagent
+
+func BenchmarkBuildPerRequest(b *testing.B) {
+	ctx := setupBenchmark(b)
+	input := &UserMessage{ID: "bench", Query: "what is eino graph"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a, err := NewAgent(ctx, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = a.Invoke(ctx, input)
+		// every build opens a redis client, close it like a request scoped agent would
+		if cerr := a.Close(ctx); cerr != nil && err == nil {
+			err = cerr
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPrebuilt(b *testing.B) {
+	ctx := setupBenchmark(b)
+	input := &UserMessage{ID: "bench", Query: "what is eino graph"}
+
+	a, err := NewAgent(ctx, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer a.Close(ctx)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = a.Invoke(ctx, input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func setupBenchmark(b *testing.B) context.Context {
+	b.Setenv("EINO_FAKE", "true")
+	ctx := context.Background()
+
+	client := redispkg.NewClient(os.Getenv("REDIS_ADDR"))
+	defer client.Close()
+	if err := client.Ping(ctx).Err(); err != nil {
+		b.Skipf("redis is unavailable: %v", err)
+	}
+	return ctx
+}
+
+// go test -v -run Test_AgentReady ./eino/einoagent
+func Test_AgentReady(t *testing.T) {
+	t.Setenv("EINO_FAKE", "")
+	t.Setenv("FAKE_CASSETTE_MODE", "")
+	ctx := context.Background()
+	withModel := configpkg.Default()
+	withModel.Model.APIKey, withModel.Model.ChatModel, withModel.Model.EmbeddingModel = "key", "chat", "embedding"
+
+	for _, c := range []struct {
+		name   string
+		index  bool
+		config *configpkg.Config
+		failed []string
+	}{
+		{"ready", true, withModel, nil},
+		{"no index", false, withModel, []string{"index"}},
+		{"no model", true, configpkg.Default(), []string{"model"}},
+		{"nothing", false, configpkg.Default(), []string{"index", "model"}},
+	} {
+		a := &Agent{client: redispkg.NewClient(fakeRedis(t, c.index)), config: c.config}
+		var failed []string
+		for _, name := range []string{"index", "model", "redis"} {
+			if err := a.Ready(ctx)[name]; err != nil {
+				failed = append(failed, name)
+			}
+		}
+		if fmt.Sprint(failed) != fmt.Sprint(c.failed) {
+			t.Errorf("%s: failed checks %v, want %v", c.name, failed, c.failed)
+		}
+		a.client.Close()
+	}
+}
+
+// fakeRedis answers PING, and FT.INFO with the alias of an index if index is set, every
+// other command is accepted. It returns the address to connect to.
+func fakeRedis(t *testing.T, index bool) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	info := fmt.Sprintf("*6\r\n$10\r\nindex_name\r\n$%d\r\n%s\r\n"+
+		"$16\r\nindex_definition\r\n*2\r\n$8\r\nprefixes\r\n*1\r\n$10\r\neino:doc:1\r\n"+
+		"$10\r\nattributes\r\n*1\r\n*2\r\n$3\r\nDIM\r\n$1\r\n8\r\n",
+		len(redispkg.AliasName()+":1"), redispkg.AliasName()+":1")
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					args, err := readCommand(r)
+					if err != nil {
+						return
+					}
+					reply := "+OK\r\n"
+					switch strings.ToUpper(args[0]) {
+					case "HELLO":
+						reply = "-ERR unknown command 'HELLO'\r\n"
+					case "PING":
+						reply = "+PONG\r\n"
+					case "FT.INFO":
+						reply = "-Unknown index name\r\n"
+						if index {
+							reply = info
+						}
+					}
+					if _, err = io.WriteString(conn, reply); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid command %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %q", line)
+		}
+		b := make([]byte, size+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+	return args, nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einoagent
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+
+	"github.com/jettjia/ai-code-example/eino/shared/checkpoint"
+)
+
+// ErrNoCheckPoint is the error of Resume for a turn that finished or was never saved.
+var ErrNoCheckPoint = errors.New("no checkpoint of the turn")
+
+// checkpointTools saves the turn before every tool call, see checkpoint.Save.
+func checkpointTools(tools []tool.BaseTool) []tool.BaseTool {
+	saved := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		if it, ok := t.(tool.InvokableTool); ok {
+			t = &checkpointTool{InvokableTool: it}
+		}
+		saved = append(saved, t)
+	}
+	return saved
+}
+
+type checkpointTool struct {
+	tool.InvokableTool
+}
+
+func (t *checkpointTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	if err := checkpoint.Save(ctx); err != nil {
+		return "", err
+	}
+	return t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+}
+
+// runSteps starts the turn id with call, or resumes it from its checkpoint, and resumes it
+// again after the checkpoint of every step. A turn without id or store is not saved.
+func runSteps[T any](ctx context.Context, store checkpoint.Store, id string, resume bool, opts []compose.Option,
+	call func(ctx context.Context, opts ...compose.Option) (T, error)) (T, error) {
+	if store == nil || id == "" {
+		return call(ctx, opts...)
+	}
+
+	ctx = checkpoint.WithSteps(ctx)
+	opts = append(opts[:len(opts):len(opts)], compose.WithCheckPointID(id))
+	first := opts
+	if !resume {
+		// a new turn replaces the checkpoint of an unfinished one
+		first = append(opts[:len(opts):len(opts)], compose.WithForceNewRun())
+	}
+	out, err := call(ctx, first...)
+	for {
+		info, ok := compose.ExtractInterruptInfo(err)
+		if !ok || !checkpoint.IsStep(info.InterruptContexts) {
+			return out, err
+		}
+		out, err = call(ctx, opts...)
+	}
+}
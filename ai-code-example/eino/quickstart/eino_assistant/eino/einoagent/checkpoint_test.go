@@ -0,0 +1,178 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einoagent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent/react"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	"github.com/jettjia/ai-code-example/eino/shared/checkpoint"
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+// newTestAgent is an Agent whose graph is the react agent only, so that it runs without redis.
+func newTestAgent(t *testing.T, script *fake.Script, tools []tool.BaseTool, store checkpoint.Store) *Agent {
+	ctx := context.Background()
+	graph, opts, err := NewReactAgent(ctx, &react.AgentConfig{
+		ToolCallingModel: fake.NewChatModel(script),
+		ToolsConfig:      compose.ToolsNodeConfig{Tools: checkpointTools(tools)},
+		MaxStep:          10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := compose.NewGraph[*UserMessage, *schema.Message]()
+	_ = g.AddLambdaNode("Input", compose.InvokableLambda(func(_ context.Context, input *UserMessage) ([]*schema.Message, error) {
+		return []*schema.Message{schema.UserMessage(input.Query)}, nil
+	}))
+	_ = g.AddGraphNode("ReactAgent", graph, opts...)
+	_ = g.AddEdge(compose.START, "Input")
+	_ = g.AddEdge("Input", "ReactAgent")
+	_ = g.AddEdge("ReactAgent", compose.END)
+	r, err := g.Compile(ctx, compose.WithCheckPointStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Agent{runner: r, store: store}
+}
+
+type cloneInput struct {
+	URL string `json:"url"`
+}
+
+// go test -v -run Test_AgentResume ./eino/einoagent
+func Test_AgentResume(t *testing.T) {
+	ctx := context.Background()
+	// every response is used once, so the model must not be called again for the step
+	// saved before the tool call
+	script := &fake.Script{Responses: []*fake.Response{
+		{Match: "clone eino", Message: schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "call-1", Function: schema.FunctionCall{Name: "clone", Arguments: `{"url":"github.com/cloudwego/eino"}`}},
+		})},
+		{Match: "cloned", Message: schema.AssistantMessage("eino is cloned", nil)},
+	}}
+	calls := 0
+	clone, err := utils.InferTool("clone", "clone a repo", func(_ context.Context, in *cloneInput) (string, error) {
+		calls++
+		if calls == 1 {
+			// the process dies while the tool runs
+			return "", errors.New("crashed")
+		}
+		return "cloned " + in.URL, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := checkpoint.NewFileStore(t.TempDir(), 0)
+	a := newTestAgent(t, script, []tool.BaseTool{clone}, store)
+
+	if _, err = a.Stream(ctx, &UserMessage{ID: "conv-1", Query: "clone eino"}); err == nil {
+		t.Fatal("the turn did not fail")
+	}
+	if _, ok, _ := store.Get(ctx, "conv-1"); !ok {
+		t.Fatal("the failed turn has no checkpoint")
+	}
+
+	sr, err := a.Resume(ctx, "conv-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer, err := schema.ConcatMessageStream(sr)
+	if err != nil || answer.Content != "eino is cloned" || calls != 2 {
+		t.Fatalf("answer: %v, err: %v, tool calls: %d", answer, err, calls)
+	}
+
+	// the finished turn is deleted
+	if _, err = a.Resume(ctx, "conv-1"); !errors.Is(err, ErrNoCheckPoint) {
+		t.Fatalf("resume of a finished turn: %v", err)
+	}
+}
+
+// go test -v -run Test_AgentApproval ./eino/einoagent
+func Test_AgentApproval(t *testing.T) {
+	ctx := approval.WithInterrupts(context.Background())
+	script := &fake.Script{Responses: []*fake.Response{
+		{Match: "clone eino", Message: schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "call-1", Function: schema.FunctionCall{Name: "clone", Arguments: `{"url":"github.com/cloudwego/eino"}`}},
+		})},
+		{Match: "cloned", Message: schema.AssistantMessage("eino is cloned", nil)},
+	}}
+	calls := 0
+	clone, err := utils.InferTool("clone", "clone a repo", func(_ context.Context, in *cloneInput) (string, error) {
+		calls++
+		return "cloned " + in.URL, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := approval.Gate([]tool.BaseTool{approval.Declare(clone, func(string) approval.Risk { return approval.RiskHigh })},
+		&approval.Config{Enabled: true, MinRisk: approval.RiskMedium, Timeout: time.Minute})
+	store := checkpoint.NewFileStore(t.TempDir(), 0)
+	a := newTestAgent(t, script, tools, store)
+
+	// the turn ends with the call waiting, it is saved past the step before the call
+	_, err = a.Stream(ctx, &UserMessage{ID: "conv-1", Query: "clone eino"})
+	pending := approval.Pending(err)
+	if len(pending) != 1 || pending[0].Tool != "clone" || calls != 0 {
+		t.Fatalf("pending: %v, err: %v, tool calls: %d", pending, err, calls)
+	}
+
+	sr, err := a.Resume(approval.Resume(ctx, map[string]*approval.Decision{pending[0].ID: {Approved: true}}), "conv-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer, err := schema.ConcatMessageStream(sr)
+	if err != nil || answer.Content != "eino is cloned" || calls != 1 {
+		t.Fatalf("answer: %v, err: %v, tool calls: %d", answer, err, calls)
+	}
+}
+
+// go test -v -run Test_AgentWithoutCheckPoints ./eino/einoagent
+func Test_AgentWithoutCheckPoints(t *testing.T) {
+	ctx := context.Background()
+	script := &fake.Script{Responses: []*fake.Response{
+		{Match: "clone eino", Message: schema.AssistantMessage("", []schema.ToolCall{
+			{ID: "call-1", Function: schema.FunctionCall{Name: "clone", Arguments: `{"url":"github.com/cloudwego/eino"}`}},
+		})},
+		{Match: "cloned", Message: schema.AssistantMessage("eino is cloned", nil)},
+	}}
+	clone, err := utils.InferTool("clone", "clone a repo", func(_ context.Context, in *cloneInput) (string, error) {
+		return "cloned " + in.URL, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestAgent(t, script, []tool.BaseTool{clone}, nil)
+
+	// a turn without id is not checkpointed either
+	answer, err := a.Invoke(ctx, &UserMessage{Query: "clone eino"})
+	if err != nil || answer.Content != "eino is cloned" {
+		t.Fatalf("answer: %v, err: %v", answer, err)
+	}
+	if _, err = a.Resume(ctx, "conv-1"); !errors.Is(err, ErrNoCheckPoint) {
+		t.Fatalf("resume without checkpoints: %v", err)
+	}
+}
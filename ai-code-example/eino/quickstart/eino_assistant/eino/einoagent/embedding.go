@@ -18,30 +18,46 @@ package einoagent
 
 import (
 	"context"
-	"os"
 
 	"github.com/cloudwego/eino-ext/components/embedding/ark"
 	"github.com/cloudwego/eino/components/embedding"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
 )
 
 func defaultArkEmbeddingConfig(ctx context.Context) (*ark.EmbeddingConfig, error) {
-	config := &ark.EmbeddingConfig{
-		Model:  os.Getenv("ARK_EMBEDDING_MODEL"),
-		APIKey: os.Getenv("ARK_API_KEY"),
+	return newArkEmbeddingConfig(config.Get()), nil
+}
+
+func newArkEmbeddingConfig(cfg *config.Config) *ark.EmbeddingConfig {
+	return &ark.EmbeddingConfig{
+		BaseURL: cfg.Model.BaseURL,
+		Model:   cfg.Model.EmbeddingModel,
+		APIKey:  cfg.Model.APIKey,
 	}
-	return config, nil
 }
 
 func NewArkEmbedding(ctx context.Context, config *ark.EmbeddingConfig) (eb embedding.Embedder, err error) {
+	if fake.Enabled() {
+		return fake.NewEmbedderFromEnv(), nil
+	}
+	cassette, err := fake.NewCassetteFromEnv()
+	if err != nil {
+		return nil, err
+	}
 	if config == nil {
 		config, err = defaultArkEmbeddingConfig(ctx)
 		if err != nil {
 			return nil, err
 		}
 	}
+	if cassette.Replaying() {
+		return cassette.WrapEmbedder(nil, config.Model)
+	}
 	eb, err = ark.NewEmbedder(ctx, config)
 	if err != nil {
 		return nil, err
 	}
-	return eb, nil
+	return cassette.WrapEmbedder(eb, config.Model)
 }
@@ -19,47 +19,54 @@ package einoagent
 import (
 	"context"
 
+	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent/react"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
 )
 
 func defaultReactAgentConfig(ctx context.Context) (*react.AgentConfig, error) {
-	config := &react.AgentConfig{
-		MaxStep:            25,
-		ToolReturnDirectly: map[string]struct{}{}}
-	chatModelCfg11, err := defaultArkChatModelConfig(ctx)
+	tools, err := GetTools(ctx)
 	if err != nil {
 		return nil, err
 	}
-	chatModelIns11, err := NewArkChatModel(ctx, chatModelCfg11)
+	cfg := config.Get()
+	registry, err := NewRegistry(cfg)
 	if err != nil {
 		return nil, err
 	}
-	config.Model = chatModelIns11
+	return newReactAgentConfig(ctx, tools, cfg, registry)
+}
 
-	tools, err := GetTools(ctx)
+func newReactAgentConfig(ctx context.Context, tools []tool.BaseTool, cfg *config.Config, registry *llm.Registry) (*react.AgentConfig, error) {
+	config := &react.AgentConfig{
+		MaxStep:            cfg.Agent.MaxStep,
+		ToolReturnDirectly: map[string]struct{}{}}
+	chatModelIns11, err := NewChatModel(ctx, registry)
 	if err != nil {
 		return nil, err
 	}
+	config.ToolCallingModel = chatModelIns11
 
 	config.ToolsConfig.Tools = tools
 	return config, nil
 }
 
-func NewReactAgent(ctx context.Context, config *react.AgentConfig) (lba *compose.Lambda, err error) {
+// NewReactAgent returns the graph of the react agent, it is added to the EinoAgent graph as a
+// subgraph, so that the interrupts of its tools checkpoint the whole turn.
+func NewReactAgent(ctx context.Context, config *react.AgentConfig) (graph compose.AnyGraph, opts []compose.GraphAddNodeOpt, err error) {
 	if config == nil {
 		config, err = defaultReactAgentConfig(ctx)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 	ins, err := react.NewAgent(ctx, config)
 	if err != nil {
-		return nil, err
-	}
-	lba, err = compose.AnyLambda(ins.Generate, ins.Stream, nil, nil)
-	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return lba, nil
+	graph, opts = ins.ExportGraph()
+	return graph, opts, nil
 }
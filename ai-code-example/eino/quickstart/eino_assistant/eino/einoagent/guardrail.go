@@ -0,0 +1,198 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einoagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	configpkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/guardrail"
+)
+
+func guardrailConfig(cfg *configpkg.Config) *guardrail.Config {
+	if cfg == nil {
+		cfg = configpkg.Get()
+	}
+	return &cfg.Guardrail
+}
+
+// NewInputGuard checks the question, a blocked question fails the turn with guardrail.ErrBlocked.
+// The user messages of the history were checked in their turn, they are only redacted again,
+// as the history is saved before the guard of its turn runs.
+func NewInputGuard(p *guardrail.Pipeline) *compose.Lambda {
+	return compose.InvokableLambda(func(ctx context.Context, input *UserMessage) (*UserMessage, error) {
+		query, err := p.Apply(input.Query)
+		if err != nil {
+			return nil, err
+		}
+		output := &UserMessage{ID: input.ID, Query: query, History: make([]*schema.Message, 0, len(input.History))}
+		for _, msg := range input.History {
+			if msg.Role == schema.User {
+				redacted := *msg
+				redacted.Content = p.Redact(msg.Content)
+				msg = &redacted
+			}
+			output.History = append(output.History, msg)
+		}
+		return output, nil
+	})
+}
+
+// NewOutputGuard checks the answer, the reasoning of the model is redacted. A stream is checked
+// in pieces that end at a line or a sentence, so that a phone or card number is not split between
+// two of them.
+func NewOutputGuard(p *guardrail.Pipeline) (*compose.Lambda, error) {
+	invoke := func(ctx context.Context, input *schema.Message, opts ...any) (*schema.Message, error) {
+		content, err := p.Apply(input.Content)
+		if err != nil {
+			return nil, err
+		}
+		output := *input
+		output.Content = content
+		output.ReasoningContent = p.Redact(input.ReasoningContent)
+		return &output, nil
+	}
+	transform := func(ctx context.Context, input *schema.StreamReader[*schema.Message], opts ...any) (*schema.StreamReader[*schema.Message], error) {
+		return guardStream(p, input), nil
+	}
+	return compose.AnyLambda(invoke, nil, nil, transform)
+}
+
+// maxPending is the longest text held back waiting for the end of a sentence.
+const maxPending = 1024
+
+func guardStream(p *guardrail.Pipeline, sr *schema.StreamReader[*schema.Message]) *schema.StreamReader[*schema.Message] {
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sw.Close()
+		defer sr.Close()
+
+		content, reasoning := &pendingText{}, &pendingText{}
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				sw.Send(nil, err)
+				return
+			}
+
+			// the other fields of the chunk, e.g. the usage, are sent once as they are
+			output := *chunk
+			output.ReasoningContent = p.Redact(reasoning.next(chunk.ReasoningContent))
+			if output.Content, err = p.Apply(content.next(chunk.Content)); err != nil {
+				sw.Send(nil, err)
+				return
+			}
+			if output.Content == "" && output.ReasoningContent == "" && onlyText(chunk) {
+				continue
+			}
+			if sw.Send(&output, nil) {
+				return
+			}
+		}
+
+		if content.pending == "" && reasoning.pending == "" {
+			return
+		}
+		last, err := p.Apply(content.pending)
+		if err != nil {
+			sw.Send(nil, err)
+			return
+		}
+		sw.Send(&schema.Message{Role: schema.Assistant, Content: last, ReasoningContent: p.Redact(reasoning.pending)}, nil)
+	}()
+	return out
+}
+
+// pendingText holds back the text of a stream until the end of a line or a sentence.
+type pendingText struct {
+	pending string
+}
+
+// next adds text and returns the text up to the last boundary, or all of it once it is too long.
+func (t *pendingText) next(text string) string {
+	t.pending += text
+	n := len(t.pending)
+	if n < maxPending {
+		n = lastBoundary(t.pending)
+	}
+	ready := t.pending[:n]
+	t.pending = t.pending[n:]
+	return ready
+}
+
+// onlyText reports whether msg has nothing but the content and the reasoning, which are sent
+// once they are checked.
+func onlyText(msg *schema.Message) bool {
+	return msg.ResponseMeta == nil && len(msg.Extra) == 0 && len(msg.ToolCalls) == 0
+}
+
+var boundaries = []string{"\n", "。", "！", "？", "；", ". ", "! ", "? "}
+
+// lastBoundary returns the length of text up to its last line or sentence end, 0 if it has none.
+func lastBoundary(text string) int {
+	end := 0
+	for _, b := range boundaries {
+		if i := strings.LastIndex(text, b); i >= 0 && i+len(b) > end {
+			end = i + len(b)
+		}
+	}
+	return end
+}
+
+// guardTools checks the results of the invokable tools before they re-enter the context,
+// a blocked result is replaced by a note, so that the agent can go on without it.
+func guardTools(tools []tool.BaseTool, p *guardrail.Pipeline) []tool.BaseTool {
+	if p == nil {
+		return tools
+	}
+	guarded := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		if it, ok := t.(tool.InvokableTool); ok {
+			t = &guardedTool{InvokableTool: it, pipeline: p}
+		}
+		guarded = append(guarded, t)
+	}
+	return guarded
+}
+
+type guardedTool struct {
+	tool.InvokableTool
+	pipeline *guardrail.Pipeline
+}
+
+func (t *guardedTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	result, err := t.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+	if err != nil {
+		return "", err
+	}
+	checked, err := t.pipeline.Apply(result)
+	if err != nil {
+		return fmt.Sprintf("the result of the tool was withheld, %v", err), nil
+	}
+	return checked, nil
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einoagent
+
+import (
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/guardrail"
+)
+
+// go test -v -run Test_GuardStreamReasoning ./eino/einoagent
+func Test_GuardStreamReasoning(t *testing.T) {
+	p := guardrail.NewPipeline(guardrail.StageOutput, guardrail.Rule{Check: guardrail.PII(), Action: guardrail.ActionRedact})
+	// the phone number is split between the chunks
+	chunks := []*schema.Message{
+		{Role: schema.Assistant, ReasoningContent: "the user wrote 1380013"},
+		{Role: schema.Assistant, ReasoningContent: "8000.\n"},
+		{Role: schema.Assistant, Content: "mail a.b@exam"},
+		{Role: schema.Assistant, Content: "ple.com"},
+	}
+	msg, err := schema.ConcatMessageStream(guardStream(p, schema.StreamReaderFromArray(chunks)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.ReasoningContent != "the user wrote [REDACTED:phone].\n" || msg.Content != "mail [REDACTED:email]" {
+		t.Fatalf("reasoning: %q, content: %q", msg.ReasoningContent, msg.Content)
+	}
+}
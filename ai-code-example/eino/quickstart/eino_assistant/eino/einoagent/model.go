@@ -18,30 +18,26 @@ package einoagent
 
 import (
 	"context"
-	"os"
 
-	"github.com/cloudwego/eino-ext/components/model/ark"
 	"github.com/cloudwego/eino/components/model"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
 )
 
-func defaultArkChatModelConfig(ctx context.Context) (*ark.ChatModelConfig, error) {
-	config := &ark.ChatModelConfig{
-		Model:  os.Getenv("ARK_CHAT_MODEL"),
-		APIKey: os.Getenv("ARK_API_KEY"),
+// AgentName routes the chat model of the agent, see config.LLMConfig.
+const AgentName = "einoagent"
+
+// NewRegistry builds the models of the llm and model sections of cfg, config.Get() if nil.
+// The agent builds it once and routes the model of every node from it.
+func NewRegistry(cfg *config.Config) (*llm.Registry, error) {
+	if cfg == nil {
+		cfg = config.Get()
 	}
-	return config, nil
+	return llm.NewRegistry(cfg.LLMFile())
 }
 
-func NewArkChatModel(ctx context.Context, config *ark.ChatModelConfig) (cm model.ChatModel, err error) {
-	if config == nil {
-		config, err = defaultArkChatModelConfig(ctx)
-		if err != nil {
-			return nil, err
-		}
-	}
-	cm, err = ark.NewChatModel(ctx, config)
-	if err != nil {
-		return nil, err
-	}
-	return cm, nil
+// NewChatModel creates the model routed to the agent, with its fallbacks.
+func NewChatModel(ctx context.Context, registry *llm.Registry) (model.ToolCallingChatModel, error) {
+	return registry.For(ctx, AgentName)
 }
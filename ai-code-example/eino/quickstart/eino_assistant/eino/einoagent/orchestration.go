@@ -23,6 +23,10 @@ import (
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent/react"
 	"github.com/cloudwego/eino/schema"
+
+	configpkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/guardrail"
+	"github.com/jettjia/ai-code-example/eino/shared/checkpoint"
 )
 
 type EinoAgentBuildConfig struct {
@@ -33,6 +37,11 @@ type EinoAgentBuildConfig struct {
 
 type BuildConfig struct {
 	EinoAgent *EinoAgentBuildConfig
+	// Config fills the node configs left nil and the models of the llm section, config.Get() if nil.
+	Config *configpkg.Config
+	// CheckPointStore keeps the checkpoints of the turns, NewAgent builds it from the checkpoint
+	// section of Config. Without it the turns cannot be resumed.
+	CheckPointStore checkpoint.Store
 }
 
 func BuildEinoAgent(ctx context.Context, config *BuildConfig) (r compose.Runnable[*UserMessage, *schema.Message], err error) {
@@ -42,35 +51,73 @@ func BuildEinoAgent(ctx context.Context, config *BuildConfig) (r compose.Runnabl
 		ReactAgent     = "ReactAgent"
 		RedisRetriever = "RedisRetriever"
 		InputToHistory = "InputToHistory"
+		InputGuard     = "InputGuard"
+		OutputGuard    = "OutputGuard"
 	)
+	cfg := config.Config
+	if cfg == nil {
+		cfg = configpkg.Get()
+	}
+	guardrails := guardrailConfig(cfg)
+	// one registry for every node, so that the rewrite and the agent share the breaker of a model they both use
+	registry, err := NewRegistry(cfg)
+	if err != nil {
+		return nil, err
+	}
 	g := compose.NewGraph[*UserMessage, *schema.Message]()
-	_ = g.AddLambdaNode(InputToQuery, compose.InvokableLambdaWithOption(NewInputToQuery),
-		compose.WithNodeName("UserMessageToQuery"))
-	chatTemplateKeyOfChatTemplate, err := NewChatTemplate(ctx, config.EinoAgent.ChatTemplateKeyOfChatTemplate)
+	_ = g.AddLambdaNode(InputGuard, NewInputGuard(guardrails.Pipeline(guardrail.StageInput)),
+		compose.WithNodeName("InputGuardrail"))
+	inputToQuery, err := NewQueryRewrite(ctx, cfg, registry)
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddChatTemplateNode(ChatTemplate, chatTemplateKeyOfChatTemplate)
-	reactAgentKeyOfLambda, err := NewReactAgent(ctx, config.EinoAgent.ReactAgentKeyOfLambda)
+	_ = g.AddLambdaNode(InputToQuery, inputToQuery, compose.WithNodeName("UserMessageToQuery"))
+	chatTemplateKeyOfChatTemplate, err := NewChatTemplate(ctx, config.EinoAgent.ChatTemplateKeyOfChatTemplate)
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddLambdaNode(ReactAgent, reactAgentKeyOfLambda, compose.WithNodeName("ReAct Agent"))
+	_ = g.AddChatTemplateNode(ChatTemplate, chatTemplateKeyOfChatTemplate)
 	redisRetrieverKeyOfRetriever, err := NewRedisRetriever(ctx, config.EinoAgent.RedisRetrieverKeyOfRetriever)
 	if err != nil {
 		return nil, err
 	}
 	_ = g.AddRetrieverNode(RedisRetriever, redisRetrieverKeyOfRetriever, compose.WithOutputKey("documents"))
+	reactAgentConfig := config.EinoAgent.ReactAgentKeyOfLambda
+	if reactAgentConfig == nil {
+		tools, err := getTools(ctx, redisRetrieverKeyOfRetriever, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if reactAgentConfig, err = newReactAgentConfig(ctx, tools, cfg, registry); err != nil {
+			return nil, err
+		}
+	}
+	reactAgentGraph, reactAgentOpts, err := NewReactAgent(ctx, reactAgentConfig)
+	if err != nil {
+		return nil, err
+	}
+	_ = g.AddGraphNode(ReactAgent, reactAgentGraph, append(reactAgentOpts, compose.WithNodeName("ReAct Agent"))...)
 	_ = g.AddLambdaNode(InputToHistory, compose.InvokableLambdaWithOption(NewInputToHistory),
 		compose.WithNodeName("UserMessageToVariables"))
-	_ = g.AddEdge(compose.START, InputToQuery)
-	_ = g.AddEdge(compose.START, InputToHistory)
-	_ = g.AddEdge(ReactAgent, compose.END)
+	outputGuard, err := NewOutputGuard(guardrails.Pipeline(guardrail.StageOutput))
+	if err != nil {
+		return nil, err
+	}
+	_ = g.AddLambdaNode(OutputGuard, outputGuard, compose.WithNodeName("OutputGuardrail"))
+	_ = g.AddEdge(compose.START, InputGuard)
+	_ = g.AddEdge(InputGuard, InputToQuery)
+	_ = g.AddEdge(InputGuard, InputToHistory)
+	_ = g.AddEdge(ReactAgent, OutputGuard)
+	_ = g.AddEdge(OutputGuard, compose.END)
 	_ = g.AddEdge(InputToQuery, RedisRetriever)
 	_ = g.AddEdge(RedisRetriever, ChatTemplate)
 	_ = g.AddEdge(InputToHistory, ChatTemplate)
 	_ = g.AddEdge(ChatTemplate, ReactAgent)
-	r, err = g.Compile(ctx, compose.WithGraphName("EinoAgent"), compose.WithNodeTriggerMode(compose.AllPredecessor))
+	compileOpts := []compose.GraphCompileOption{compose.WithGraphName("EinoAgent"), compose.WithNodeTriggerMode(compose.AllPredecessor)}
+	if config.CheckPointStore != nil {
+		compileOpts = append(compileOpts, compose.WithCheckPointStore(config.CheckPointStore))
+	}
+	r, err = g.Compile(ctx, compileOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +35,7 @@ var systemPrompt = `
 - knowledge of Eino framework and ecosystem
 - Project scaffolding and best practices consultation
 - Documentation navigation and implementation guidance
-- Search web, clone github repo, open file/url, task management
+- Search web, search knowledge base with metadata filter, clone github repo, open file/url, task management
 
 ## Interaction Guidelines
 - Before responding, ensure you:
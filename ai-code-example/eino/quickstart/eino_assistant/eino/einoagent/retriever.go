@@ -19,47 +19,47 @@ package einoagent
 import (
 	"context"
 	"fmt"
-	"os"
 	"strconv"
 
 	"github.com/cloudwego/eino-ext/components/retriever/redis"
 	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 	redisCli "github.com/redis/go-redis/v9"
 
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
 	redispkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/redis"
 )
 
 func defaultRedisRetrieverConfig(ctx context.Context) (*redis.RetrieverConfig, error) {
-	redisAddr := os.Getenv("REDIS_ADDR")
-	redisClient := redisCli.NewClient(&redisCli.Options{
-		Addr:     redisAddr,
-		Protocol: 2,
-	})
+	cfg := config.Get()
+	return newRedisRetrieverConfig(ctx, redispkg.NewClient(cfg.Redis.Addr), cfg)
+}
 
+func newRedisRetrieverConfig(ctx context.Context, redisClient *redisCli.Client, cfg *config.Config) (*redis.RetrieverConfig, error) {
 	config := &redis.RetrieverConfig{
-		Client:       redisClient,
-		Index:        fmt.Sprintf("%s%s", redispkg.RedisPrefix, redispkg.IndexName),
-		Dialect:      2,
-		ReturnFields: []string{redispkg.ContentField, redispkg.MetadataField, redispkg.DistanceField},
-		TopK:         8,
-		VectorField:  redispkg.VectorField,
+		Client:  redisClient,
+		Index:   redispkg.AliasName(),
+		Dialect: 2,
+		ReturnFields: []string{
+			redispkg.ContentField,
+			redispkg.MetadataField,
+			redispkg.SourceField,
+			redispkg.DocTypeField,
+			redispkg.LanguageField,
+			redispkg.UpdatedAtField,
+			redispkg.DistanceField,
+		},
+		TopK:        cfg.Agent.TopK,
+		VectorField: redispkg.VectorField,
 		DocumentConverter: func(ctx context.Context, doc redisCli.Document) (*schema.Document, error) {
 			resp := &schema.Document{
 				ID:       doc.ID,
-				Content:  "",
-				MetaData: map[string]any{},
+				Content:  doc.Fields[redispkg.ContentField],
+				MetaData: redispkg.DecodeMetadata(doc.Fields),
 			}
-			for field, val := range doc.Fields {
-				if field == redispkg.ContentField {
-					resp.Content = val
-				} else if field == redispkg.MetadataField {
-					resp.MetaData[field] = val
-				} else if field == redispkg.DistanceField {
-					distance, err := strconv.ParseFloat(val, 64)
-					if err != nil {
-						continue
-					}
+			if val, ok := doc.Fields[redispkg.DistanceField]; ok {
+				if distance, err := strconv.ParseFloat(val, 64); err == nil {
 					resp.WithScore(1 - distance)
 				}
 			}
@@ -67,18 +67,29 @@ func defaultRedisRetrieverConfig(ctx context.Context) (*redis.RetrieverConfig, e
 			return resp, nil
 		},
 	}
-	embeddingCfg11, err := defaultArkEmbeddingConfig(ctx)
-	if err != nil {
-		return nil, err
-	}
-	embeddingIns11, err := NewArkEmbedding(ctx, embeddingCfg11)
+	embeddingIns11, err := NewArkEmbedding(ctx, newArkEmbeddingConfig(cfg))
 	if err != nil {
 		return nil, err
 	}
 	config.Embedding = embeddingIns11
+
+	// make sure the alias serves vectors of the same dimension as the embedder
+	if _, err = redispkg.Init(ctx, embeddingIns11); err != nil {
+		return nil, fmt.Errorf("failed to init redis index: %w", err)
+	}
 	return config, nil
 }
 
+// WithRetrieverFilter restricts retrieval to documents matching the filter expression,
+// see redispkg.FilterSyntax.
+func WithRetrieverFilter(expr string) (compose.Option, error) {
+	opt, err := redispkg.FilterOption(expr)
+	if err != nil {
+		return compose.Option{}, err
+	}
+	return compose.WithRetrieverOption(opt), nil
+}
+
 func NewRedisRetriever(ctx context.Context, config *redis.RetrieverConfig) (rtr retriever.Retriever, err error) {
 	if config == nil {
 		config, err = defaultRedisRetrieverConfig(ctx)
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einoagent
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
+)
+
+// QueryRewriteRoute routes the model that rewrites the question into a search query,
+// usually a cheap one, e.g. llm.routes.query_rewrite: local.
+const QueryRewriteRoute = "query_rewrite"
+
+// rewriteHistory is the number of history messages the rewrite model reads.
+const rewriteHistory = 6
+
+var rewritePrompt = `Rewrite the last question of the conversation into a standalone query for searching the Eino documentation.
+Resolve pronouns and references from the conversation, keep the language of the question and its technical terms.
+Answer with the query only, without quotes or explanation.`
+
+// NewQueryRewrite is the InputToQuery node. With a query_rewrite route, a follow-up question
+// is rewritten into a standalone query by that model before retrieval, otherwise or when the
+// rewrite fails the question is searched as is. The model is routed from registry.
+func NewQueryRewrite(ctx context.Context, cfg *config.Config, registry *llm.Registry) (*compose.Lambda, error) {
+	if cfg == nil {
+		cfg = config.Get()
+	}
+	if _, ok := cfg.LLM.Routes[QueryRewriteRoute]; !ok {
+		return compose.InvokableLambdaWithOption(NewInputToQuery), nil
+	}
+
+	// temperature 0 keeps the rewrite stable, and lets the llm cache answer repeated questions
+	cm, err := registry.For(ctx, QueryRewriteRoute, llm.WithTemperature(0), llm.WithDisableThinking())
+	if err != nil {
+		return nil, err
+	}
+	return compose.InvokableLambdaWithOption(func(ctx context.Context, input *UserMessage, opts ...any) (string, error) {
+		return rewriteQuery(ctx, cm, input)
+	}), nil
+}
+
+func rewriteQuery(ctx context.Context, cm model.BaseChatModel, input *UserMessage) (string, error) {
+	var history []string
+	for _, msg := range input.History {
+		if (msg.Role == schema.User || msg.Role == schema.Assistant) && strings.TrimSpace(msg.Content) != "" {
+			history = append(history, string(msg.Role)+": "+strings.TrimSpace(msg.Content))
+		}
+	}
+	// a first question has nothing to resolve
+	if len(history) == 0 {
+		return input.Query, nil
+	}
+	history = history[max(len(history)-rewriteHistory, 0):]
+
+	msg, err := cm.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(rewritePrompt),
+		schema.UserMessage("Conversation:\n" + strings.Join(history, "\n") + "\n\nQuestion: " + input.Query),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", err
+		}
+		log.Printf("[einoagent] failed to rewrite query, searching the question as is, err=%v", err)
+		return input.Query, nil
+	}
+	query := strings.Trim(strings.TrimSpace(msg.Content), "\"'`")
+	if query == "" {
+		return input.Query, nil
+	}
+	return query, nil
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einoagent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+// go test -v -run Test_RewriteQuery ./eino/einoagent
+func Test_RewriteQuery(t *testing.T) {
+	ctx := context.Background()
+	cm := fake.NewChatModel(&fake.Script{Responses: []*fake.Response{
+		{Match: "how do I stream it", Message: schema.AssistantMessage(`"how to stream the output of an eino graph"`, nil)},
+	}})
+
+	// a first question is searched as is, without calling the model
+	query, err := rewriteQuery(ctx, cm, &UserMessage{Query: "what is eino graph"})
+	if err != nil || query != "what is eino graph" {
+		t.Fatalf("query: %q, err: %v", query, err)
+	}
+
+	input := &UserMessage{
+		Query: "how do I stream it",
+		History: []*schema.Message{
+			schema.UserMessage("what is eino graph"),
+			schema.AssistantMessage("a graph orchestrates components", nil),
+		},
+	}
+	query, err = rewriteQuery(ctx, cm, input)
+	if err != nil || query != "how to stream the output of an eino graph" {
+		t.Fatalf("query: %q, err: %v", query, err)
+	}
+
+	// the script is used up, a failed rewrite falls back to the question
+	query, err = rewriteQuery(ctx, cm, input)
+	if err != nil || query != input.Query {
+		t.Fatalf("query: %q, err: %v", query, err)
+	}
+}
@@ -19,16 +19,35 @@ package einoagent
 import (
 	"context"
 
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/guardrail"
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/einotool"
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/gitclone"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/knowledge"
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/open"
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/task"
 	"github.com/cloudwego/eino-ext/components/tool/duckduckgo"
+	"github.com/cloudwego/eino/components/retriever"
 	"github.com/cloudwego/eino/components/tool"
 )
 
 func GetTools(ctx context.Context) ([]tool.BaseTool, error) {
-	einoAssistantTool, err := NewEinoAssistantTool(ctx)
+	rtr, err := NewRedisRetriever(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return getTools(ctx, rtr, config.Get())
+}
+
+// getTools shares rtr with the knowledge tool and the docs search of eino_tool, so the tools do not open their own redis client.
+// The results of the tools are checked by the tool stage of the guardrail, the turn is checkpointed before every call.
+func getTools(ctx context.Context, rtr retriever.Retriever, cfg *config.Config) ([]tool.BaseTool, error) {
+	einoAssistantTool, err := einotool.NewEinoAssistantTool(ctx, &einotool.EinoAssistantToolConfig{
+		BaseDir:     cfg.Data.EinoDir,
+		Retriever:   rtr,
+		ExamplesDir: einotool.DefaultExamplesDir(cfg.Data.ReposDir),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +62,7 @@ func GetTools(ctx context.Context) ([]tool.BaseTool, error) {
 		return nil, err
 	}
 
-	toolGitClone, err := NewGitCloneFile(ctx)
+	toolGitClone, err := gitclone.NewGitCloneFile(ctx, &gitclone.GitCloneFileConfig{BaseDir: cfg.Data.ReposDir})
 	if err != nil {
 		return nil, err
 	}
@@ -53,13 +72,20 @@ func GetTools(ctx context.Context) ([]tool.BaseTool, error) {
 		return nil, err
 	}
 
-	return []tool.BaseTool{
+	toolKnowledge, err := NewKnowledgeTool(ctx, rtr)
+	if err != nil {
+		return nil, err
+	}
+
+	tools := []tool.BaseTool{
 		einoAssistantTool,
 		toolTask,
 		toolOpen,
 		toolGitClone,
 		toolDDGSearch,
-	}, nil
+		toolKnowledge,
+	}
+	return checkpointTools(guardTools(approval.Gate(tools, &cfg.Approval), cfg.Guardrail.Pipeline(guardrail.StageTool))), nil
 }
 
 func defaultDDGSearchConfig(ctx context.Context) (*duckduckgo.Config, error) {
@@ -93,6 +119,10 @@ func NewEinoAssistantTool(ctx context.Context) (tn tool.BaseTool, err error) {
 	return einotool.NewEinoAssistantTool(ctx, nil)
 }
 
+func NewKnowledgeTool(ctx context.Context, rtr retriever.Retriever) (tn tool.BaseTool, err error) {
+	return knowledge.NewKnowledgeTool(ctx, &knowledge.KnowledgeToolConfig{Retriever: rtr})
+}
+
 func NewTaskTool(ctx context.Context) (tn tool.BaseTool, err error) {
 	return task.NewTaskTool(ctx, nil)
 }
@@ -19,17 +19,21 @@ package knowledgeindexing
 import (
 	"context"
 	"log"
-	"os"
 
 	"github.com/cloudwego/eino-ext/components/embedding/ark"
 	"github.com/cloudwego/eino/components/embedding"
+
+	configpkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/embedder"
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
 )
 
 func defaultArkEmbeddingConfig(ctx context.Context) (*ark.EmbeddingConfig, error) {
+	cfg := configpkg.Get()
 	config := &ark.EmbeddingConfig{
-		BaseURL: "https://ark.cn-beijing.volces.com/api/v3",
-		APIKey:  os.Getenv("ARK_API_KEY"),
-		Model:   os.Getenv("ARK_EMBEDDING_MODEL"),
+		BaseURL: cfg.Model.BaseURL,
+		APIKey:  cfg.Model.APIKey,
+		Model:   cfg.Model.EmbeddingModel,
 	}
 
 	log.Printf("model: %v", config.Model)
@@ -37,15 +41,38 @@ func defaultArkEmbeddingConfig(ctx context.Context) (*ark.EmbeddingConfig, error
 }
 
 func NewArkEmbedding(ctx context.Context, config *ark.EmbeddingConfig) (eb embedding.Embedder, err error) {
+	if fake.Enabled() {
+		return fake.NewEmbedderFromEnv(), nil
+	}
+	cassette, err := fake.NewCassetteFromEnv()
+	if err != nil {
+		return nil, err
+	}
 	if config == nil {
 		config, err = defaultArkEmbeddingConfig(ctx)
 		if err != nil {
 			return nil, err
 		}
 	}
+	if cassette.Replaying() {
+		return cassette.WrapEmbedder(nil, config.Model)
+	}
 	eb, err = ark.NewEmbedder(ctx, config)
 	if err != nil {
 		return nil, err
 	}
-	return eb, nil
+	return cassette.WrapEmbedder(eb, config.Model)
+}
+
+// NewBatchCachedEmbedding wraps eb with the on-disk cache, texts missing from cache
+// are embedded in concurrent batches with retry on rate limits.
+func NewBatchCachedEmbedding(ctx context.Context, eb embedding.Embedder, model string) (embedding.Embedder, error) {
+	batched, err := embedder.NewBatchEmbedder(eb, nil)
+	if err != nil {
+		return nil, err
+	}
+	return embedder.NewCachedEmbedder(batched, &embedder.CacheConfig{
+		Dir:       configpkg.Get().Data.EmbeddingCacheDir,
+		Namespace: fake.ModelName(model),
+	})
 }
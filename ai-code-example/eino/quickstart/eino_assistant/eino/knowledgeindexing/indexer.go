@@ -20,8 +20,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/cloudwego/eino-ext/components/indexer/redis"
 	"github.com/cloudwego/eino/components/indexer"
@@ -29,44 +27,43 @@ import (
 	"github.com/google/uuid"
 	redisCli "github.com/redis/go-redis/v9"
 
+	configpkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
 	redispkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/redis"
 )
 
-func init() {
-	err := redispkg.Init()
-	if err != nil {
-		log.Fatalf("failed to init redis index: %v", err)
-	}
-}
-
 func defaultRedisIndexerConfig(ctx context.Context) (*redis.IndexerConfig, error) {
-	redisAddr := os.Getenv("REDIS_ADDR")
 	redisClient := redisCli.NewClient(&redisCli.Options{
-		Addr:     redisAddr,
+		Addr:     configpkg.Get().Redis.Addr,
 		Protocol: 2,
 	})
 
 	config := &redis.IndexerConfig{
-		Client:    redisClient,
-		KeyPrefix: redispkg.RedisPrefix,
-		BatchSize: 1,
+		Client: redisClient,
+		// the embedder splits large batches by itself, see NewBatchCachedEmbedding
+		BatchSize: 256,
 		DocumentToHashes: func(ctx context.Context, doc *schema.Document) (*redis.Hashes, error) {
 			if doc.ID == "" {
 				doc.ID = uuid.New().String()
 			}
 			key := doc.ID
+			enrichMetadata(doc)
 
 			metadataBytes, err := json.Marshal(doc.MetaData)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 			}
 
+			field2Value := map[string]redis.FieldValue{
+				redispkg.ContentField:  {Value: doc.Content, EmbedKey: redispkg.VectorField},
+				redispkg.MetadataField: {Value: metadataBytes},
+			}
+			for field, value := range redispkg.MetadataToFields(doc.MetaData) {
+				field2Value[field] = redis.FieldValue{Value: value}
+			}
+
 			return &redis.Hashes{
-				Key: key,
-				Field2Value: map[string]redis.FieldValue{
-					redispkg.ContentField:  {Value: doc.Content, EmbedKey: redispkg.VectorField},
-					redispkg.MetadataField: {Value: metadataBytes},
-				},
+				Key:         key,
+				Field2Value: field2Value,
 			}, nil
 		},
 	}
@@ -79,7 +76,16 @@ func defaultRedisIndexerConfig(ctx context.Context) (*redis.IndexerConfig, error
 	if err != nil {
 		return nil, err
 	}
-	config.Embedding = embeddingIns11
+	config.Embedding, err = NewBatchCachedEmbedding(ctx, embeddingIns11, embeddingCfg11.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := redispkg.Init(ctx, config.Embedding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init redis index: %w", err)
+	}
+	config.KeyPrefix = index.KeyPrefix
 	return config, nil
 }
 
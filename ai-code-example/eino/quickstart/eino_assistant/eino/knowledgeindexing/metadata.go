@@ -0,0 +1,101 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package knowledgeindexing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cloudwego/eino-ext/components/document/loader/file"
+	"github.com/cloudwego/eino/schema"
+
+	redispkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/redis"
+)
+
+// docTypeKeywords maps keywords of the source path to doc types, the first match wins.
+// doc types follow the doc categories of the eino website.
+var docTypeKeywords = []struct {
+	keywords []string
+	docType  string
+}{
+	{[]string{"quick_start", "quickstart"}, "quickstart"},
+	{[]string{"graph", "chain", "orchestration", "workflow"}, "graph"},
+	{[]string{"agent", "flow"}, "agent"},
+	{[]string{"component"}, "components"},
+	{[]string{"ecosystem", "integrat"}, "integrate"},
+}
+
+// enrichMetadata fills the typed metadata fields which are not set by the loader yet.
+func enrichMetadata(doc *schema.Document) {
+	if doc.MetaData == nil {
+		doc.MetaData = map[string]any{}
+	}
+	meta := doc.MetaData
+
+	source, _ := meta[file.MetaKeySource].(string)
+	if _, ok := meta[redispkg.SourceField]; !ok && source != "" {
+		meta[redispkg.SourceField] = filepath.ToSlash(filepath.Clean(source))
+	}
+
+	if _, ok := meta[redispkg.DocTypeField]; !ok {
+		meta[redispkg.DocTypeField] = detectDocType(source)
+	}
+
+	if _, ok := meta[redispkg.LanguageField]; !ok {
+		meta[redispkg.LanguageField] = detectLanguage(doc.Content)
+	}
+
+	if _, ok := meta[redispkg.UpdatedAtField]; !ok {
+		updatedAt := time.Now()
+		if info, err := os.Stat(source); err == nil {
+			updatedAt = info.ModTime()
+		}
+		meta[redispkg.UpdatedAtField] = updatedAt.Unix()
+	}
+}
+
+func detectDocType(source string) string {
+	path := strings.ToLower(filepath.ToSlash(source))
+	for _, item := range docTypeKeywords {
+		for _, keyword := range item.keywords {
+			if strings.Contains(path, keyword) {
+				return item.docType
+			}
+		}
+	}
+	return "general"
+}
+
+// detectLanguage tells zh from en by the share of han characters among letters.
+func detectLanguage(content string) string {
+	var han, letters int
+	for _, r := range content {
+		if unicode.Is(unicode.Han, r) {
+			han++
+			letters++
+		} else if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters > 0 && han*10 >= letters {
+		return "zh"
+	}
+	return "en"
+}
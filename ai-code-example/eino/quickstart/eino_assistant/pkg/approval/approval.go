@@ -0,0 +1,243 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package approval pauses the tool calls with side effects until the user approves them.
+//
+// Tools declare the risk of a call with Declare, Gate wraps the tools so that a call at or
+// above Config.MinRisk interrupts the turn instead of running. The turn is saved in its
+// checkpoint and ends, nothing waits for the user meanwhile. The caller finds the calls in
+// the error with Pending and resumes the turn with the decisions, see Resume.
+package approval
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/jettjia/ai-code-example/eino/shared/checkpoint"
+)
+
+type Risk string
+
+const (
+	// RiskLow only reads, e.g. listing tasks.
+	RiskLow Risk = "low"
+	// RiskMedium has side effects outside the agent that are easy to undo, e.g. opening a file.
+	RiskMedium Risk = "medium"
+	// RiskHigh changes or deletes data, e.g. overwriting files.
+	RiskHigh Risk = "high"
+)
+
+func (r Risk) rank() int {
+	switch r {
+	case RiskLow:
+		return 1
+	case RiskMedium:
+		return 2
+	case RiskHigh:
+		return 3
+	}
+	return 0
+}
+
+// Declarer is a tool that knows the risk of its calls.
+type Declarer interface {
+	Risk(argumentsInJSON string) Risk
+}
+
+type declaredTool struct {
+	tool.InvokableTool
+	risk func(argumentsInJSON string) Risk
+}
+
+func (d *declaredTool) Risk(argumentsInJSON string) Risk {
+	return d.risk(argumentsInJSON)
+}
+
+// Declare makes t a Declarer, risk gets the arguments of the call.
+func Declare(t tool.InvokableTool, risk func(argumentsInJSON string) Risk) tool.InvokableTool {
+	return &declaredTool{InvokableTool: t, risk: risk}
+}
+
+// RiskOf is RiskLow for the tools that declare nothing.
+func RiskOf(t tool.BaseTool, argumentsInJSON string) Risk {
+	if d, ok := t.(Declarer); ok {
+		return d.Risk(argumentsInJSON)
+	}
+	return RiskLow
+}
+
+// Request is a tool call waiting for approval, ID is the id of its interrupt.
+type Request struct {
+	ID        string `json:"id"`
+	Tool      string `json:"tool"`
+	Arguments string `json:"arguments"`
+	Risk      Risk   `json:"risk"`
+}
+
+type Decision struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Approver decides on a request, e.g. by asking on the terminal.
+type Approver interface {
+	Approve(ctx context.Context, req *Request) (*Decision, error)
+}
+
+type ApproverFunc func(ctx context.Context, req *Request) (*Decision, error)
+
+func (f ApproverFunc) Approve(ctx context.Context, req *Request) (*Decision, error) {
+	return f(ctx, req)
+}
+
+type interruptsKey struct{}
+
+// WithInterrupts marks a turn whose caller handles the approvals with Pending and Resume.
+func WithInterrupts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, interruptsKey{}, true)
+}
+
+// Pending returns the calls that wait for approval in the error of an interrupted turn, none
+// if err is not such an interrupt.
+func Pending(err error) []*Request {
+	info, ok := compose.ExtractInterruptInfo(err)
+	if !ok {
+		return nil
+	}
+	var pending []*Request
+	for _, ic := range info.InterruptContexts {
+		req, ok := ic.Info.(*Request)
+		if !ic.IsRootCause || !ok {
+			continue
+		}
+		r := *req
+		r.ID = ic.ID
+		pending = append(pending, &r)
+	}
+	return pending
+}
+
+// Resume returns ctx for resuming an interrupted turn with the decisions by request id. The
+// calls without a decision interrupt the turn again, with new ids.
+func Resume(ctx context.Context, decisions map[string]*Decision) context.Context {
+	data := make(map[string]any, len(decisions))
+	for id, d := range decisions {
+		data[id] = d
+	}
+	return compose.BatchResumeWithData(ctx, data)
+}
+
+// Config is the approval section of the config.
+type Config struct {
+	Enabled bool `yaml:"enabled" env:"APPROVAL_ENABLED"`
+	// MinRisk is the lowest risk that needs approval.
+	MinRisk Risk `yaml:"min_risk" env:"APPROVAL_MIN_RISK"`
+	// Timeout is how long a call waits for the decision, a later decision rejects it.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func DefaultConfig() Config {
+	return Config{Enabled: true, MinRisk: RiskMedium, Timeout: 10 * time.Minute}
+}
+
+func (c *Config) Validate() error {
+	if c.MinRisk.rank() == 0 {
+		return fmt.Errorf("approval.min_risk (APPROVAL_MIN_RISK): invalid risk %q, can be low, medium or high", c.MinRisk)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("approval.timeout must be positive, got %v", c.Timeout)
+	}
+	return nil
+}
+
+func init() {
+	schema.RegisterName[*asked]("approval_asked")
+}
+
+// asked is the interrupt state of a call waiting for approval, kept in the checkpoint.
+type asked struct {
+	Request *Request
+	At      time.Time
+}
+
+// Gate wraps the invokable tools, so that their risky calls wait for approval. A call of
+// a turn that cannot be interrupted is rejected, i.e. without WithInterrupts or without
+// checkpoints, e.g. from the OpenAI compatible api. A rejected call returns a note instead
+// of its result, so that the agent can go on.
+func Gate(tools []tool.BaseTool, config *Config) []tool.BaseTool {
+	if !config.Enabled {
+		return tools
+	}
+	gated := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		if it, ok := t.(tool.InvokableTool); ok {
+			if _, ok = t.(Declarer); ok {
+				t = &gatedTool{InvokableTool: it, config: config}
+			}
+		}
+		gated = append(gated, t)
+	}
+	return gated
+}
+
+type gatedTool struct {
+	tool.InvokableTool
+	config *Config
+}
+
+func (g *gatedTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	risk := RiskOf(g.InvokableTool, argumentsInJSON)
+	if risk.rank() < g.config.MinRisk.rank() {
+		return g.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+	}
+
+	info, err := g.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	_, wasAsked, state := compose.GetInterruptState[*asked](ctx)
+	if !wasAsked {
+		if interrupts, _ := ctx.Value(interruptsKey{}).(bool); !interrupts || !checkpoint.Resumable(ctx) {
+			return fmt.Sprintf("the call of tool %s needs the approval of the user, which is not possible here, it was not run", info.Name), nil
+		}
+		req := &Request{Tool: info.Name, Arguments: argumentsInJSON, Risk: risk}
+		return "", compose.StatefulInterrupt(ctx, req, &asked{Request: req, At: time.Now()})
+	}
+
+	isTarget, hasDecision, decision := compose.GetResumeContext[*Decision](ctx)
+	switch {
+	case time.Since(state.At) > g.config.Timeout:
+		log.Printf("[approval] call of tool %s not approved within %v", info.Name, g.config.Timeout)
+		decision = &Decision{Reason: "no decision in time"}
+	case !isTarget || !hasDecision || decision == nil:
+		// another call of the turn was decided on, this one waits on
+		return "", compose.StatefulInterrupt(ctx, state.Request, state)
+	}
+	if !decision.Approved {
+		note := fmt.Sprintf("the user rejected the call of tool %s, it was not run", info.Name)
+		if decision.Reason != "" {
+			note += ", reason: " + decision.Reason
+		}
+		return note, nil
+	}
+	return g.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+}
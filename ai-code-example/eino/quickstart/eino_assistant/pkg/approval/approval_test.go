@@ -0,0 +1,154 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package approval
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/jettjia/ai-code-example/eino/shared/checkpoint"
+)
+
+type countTool struct {
+	runs int
+}
+
+func (c *countTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "count"}, nil
+}
+
+func (c *countTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	c.runs++
+	return "ok", nil
+}
+
+func newCountTool(config *Config) (*countTool, tool.InvokableTool) {
+	inner := &countTool{}
+	declared := Declare(inner, func(args string) Risk {
+		if strings.Contains(args, "delete") {
+			return RiskHigh
+		}
+		return RiskLow
+	})
+	return inner, Gate([]tool.BaseTool{declared}, config)[0].(tool.InvokableTool)
+}
+
+// go test -v -run Test_Gate ./pkg/approval
+func Test_Gate(t *testing.T) {
+	inner, gated := newCountTool(&Config{Enabled: true, MinRisk: RiskMedium, Timeout: time.Minute})
+
+	// below the threshold
+	if out, _ := gated.InvokableRun(context.Background(), "list"); out != "ok" || inner.runs != 1 {
+		t.Fatalf("low risk call: out=%q runs=%d", out, inner.runs)
+	}
+	// a turn that cannot be interrupted
+	ctx := checkpoint.WithSteps(context.Background())
+	if out, _ := gated.InvokableRun(ctx, "delete"); !strings.Contains(out, "not run") || inner.runs != 1 {
+		t.Fatalf("call without interrupts: out=%q runs=%d", out, inner.runs)
+	}
+}
+
+// go test -v -run Test_GateResume ./pkg/approval
+func Test_GateResume(t *testing.T) {
+	ctx := WithInterrupts(checkpoint.WithSteps(context.Background()))
+	inner, gated := newCountTool(&Config{Enabled: true, MinRisk: RiskMedium, Timeout: time.Minute})
+	run := newToolsRunner(t, gated)
+	input := schema.AssistantMessage("", []schema.ToolCall{
+		{ID: "call-1", Function: schema.FunctionCall{Name: "count", Arguments: "delete a"}},
+		{ID: "call-2", Function: schema.FunctionCall{Name: "count", Arguments: "delete b"}},
+	})
+
+	_, err := run(ctx, input)
+	pending := Pending(err)
+	if len(pending) != 2 || inner.runs != 0 {
+		t.Fatalf("first run: pending=%v runs=%d err=%v, want 2 calls waiting", pending, inner.runs, err)
+	}
+	if pending[0].Tool != "count" || pending[0].Risk != RiskHigh || pending[0].ID == "" {
+		t.Fatalf("unexpected request %+v", pending[0])
+	}
+
+	// the call without a decision waits on
+	approved := pending[0]
+	_, err = run(Resume(ctx, map[string]*Decision{approved.ID: {Approved: true}}), input)
+	pending = Pending(err)
+	if len(pending) != 1 || pending[0].Arguments == approved.Arguments || inner.runs != 1 {
+		t.Fatalf("second run: pending=%v runs=%d err=%v, want the other call waiting", pending, inner.runs, err)
+	}
+
+	out, err := run(Resume(ctx, map[string]*Decision{pending[0].ID: {Reason: "no"}}), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || inner.runs != 1 {
+		t.Fatalf("last run: out=%v runs=%d", out, inner.runs)
+	}
+	for _, msg := range out {
+		if msg.Content != "ok" && !strings.Contains(msg.Content, "reason: no") {
+			t.Fatalf("unexpected result %q", msg.Content)
+		}
+	}
+}
+
+// go test -v -run Test_GateTimeout ./pkg/approval
+func Test_GateTimeout(t *testing.T) {
+	ctx := WithInterrupts(checkpoint.WithSteps(context.Background()))
+	inner, gated := newCountTool(&Config{Enabled: true, MinRisk: RiskMedium, Timeout: time.Millisecond})
+	run := newToolsRunner(t, gated)
+	input := schema.AssistantMessage("", []schema.ToolCall{
+		{ID: "call-1", Function: schema.FunctionCall{Name: "count", Arguments: "delete"}},
+	})
+
+	_, err := run(ctx, input)
+	pending := Pending(err)
+	if len(pending) != 1 {
+		t.Fatalf("first run: err=%v, want a call waiting", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	out, err := run(Resume(ctx, map[string]*Decision{pending[0].ID: {Approved: true}}), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inner.runs != 0 || !strings.Contains(out[0].Content, "no decision in time") {
+		t.Fatalf("late decision: out=%q runs=%d", out[0].Content, inner.runs)
+	}
+}
+
+// newToolsRunner runs the tool calls of a message with a checkpoint in a temp dir.
+func newToolsRunner(t *testing.T, tools ...tool.BaseTool) func(ctx context.Context, input *schema.Message) ([]*schema.Message, error) {
+	ctx := context.Background()
+	node, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: tools})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := compose.NewGraph[*schema.Message, []*schema.Message]()
+	_ = g.AddToolsNode("tools", node)
+	_ = g.AddEdge(compose.START, "tools")
+	_ = g.AddEdge("tools", compose.END)
+	r, err := g.Compile(ctx, compose.WithCheckPointStore(checkpoint.NewFileStore(t.TempDir(), 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(ctx context.Context, input *schema.Message) ([]*schema.Message, error) {
+		return r.Invoke(ctx, input, compose.WithCheckPointID("turn-1"))
+	}
+}
@@ -0,0 +1,407 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config is the typed configuration of the assistant. It is read from a yaml
+// file, then the env vars named by the env tags override it, so that the .env of
+// earlier versions keeps working.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/guardrail"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tracing"
+	"github.com/jettjia/ai-code-example/eino/shared/checkpoint"
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+	"github.com/jettjia/ai-code-example/eino/shared/llm"
+)
+
+// PathEnv names the config file if no path is given to Load, default config.yaml.
+const PathEnv = "EINO_CONFIG"
+
+type Config struct {
+	Server  ServerConfig   `yaml:"server"`
+	Model   ModelConfig    `yaml:"model"`
+	LLM     LLMConfig      `yaml:"llm"`
+	Redis   RedisConfig    `yaml:"redis"`
+	Agent   AgentConfig    `yaml:"agent"`
+	Memory  MemoryConfig   `yaml:"memory"`
+	Data    DataConfig     `yaml:"data"`
+	Tracing tracing.Config `yaml:"tracing"`
+	// Guardrail checks the questions, the answers and the tool results, see the guardrail package.
+	Guardrail guardrail.Config `yaml:"guardrail"`
+	// Approval pauses the risky tool calls until the user approves them, see the approval package.
+	Approval approval.Config `yaml:"approval"`
+	// Checkpoint saves the turns before every tool call, so that they can be resumed.
+	Checkpoint checkpoint.Config `yaml:"checkpoint"`
+}
+
+type ServerConfig struct {
+	Port int `yaml:"port" env:"PORT"`
+	// Debug also prints the trace events to the standard logger.
+	Debug bool `yaml:"debug" env:"DEBUG"`
+	// DevOps enables the visual debugging of Eino Dev.
+	DevOps bool   `yaml:"devops" env:"EINO_DEBUG"`
+	LogDir string `yaml:"log_dir" env:"LOG_DIR"`
+	// AuthTokens are the bearer tokens the apis accept, none leaves them open.
+	// The web pages, probes and metrics are always open.
+	AuthTokens []string `yaml:"auth_tokens" env:"AUTH_TOKENS"`
+}
+
+// ModelConfig is the Ark chat and embedding model.
+type ModelConfig struct {
+	BaseURL        string `yaml:"base_url" env:"ARK_BASE_URL"`
+	APIKey         string `yaml:"api_key" env:"ARK_API_KEY"`
+	ChatModel      string `yaml:"chat_model" env:"ARK_CHAT_MODEL"`
+	EmbeddingModel string `yaml:"embedding_model" env:"ARK_EMBEDDING_MODEL"`
+}
+
+// LLMConfig are more chat models by name, with fallback and routes, see LLMFile.
+type LLMConfig struct {
+	// Default is the model of the agents without route, default ark.
+	Default string `yaml:"default" env:"LLM_DEFAULT"`
+	// Fallback are the models the default model falls back to when it fails.
+	Fallback []string               `yaml:"fallback" env:"LLM_FALLBACK"`
+	Models   map[string]*llm.Config `yaml:"models"`
+	// Routes maps an agent or a purpose to a model name, the agent of einoagent is named einoagent
+	// and query_rewrite rewrites the question before retrieval, see einoagent.NewQueryRewrite.
+	Routes map[string]string `yaml:"routes"`
+}
+
+type RedisConfig struct {
+	Addr string `yaml:"addr" env:"REDIS_ADDR"`
+	// IndexAlgorithm is HNSW or FLAT.
+	IndexAlgorithm   string `yaml:"index_algorithm" env:"REDIS_INDEX_ALGORITHM"`
+	HNSWM            int    `yaml:"hnsw_m" env:"REDIS_HNSW_M"`
+	HNSWConstruction int    `yaml:"hnsw_ef_construction" env:"REDIS_HNSW_EF_CONSTRUCTION"`
+	HNSWRuntime      int    `yaml:"hnsw_ef_runtime" env:"REDIS_HNSW_EF_RUNTIME"`
+}
+
+type AgentConfig struct {
+	// TopK is the number of documents retrieved for every question.
+	TopK int `yaml:"top_k" env:"AGENT_TOP_K"`
+	// MaxStep is the max number of steps of the ReAct agent.
+	MaxStep         int `yaml:"max_step" env:"AGENT_MAX_STEP"`
+	MaxRunningChats int `yaml:"max_running_chats" env:"MAX_RUNNING_CHATS"`
+	MaxQueuedChats  int `yaml:"max_queued_chats" env:"MAX_QUEUED_CHATS"`
+}
+
+type MemoryConfig struct {
+	Dir string `yaml:"dir" env:"MEMORY_DIR"`
+	// MaxWindowSize is the number of history messages sent to the model.
+	MaxWindowSize int `yaml:"max_window_size" env:"MEMORY_MAX_WINDOW_SIZE"`
+}
+
+// DataConfig is where the tools keep their files.
+type DataConfig struct {
+	TaskDir  string `yaml:"task_dir" env:"TASK_DIR"`
+	ReposDir string `yaml:"repos_dir" env:"REPOS_DIR"`
+	EinoDir  string `yaml:"eino_dir" env:"EINO_DIR"`
+	// EmbeddingCacheDir keeps the vectors of the indexed texts, see embedder.CachedEmbedder.
+	EmbeddingCacheDir string `yaml:"embedding_cache_dir" env:"EMBEDDING_CACHE_DIR"`
+}
+
+// Default is the config without file and env.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:   8080,
+			DevOps: true,
+			LogDir: "log",
+		},
+		Model: ModelConfig{
+			BaseURL: "https://ark.cn-beijing.volces.com/api/v3",
+		},
+		Redis: RedisConfig{
+			Addr:             "localhost:6379",
+			IndexAlgorithm:   "HNSW",
+			HNSWM:            16,
+			HNSWConstruction: 200,
+			HNSWRuntime:      10,
+		},
+		Agent: AgentConfig{
+			TopK:            8,
+			MaxStep:         25,
+			MaxRunningChats: 8,
+			MaxQueuedChats:  64,
+		},
+		Memory: MemoryConfig{
+			Dir:           "data/memory",
+			MaxWindowSize: 6,
+		},
+		Data: DataConfig{
+			TaskDir:           "./data/task",
+			ReposDir:          "./data/repos",
+			EinoDir:           "./data/eino",
+			EmbeddingCacheDir: "./data/embedding_cache",
+		},
+		Guardrail: guardrail.DefaultConfig(),
+		Approval:  approval.DefaultConfig(),
+		Checkpoint: checkpoint.Config{
+			Backend: checkpoint.BackendFile,
+			Dir:     "data/checkpoints",
+		},
+	}
+}
+
+var (
+	mu      sync.Mutex
+	current *Config
+)
+
+// Load reads .env if there is one, the yaml file at path and the env overrides, and makes
+// the result the config returned by Get. An empty path is $EINO_CONFIG or config.yaml,
+// which may be missing, an explicit path must exist. Load does not validate.
+func Load(path string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env: %w", err)
+	}
+
+	optional := false
+	if path == "" {
+		path = os.Getenv(PathEnv)
+	}
+	if path == "" {
+		path, optional = "config.yaml", true
+	}
+
+	config := Default()
+	b, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err = yaml.Unmarshal(b, config); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	case optional && errors.Is(err, os.ErrNotExist):
+	default:
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err = applyEnv(reflect.ValueOf(config).Elem()); err != nil {
+		return nil, err
+	}
+	config.Redis.IndexAlgorithm = strings.ToUpper(config.Redis.IndexAlgorithm)
+	config.Tracing.Provider = tracing.Provider(strings.ToLower(string(config.Tracing.Provider)))
+	// Langfuse was enabled by its keys alone before there was a provider
+	if config.Tracing.Provider == "" && config.Tracing.Langfuse.PublicKey != "" && config.Tracing.Langfuse.SecretKey != "" {
+		config.Tracing.Provider = tracing.ProviderLangfuse
+	}
+
+	mu.Lock()
+	current = config
+	mu.Unlock()
+	return config, nil
+}
+
+// Get returns the config of the last Load, or loads the default file once if there was none,
+// for the components built without an explicit config.
+func Get() *Config {
+	mu.Lock()
+	config := current
+	mu.Unlock()
+	if config != nil {
+		return config
+	}
+	config, err := Load("")
+	if err != nil {
+		config = Default()
+		mu.Lock()
+		current = config
+		mu.Unlock()
+	}
+	return config
+}
+
+// applyEnv sets the fields with an env tag whose env var is not empty.
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, fv := t.Field(i), v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnv(fv); err != nil {
+				return err
+			}
+			continue
+		}
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if fv.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("env %s: %q is not a duration", name, value)
+			}
+			fv.SetInt(int64(d))
+			continue
+		}
+		switch fv.Kind() {
+		case reflect.String:
+			fv.SetString(value)
+		case reflect.Int, reflect.Int64:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("env %s: %q is not an integer", name, value)
+			}
+			fv.SetInt(n)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("env %s: %q is not a boolean", name, value)
+			}
+			fv.SetBool(b)
+		case reflect.Slice:
+			fv.Set(reflect.ValueOf(strings.Split(value, ",")))
+		default:
+			return fmt.Errorf("env %s: unsupported field type %s", name, fv.Type())
+		}
+	}
+	return nil
+}
+
+// Validate checks everything the agent server and cli need, it reports all problems at once.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port (PORT) must be in 1-65535, got %d", c.Server.Port))
+	}
+	if err := c.ValidateModels(true); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, c.validateRedis()...)
+	if c.Agent.TopK <= 0 {
+		errs = append(errs, fmt.Errorf("agent.top_k (AGENT_TOP_K) must be positive, got %d", c.Agent.TopK))
+	}
+	if c.Agent.MaxStep <= 0 {
+		errs = append(errs, fmt.Errorf("agent.max_step (AGENT_MAX_STEP) must be positive, got %d", c.Agent.MaxStep))
+	}
+	if c.Agent.MaxRunningChats <= 0 || c.Agent.MaxQueuedChats <= 0 {
+		errs = append(errs, fmt.Errorf("agent.max_running_chats and agent.max_queued_chats must be positive"))
+	}
+	if c.Memory.Dir == "" || c.Memory.MaxWindowSize <= 0 {
+		errs = append(errs, fmt.Errorf("memory.dir must be set and memory.max_window_size must be positive"))
+	}
+	if c.Data.TaskDir == "" || c.Data.ReposDir == "" || c.Data.EinoDir == "" {
+		errs = append(errs, fmt.Errorf("data.task_dir, data.repos_dir and data.eino_dir must be set"))
+	}
+	if err := c.Tracing.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Guardrail.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Approval.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Checkpoint.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateIndexing checks what indexing documents needs: the embedding model and redis.
+func (c *Config) ValidateIndexing() error {
+	errs := c.validateRedis()
+	if err := c.ValidateModels(false); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateModels reports the missing model settings, none is needed offline, see fake.Offline.
+func (c *Config) ValidateModels(chat bool) error {
+	if fake.Offline() {
+		return nil
+	}
+	var missing []string
+	if c.Model.APIKey == "" {
+		missing = append(missing, "model.api_key (ARK_API_KEY)")
+	}
+	if chat && c.Model.ChatModel == "" && len(c.LLM.Models) == 0 {
+		missing = append(missing, "model.chat_model (ARK_CHAT_MODEL)")
+	}
+	if c.Model.EmbeddingModel == "" {
+		missing = append(missing, "model.embedding_model (ARK_EMBEDDING_MODEL)")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s required, set them in config.yaml or .env", strings.Join(missing, ", "))
+	}
+	if chat {
+		if _, err := llm.NewRegistry(c.LLMFile()); err != nil {
+			return fmt.Errorf("llm: %w", err)
+		}
+	}
+	return nil
+}
+
+// LLMFile is the models of the llm section, plus the model section as the model named ark
+// unless llm.models has its own ark. Without any model offline, it is the fake model.
+func (c *Config) LLMFile() *llm.File {
+	file := &llm.File{
+		Default: c.LLM.Default,
+		Models:  make(map[string]*llm.Config, len(c.LLM.Models)+1),
+		Routes:  c.LLM.Routes,
+	}
+	for name, model := range c.LLM.Models {
+		model := *model
+		file.Models[name] = &model
+	}
+	if _, ok := file.Models[string(llm.ProviderArk)]; !ok && c.Model.ChatModel != "" {
+		file.Models[string(llm.ProviderArk)] = &llm.Config{
+			Provider: llm.ProviderArk,
+			Model:    c.Model.ChatModel,
+			APIKey:   c.Model.APIKey,
+			BaseURL:  c.Model.BaseURL,
+		}
+	}
+	// offline the fake model answers, see fake.Offline
+	if len(file.Models) == 0 && fake.Offline() {
+		file.Models[string(llm.ProviderFake)] = &llm.Config{Provider: llm.ProviderFake}
+	}
+	if file.Default == "" && len(c.LLM.Models) > 0 {
+		file.Default = string(llm.ProviderArk)
+	}
+	if def := file.Models[file.Default]; def != nil && len(c.LLM.Fallback) > 0 {
+		def.Fallback = c.LLM.Fallback
+	}
+	return file
+}
+
+func (c *Config) validateRedis() []error {
+	var errs []error
+	if c.Redis.Addr == "" {
+		errs = append(errs, fmt.Errorf("redis.addr (REDIS_ADDR) must be set"))
+	}
+	if c.Redis.IndexAlgorithm != "HNSW" && c.Redis.IndexAlgorithm != "FLAT" {
+		errs = append(errs, fmt.Errorf("redis.index_algorithm (REDIS_INDEX_ALGORITHM) must be HNSW or FLAT, got %q", c.Redis.IndexAlgorithm))
+	}
+	return errs
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package embedder
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+type BatchConfig struct {
+	// MaxBatchSize is the upper bound of texts per request, default 32.
+	// The batch size is halved on rate limits and grows back after successful requests.
+	MaxBatchSize int
+	// Concurrency limits the requests in flight, default 4.
+	Concurrency int
+	// MaxRetries on rate limits and temporary errors, default 5.
+	MaxRetries int
+	// BaseBackoff doubles on every retry up to MaxBackoff, default 500ms and 30s.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Metrics     *Metrics
+}
+
+// BatchEmbedder splits large inputs into batches and embeds them concurrently,
+// retrying with exponential backoff when the provider rate limits.
+type BatchEmbedder struct {
+	emb    embedding.Embedder
+	config *BatchConfig
+
+	mu        sync.Mutex
+	batchSize int
+	successes int
+}
+
+// NewBatchEmbedder copies config, which can be nil.
+func NewBatchEmbedder(emb embedding.Embedder, config *BatchConfig) (*BatchEmbedder, error) {
+	if emb == nil {
+		return nil, fmt.Errorf("embedding cannot be nil")
+	}
+	c := BatchConfig{}
+	if config != nil {
+		c = *config
+	}
+	if c.MaxBatchSize <= 0 {
+		c.MaxBatchSize = 32
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 4
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = 5
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = 500 * time.Millisecond
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 30 * time.Second
+	}
+	if c.Metrics == nil {
+		c.Metrics = DefaultMetrics
+	}
+	return &BatchEmbedder{emb: emb, config: &c, batchSize: c.MaxBatchSize}, nil
+}
+
+func (b *BatchEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		sem      = make(chan struct{}, b.config.Concurrency)
+	)
+
+	for start := 0; start < len(texts); {
+		end := min(start+b.currentBatchSize(), len(texts))
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			batch, err := b.embedWithRetry(ctx, texts[start:end], opts...)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			copy(vectors[start:end], batch)
+		}(start, end)
+
+		start = end
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+func (b *BatchEmbedder) GetType() string {
+	if typ, ok := components.GetType(b.emb); ok {
+		return "Batch" + typ
+	}
+	return "Batch"
+}
+
+func (b *BatchEmbedder) embedWithRetry(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	backoff := b.config.BaseBackoff
+	for attempt := 0; ; attempt++ {
+		b.config.Metrics.Requests.Add(1)
+		b.config.Metrics.Texts.Add(int64(len(texts)))
+
+		vectors, err := b.emb.EmbedStrings(ctx, texts, opts...)
+		if err == nil {
+			if len(vectors) != len(texts) {
+				return nil, fmt.Errorf("invalid vector length, expected=%d, got=%d", len(texts), len(vectors))
+			}
+			b.onSuccess()
+			return vectors, nil
+		}
+
+		rateLimited := IsRateLimited(err)
+		if rateLimited {
+			b.config.Metrics.RateLimited.Add(1)
+			b.onRateLimited()
+		}
+		if attempt >= b.config.MaxRetries || (!rateLimited && !isTemporary(err)) {
+			return nil, err
+		}
+
+		b.config.Metrics.Retries.Add(1)
+		// jitter spreads the retries of concurrent batches
+		wait := time.Duration(rand.Int63n(int64(backoff))) + backoff/2
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, b.config.MaxBackoff)
+	}
+}
+
+func (b *BatchEmbedder) currentBatchSize() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.batchSize
+}
+
+func (b *BatchEmbedder) onRateLimited() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batchSize = max(b.batchSize/2, 1)
+	b.successes = 0
+}
+
+// onSuccess doubles the batch size after a few successful requests in a row.
+func (b *BatchEmbedder) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.successes++
+	if b.successes >= 3 && b.batchSize < b.config.MaxBatchSize {
+		b.batchSize = min(b.batchSize*2, b.config.MaxBatchSize)
+		b.successes = 0
+	}
+}
+
+// IsRateLimited tells from the error message whether the provider throttled the request.
+func IsRateLimited(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"429", "rate limit", "ratelimit", "too many requests", "toomanyrequests", "quota"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTemporary(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"timeout", "connection reset", "eof", "502", "503", "504", "server overloaded"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package embedder
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+// go test -v -run Test_BatchEmbedder ./pkg/embedder
+func Test_BatchEmbedder(t *testing.T) {
+	ctx := context.Background()
+	texts := []string{"a", "b", "c", "d", "e"}
+	want, _ := fake.NewEmbedder(8).EmbedStrings(ctx, texts)
+
+	// a rate limited batch is retried, the next batches are smaller
+	stub := &stubEmbedder{Embedder: fake.NewEmbedder(8), errs: []error{errors.New("429 Too Many Requests")}}
+	config := &BatchConfig{MaxBatchSize: 4, Concurrency: 1, BaseBackoff: time.Millisecond, Metrics: &Metrics{}}
+	emb, err := NewBatchEmbedder(stub, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors, err := emb.EmbedStrings(ctx, texts)
+	if err != nil || !reflect.DeepEqual(vectors, want) {
+		t.Fatalf("vectors: %v, err: %v", vectors, err)
+	}
+	if !reflect.DeepEqual(stub.calls, [][]string{{"a", "b", "c", "d"}, {"a", "b", "c", "d"}, {"e"}}) {
+		t.Fatalf("calls: %v", stub.calls)
+	}
+	if emb.currentBatchSize() != 2 {
+		t.Fatalf("batch size: %d", emb.currentBatchSize())
+	}
+	m := config.Metrics
+	if m.Requests.Load() != 3 || m.Texts.Load() != 9 || m.Retries.Load() != 1 || m.RateLimited.Load() != 1 {
+		t.Fatalf("metrics: %s", m)
+	}
+
+	// other errors are not retried
+	stub = &stubEmbedder{Embedder: fake.NewEmbedder(8), errs: []error{errors.New("invalid api key")}}
+	if emb, err = NewBatchEmbedder(stub, config); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = emb.EmbedStrings(ctx, texts[:1]); err == nil || len(stub.calls) != 1 {
+		t.Fatalf("calls: %v, err: %v", stub.calls, err)
+	}
+
+	// the defaults are not written into the config of the caller
+	if config.MaxRetries != 0 || config.MaxBackoff != 0 {
+		t.Fatalf("config: %+v", config)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package embedder
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+type CacheConfig struct {
+	// Dir stores one file per vector, usually config.DataConfig.EmbeddingCacheDir, default ./data/embedding_cache.
+	Dir string
+	// Namespace separates vectors of different models, usually the model name. It has to change with
+	// every setting of the wrapped embedder that changes its vectors, e.g. their dimensions.
+	Namespace string
+	Metrics   *Metrics
+}
+
+// ErrUncachedOption is returned for embedding options the cache key cannot tell apart,
+// such as the implementation specific options of a provider.
+var ErrUncachedOption = errors.New("embedding option is not supported by the cache, only embedding.WithModel is")
+
+// CachedEmbedder keeps vectors on disk keyed by the hash of namespace, model option and text,
+// only texts never seen before are sent to the wrapped embedder.
+// embedding.WithModel is the only option accepted, the others may change the vectors without
+// changing the key, so they fail with ErrUncachedOption.
+type CachedEmbedder struct {
+	emb    embedding.Embedder
+	config *CacheConfig
+}
+
+// NewCachedEmbedder copies config, which can be nil.
+func NewCachedEmbedder(emb embedding.Embedder, config *CacheConfig) (*CachedEmbedder, error) {
+	if emb == nil {
+		return nil, fmt.Errorf("embedding cannot be nil")
+	}
+	c := CacheConfig{}
+	if config != nil {
+		c = *config
+	}
+	if c.Dir == "" {
+		c.Dir = "./data/embedding_cache"
+	}
+	if c.Metrics == nil {
+		c.Metrics = DefaultMetrics
+	}
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	return &CachedEmbedder{emb: emb, config: &c}, nil
+}
+
+func (c *CachedEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	namespace, err := c.namespace(opts...)
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([][]float64, len(texts))
+
+	// same texts in one call are embedded once
+	missing := make(map[string][]int)
+	var missingTexts []string
+	for i, text := range texts {
+		if vector, ok := c.load(namespace, text); ok {
+			vectors[i] = vector
+			c.config.Metrics.CacheHits.Add(1)
+			continue
+		}
+		c.config.Metrics.CacheMisses.Add(1)
+		if _, ok := missing[text]; !ok {
+			missingTexts = append(missingTexts, text)
+		}
+		missing[text] = append(missing[text], i)
+	}
+
+	if len(missingTexts) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := c.emb.EmbedStrings(ctx, missingTexts, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missingTexts) {
+		return nil, fmt.Errorf("invalid vector length, expected=%d, got=%d", len(missingTexts), len(embedded))
+	}
+
+	for i, text := range missingTexts {
+		for _, idx := range missing[text] {
+			vectors[idx] = embedded[i]
+		}
+		if err = c.store(namespace, text, embedded[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return vectors, nil
+}
+
+func (c *CachedEmbedder) GetType() string {
+	if typ, ok := components.GetType(c.emb); ok {
+		return "Cached" + typ
+	}
+	return "Cached"
+}
+
+// namespace adds the model of opts to the namespace of the config.
+func (c *CachedEmbedder) namespace(opts ...embedding.Option) (string, error) {
+	namespace := c.config.Namespace
+	for _, opt := range opts {
+		model := embedding.GetCommonOptions(nil, opt).Model
+		if model == nil {
+			return "", ErrUncachedOption
+		}
+		namespace = c.config.Namespace + "\x00model=" + *model
+	}
+	return namespace, nil
+}
+
+func (c *CachedEmbedder) path(namespace, text string) string {
+	sum := sha256.Sum256([]byte(namespace + "\x00" + text))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.config.Dir, key[:2], key+".bin")
+}
+
+func (c *CachedEmbedder) load(namespace, text string) ([]float64, bool) {
+	b, err := os.ReadFile(c.path(namespace, text))
+	if err != nil || len(b) == 0 || len(b)%8 != 0 {
+		return nil, false
+	}
+	vector := make([]float64, len(b)/8)
+	if err = binary.Read(bytes.NewReader(b), binary.LittleEndian, vector); err != nil {
+		return nil, false
+	}
+	return vector, true
+}
+
+// store writes to a temp file first, so a crash never leaves a truncated vector behind.
+func (c *CachedEmbedder) store(namespace, text string, vector []float64) error {
+	path := c.path(namespace, text)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, vector); err != nil {
+		return fmt.Errorf("failed to encode vector: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	if _, err = tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to close cache file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to rename cache file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package embedder
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/components/embedding"
+
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+// stubEmbedder embeds with the fake embedder, it records the texts of every call
+// and fails the first calls with errs.
+type stubEmbedder struct {
+	*fake.Embedder
+	errs []error
+
+	mu    sync.Mutex
+	calls [][]string
+}
+
+func (s *stubEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, texts)
+	n := len(s.calls)
+	s.mu.Unlock()
+	if n <= len(s.errs) {
+		return nil, s.errs[n-1]
+	}
+	return s.Embedder.EmbedStrings(ctx, texts, opts...)
+}
+
+// go test -v -run Test_CachedEmbedder ./pkg/embedder
+func Test_CachedEmbedder(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubEmbedder{Embedder: fake.NewEmbedder(8)}
+	config := &CacheConfig{Dir: t.TempDir(), Namespace: "m", Metrics: &Metrics{}}
+	emb, err := NewCachedEmbedder(stub, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := fake.NewEmbedder(8).EmbedStrings(ctx, []string{"a b", "c", "a b"})
+
+	// misses, the repeated text is embedded once
+	vectors, err := emb.EmbedStrings(ctx, []string{"a b", "c", "a b"})
+	if err != nil || !reflect.DeepEqual(vectors, want) {
+		t.Fatalf("vectors: %v, err: %v", vectors, err)
+	}
+	if !reflect.DeepEqual(stub.calls, [][]string{{"a b", "c"}}) {
+		t.Fatalf("calls: %v", stub.calls)
+	}
+
+	// hits, only the new text is embedded
+	if vectors, err = emb.EmbedStrings(ctx, []string{"c", "d", "a b"}); err != nil || !reflect.DeepEqual(vectors[2], want[0]) {
+		t.Fatalf("vectors: %v, err: %v", vectors, err)
+	}
+	if !reflect.DeepEqual(stub.calls[1:], [][]string{{"d"}}) {
+		t.Fatalf("calls: %v", stub.calls)
+	}
+	if hits, misses := config.Metrics.CacheHits.Load(), config.Metrics.CacheMisses.Load(); hits != 2 || misses != 4 {
+		t.Fatalf("hits: %d, misses: %d", hits, misses)
+	}
+	if rate := config.Metrics.HitRate(); rate != 2.0/6 {
+		t.Fatalf("hit rate: %v", rate)
+	}
+
+	// the model option is part of the key, the others are rejected
+	if _, err = emb.EmbedStrings(ctx, []string{"c"}, embedding.WithModel("other")); err != nil || len(stub.calls) != 3 {
+		t.Fatalf("calls: %v, err: %v", stub.calls, err)
+	}
+	type dimensions struct{ n int }
+	opt := embedding.WrapImplSpecificOptFn(func(d *dimensions) { d.n = 4 })
+	if _, err = emb.EmbedStrings(ctx, []string{"c"}, opt); !errors.Is(err, ErrUncachedOption) {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the defaults are not written into the config of the caller
+	config = &CacheConfig{}
+	if _, err = NewCachedEmbedder(stub, config); err != nil || config.Dir != "" || config.Metrics != nil {
+		t.Fatalf("config: %+v, err: %v", config, err)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package embedder
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// DefaultMetrics is shared by the embedders which are not given their own Metrics.
+var DefaultMetrics = &Metrics{}
+
+// Metrics counts cache lookups and calls to the wrapped embedder, safe for concurrent use.
+type Metrics struct {
+	CacheHits   atomic.Int64
+	CacheMisses atomic.Int64
+	Requests    atomic.Int64 // calls to the wrapped embedder
+	Texts       atomic.Int64 // texts sent to the wrapped embedder
+	Retries     atomic.Int64
+	RateLimited atomic.Int64
+}
+
+// HitRate is the share of texts served from cache, 0 if nothing was looked up.
+func (m *Metrics) HitRate() float64 {
+	hits, misses := m.CacheHits.Load(), m.CacheMisses.Load()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+func (m *Metrics) String() string {
+	return fmt.Sprintf("cache hits: %d, misses: %d, hit rate: %.2f%%, requests: %d, texts: %d, retries: %d, rate limited: %d",
+		m.CacheHits.Load(), m.CacheMisses.Load(), m.HitRate()*100,
+		m.Requests.Load(), m.Texts.Load(), m.Retries.Load(), m.RateLimited.Load())
+}
@@ -1,40 +0,0 @@
-/*
- * Copyright 2025 CloudWeGo Authors
- *
- * Licensed under the Apache License, Version 2.0 (the "License");
- * you may not use this file except in compliance with the License.
- * You may obtain a copy of the License at
- *
- *     http://www.apache.org/licenses/LICENSE-2.0
- *
- * Unless required by applicable law or agreed to in writing, software
- * distributed under the License is distributed on an "AS IS" BASIS,
- * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
- * See the License for the specific language governing permissions and
- * limitations under the License.
- */
-
-package env
-
-import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
-)
-
-func init() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("❌ [ERROR] Error loading .env file: %v", err)
-	}
-
-}
-
-func MustHasEnvs(envs ...string) {
-	for _, env := range envs {
-		if os.Getenv(env) == "" {
-			log.Fatalf("❌ [ERROR] env [%s] is required, but is not set now, please check your .env file", env)
-		}
-	}
-}
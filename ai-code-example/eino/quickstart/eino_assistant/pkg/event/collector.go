@@ -0,0 +1,161 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	template "github.com/cloudwego/eino/utils/callbacks"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/usage"
+)
+
+// Collector turns the callbacks of a graph run into events of the Run,
+// and sums the token usage of every model call.
+type Collector struct {
+	run *Run
+
+	mu      sync.Mutex
+	usage   Usage
+	pending sync.WaitGroup
+}
+
+func NewCollector(run *Run) *Collector {
+	return &Collector{run: run}
+}
+
+// Handler is passed to the graph with compose.WithCallbacks.
+func (c *Collector) Handler() callbacks.Handler {
+	return template.NewHandlerHelper().
+		ChatModel(&template.ModelCallbackHandler{
+			OnEnd: func(ctx context.Context, info *callbacks.RunInfo, output *model.CallbackOutput) context.Context {
+				if output != nil {
+					c.emitReasoning(output.Message)
+					c.addUsage(usage.Of(output))
+				}
+				return ctx
+			},
+			OnEndWithStreamOutput: func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[*model.CallbackOutput]) context.Context {
+				c.pending.Add(1)
+				go func() {
+					defer c.pending.Done()
+					defer output.Close()
+
+					// streamed usage is reported by the last chunks, only the last one counts
+					var last *model.TokenUsage
+					for {
+						chunk, err := output.Recv()
+						if err != nil {
+							break
+						}
+						if chunk == nil {
+							continue
+						}
+						c.emitReasoning(chunk.Message)
+						if u := usage.Of(chunk); u != nil {
+							last = u
+						}
+					}
+					c.addUsage(last)
+				}()
+				return ctx
+			},
+		}).
+		Retriever(&template.RetrieverCallbackHandler{
+			OnEnd: func(ctx context.Context, info *callbacks.RunInfo, output *retriever.CallbackOutput) context.Context {
+				retrieval := &Retrieval{Documents: make([]*Document, 0, len(output.Docs))}
+				for _, doc := range output.Docs {
+					retrieval.Documents = append(retrieval.Documents, &Document{
+						ID:       doc.ID,
+						Content:  doc.Content,
+						Score:    doc.Score(),
+						MetaData: doc.MetaData,
+					})
+				}
+				c.run.Emit(TypeRetrieval, retrieval)
+				return ctx
+			},
+		}).
+		ToolsNode(&template.ToolsNodeCallbackHandlers{
+			OnStart: func(ctx context.Context, info *callbacks.RunInfo, input *schema.Message) context.Context {
+				for _, tc := range input.ToolCalls {
+					c.run.Emit(TypeToolCall, &ToolCall{ID: tc.ID, Name: tc.Function.Name, Arguments: tc.Function.Arguments})
+				}
+				return ctx
+			},
+			OnEnd: func(ctx context.Context, info *callbacks.RunInfo, output []*schema.Message) context.Context {
+				for _, msg := range output {
+					c.run.Emit(TypeToolResult, &ToolResult{ID: msg.ToolCallID, Name: msg.Name, Content: msg.Content})
+				}
+				return ctx
+			},
+		}).
+		Handler()
+}
+
+// Usage waits for the streamed model outputs to be read and returns the total usage.
+func (c *Collector) Usage() *Usage {
+	c.pending.Wait()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	usage := c.usage
+	return &usage
+}
+
+func (c *Collector) emitReasoning(msg *schema.Message) {
+	if reasoning := ReasoningContent(msg); reasoning != "" {
+		c.run.Emit(TypeReasoning, &Reasoning{Content: reasoning})
+	}
+}
+
+func (c *Collector) addUsage(usage *model.TokenUsage) {
+	if usage == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.usage.PromptTokens += usage.PromptTokens
+	c.usage.CompletionTokens += usage.CompletionTokens
+	c.usage.TotalTokens += usage.TotalTokens
+}
+
+// Forward reads the answer into token events and finishes the run with done, or error
+// if the stream fails. It always reads sr to the end.
+func Forward(run *Run, collector *Collector, sr *schema.StreamReader[*schema.Message]) {
+	defer sr.Close()
+	for {
+		msg, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			run.Emit(TypeError, &Error{Code: ErrorCode(run.Context(), err), Message: err.Error()})
+			return
+		}
+		if msg.Content != "" {
+			run.Emit(TypeToken, &Token{Content: msg.Content})
+		}
+	}
+	run.Emit(TypeDone, &Done{Usage: collector.Usage()})
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package event defines the events streamed by /agent/api/chat.
+//
+// Every event is sent as an SSE message whose event field is the Type and whose
+// data field is the JSON payload of that type:
+//
+//	token              Token             a chunk of the answer
+//	reasoning          Reasoning         a chunk of the model reasoning, if the model returns it
+//	tool_call          ToolCall          the agent calls a tool
+//	tool_result        ToolResult        the tool returned
+//	approval_required  ApprovalRequired  a tool call waits for POST /agent/api/approve
+//	retrieval          Retrieval         documents retrieved from the knowledge base
+//	error              Error             the turn failed, was cancelled or blocked, no more events follow
+//	done               Done              the turn finished, with the token usage of all model calls
+//	paused             Paused            the turn waits for the approvals before it, no more events follow
+//
+// Event ids are "<run id>:<seq>", a client that lost the connection sends the last id
+// it saw as Last-Event-ID to receive the rest of the turn. A ping event without id
+// is sent when nothing happened for a while, it carries no data worth reading.
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/guardrail"
+)
+
+type Type string
+
+const (
+	TypeToken      Type = "token"
+	TypeReasoning  Type = "reasoning"
+	TypeToolCall   Type = "tool_call"
+	TypeToolResult Type = "tool_result"
+	TypeRetrieval  Type = "retrieval"
+	TypeError      Type = "error"
+	TypeDone       Type = "done"
+	TypePing       Type = "ping"
+
+	TypeApprovalRequired Type = "approval_required"
+	TypePaused           Type = "paused"
+)
+
+type Event struct {
+	ID   string `json:"id"`
+	Type Type   `json:"type"`
+	Data any    `json:"data"`
+	Seq  int    `json:"-"`
+}
+
+type Token struct {
+	Content string `json:"content"`
+}
+
+type Reasoning struct {
+	Content string `json:"content"`
+}
+
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type ToolResult struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Content string `json:"content"`
+}
+
+// ApprovalRequired is a risky tool call, the turn is paused until the client approves or
+// rejects ID, see the approval package.
+type ApprovalRequired struct {
+	ID        string `json:"id"`
+	Tool      string `json:"tool"`
+	Arguments string `json:"arguments"`
+	Risk      string `json:"risk"`
+}
+
+type Retrieval struct {
+	Query     string      `json:"query,omitempty"`
+	Documents []*Document `json:"documents"`
+}
+
+type Document struct {
+	ID       string         `json:"id"`
+	Content  string         `json:"content"`
+	Score    float64        `json:"score"`
+	MetaData map[string]any `json:"metadata,omitempty"`
+}
+
+const (
+	ErrorCodeFailed    = "failed"
+	ErrorCodeCancelled = "cancelled"
+	// ErrorCodeBlocked is a question or an answer rejected by the guardrail.
+	ErrorCodeBlocked = "blocked"
+)
+
+// ErrorCode is the code of the error that ended a turn.
+func ErrorCode(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, guardrail.ErrBlocked):
+		return ErrorCodeBlocked
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		return ErrorCodeCancelled
+	default:
+		return ErrorCodeFailed
+	}
+}
+
+type Error struct {
+	// Code is ErrorCodeFailed, ErrorCodeCancelled or ErrorCodeBlocked.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Done struct {
+	Usage *Usage `json:"usage"`
+}
+
+// Paused ends a turn that waits for approval, POST /agent/api/approve resumes it. Usage
+// is the token usage of the model calls until then.
+type Paused struct {
+	Usage *Usage `json:"usage"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ReasoningKeys are the keys of Message.Extra where models without the ReasoningContent field
+// put their reasoning, e.g. ark-reasoning-content of older Ark versions.
+var ReasoningKeys = []string{"reasoning_content", "ark-reasoning-content"}
+
+// ReasoningContent is the reasoning of msg, from Message.ReasoningContent or else from Extra.
+func ReasoningContent(msg *schema.Message) string {
+	if msg == nil {
+		return ""
+	}
+	if msg.ReasoningContent != "" {
+		return msg.ReasoningContent
+	}
+	for _, key := range ReasoningKeys {
+		if s, _ := msg.Extra[key].(string); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// Decode parses the data of an SSE message of type typ into its payload, e.g. *Token.
+// Pings and unknown types are nil.
+func Decode(typ Type, data []byte) (any, error) {
+	var v any
+	switch typ {
+	case TypeToken:
+		v = &Token{}
+	case TypeReasoning:
+		v = &Reasoning{}
+	case TypeToolCall:
+		v = &ToolCall{}
+	case TypeToolResult:
+		v = &ToolResult{}
+	case TypeApprovalRequired:
+		v = &ApprovalRequired{}
+	case TypeRetrieval:
+		v = &Retrieval{}
+	case TypeError:
+		v = &Error{}
+	case TypeDone:
+		v = &Done{}
+	case TypePaused:
+		v = &Paused{}
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("invalid %s event: %w", typ, err)
+	}
+	return v, nil
+}
+
+func formatID(runID string, seq int) string {
+	return runID + ":" + strconv.Itoa(seq)
+}
+
+// ParseID splits an event id into the run id and the sequence number.
+func ParseID(id string) (runID string, seq int, err error) {
+	i := strings.LastIndex(id, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("invalid event id %q", id)
+	}
+	seq, err = strconv.Atoi(id[i+1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid event id %q", id)
+	}
+	return id[:i], seq, nil
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ResumeGrace is how long a run without subscribers keeps going, waiting for the client to resume,
+// and how long a Hub keeps a finished run, so that the client can still read its last events.
+var ResumeGrace = 15 * time.Second
+
+// Run buffers the events of one chat turn. Subscribers can join at any time and
+// read from any sequence number, so a client can resume after a disconnect.
+type Run struct {
+	ID             string
+	ConversationID string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	events      []*Event
+	finished    bool
+	updated     chan struct{}
+	subscribers int
+	idle        *time.Timer
+	// onFinish is called once when the run finishes, with r.mu held
+	onFinish func()
+}
+
+// NewRun creates a run which is not kept by any Hub, so it cannot be resumed.
+// The context of the run keeps the values of ctx but is only cancelled by Cancel.
+func NewRun(ctx context.Context, conversationID string) *Run {
+	r := &Run{
+		ID:             strconv.FormatInt(time.Now().UnixNano(), 36),
+		ConversationID: conversationID,
+		updated:        make(chan struct{}),
+	}
+	r.ctx, r.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	return r
+}
+
+// Context is passed to the graph, so cancelling the run stops the model and the tools.
+func (r *Run) Context() context.Context {
+	return r.ctx
+}
+
+// Cancel stops the turn, the answer stream then fails with context.Canceled.
+func (r *Run) Cancel() {
+	r.cancel()
+}
+
+// Subscribe counts a client reading the run, the returned function must be called when it leaves.
+// When the last client leaves an unfinished run, the run is cancelled unless a client
+// subscribes again within ResumeGrace.
+func (r *Run) Subscribe() (unsubscribe func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers++
+	if r.idle != nil {
+		r.idle.Stop()
+		r.idle = nil
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.subscribers--
+			if r.subscribers == 0 && !r.finished {
+				r.idle = time.AfterFunc(ResumeGrace, r.cancel)
+			}
+		})
+	}
+}
+
+// Emit appends an event, events emitted after error, done or paused are dropped.
+func (r *Run) Emit(typ Type, data any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.finished {
+		return
+	}
+
+	seq := len(r.events) + 1
+	r.events = append(r.events, &Event{ID: formatID(r.ID, seq), Type: typ, Data: data, Seq: seq})
+	if typ == TypeError || typ == TypeDone || typ == TypePaused {
+		r.finished = true
+		if r.idle != nil {
+			r.idle.Stop()
+			r.idle = nil
+		}
+		// release the context of the finished run
+		r.cancel()
+		if r.onFinish != nil {
+			r.onFinish()
+		}
+	}
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
+
+func (r *Run) Finished() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.finished
+}
+
+// Next returns the events after seq, waiting for new ones if there are none yet.
+// It returns io.EOF once the run is finished and every event was read.
+func (r *Run) Next(ctx context.Context, seq int) ([]*Event, error) {
+	for {
+		r.mu.Lock()
+		if seq < len(r.events) {
+			events := r.events[max(seq, 0):]
+			r.mu.Unlock()
+			return events, nil
+		}
+		if r.finished {
+			r.mu.Unlock()
+			return nil, io.EOF
+		}
+		updated := r.updated
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-updated:
+		}
+	}
+}
+
+// Hub keeps the latest run of every conversation, a finished run is dropped after ResumeGrace.
+type Hub struct {
+	mu   sync.Mutex
+	runs map[string]*Run
+}
+
+func NewHub() *Hub {
+	return &Hub{runs: make(map[string]*Run)}
+}
+
+// Start creates the run of a new turn, it replaces the previous run of the conversation.
+func (h *Hub) Start(ctx context.Context, conversationID string) *Run {
+	run := NewRun(ctx, conversationID)
+	run.onFinish = func() {
+		time.AfterFunc(ResumeGrace, func() { h.drop(conversationID, run) })
+	}
+	h.mu.Lock()
+	h.runs[conversationID] = run
+	h.mu.Unlock()
+	return run
+}
+
+// Remove cancels the run of the conversation and forgets it, e.g. when the conversation is deleted.
+func (h *Hub) Remove(conversationID string) {
+	h.mu.Lock()
+	run, ok := h.runs[conversationID]
+	delete(h.runs, conversationID)
+	h.mu.Unlock()
+	if ok {
+		run.Cancel()
+	}
+}
+
+// drop forgets run unless a newer run of the conversation replaced it.
+func (h *Hub) drop(conversationID string, run *Run) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.runs[conversationID] == run {
+		delete(h.runs, conversationID)
+	}
+}
+
+// Running returns the unfinished run of the conversation.
+func (h *Hub) Running(conversationID string) (*Run, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	run, ok := h.runs[conversationID]
+	if !ok || run.Finished() {
+		return nil, false
+	}
+	return run, true
+}
+
+// Resume finds the run of lastEventID and the sequence number to continue after.
+func (h *Hub) Resume(conversationID, lastEventID string) (*Run, int, bool) {
+	runID, seq, err := ParseID(lastEventID)
+	if err != nil {
+		return nil, 0, false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	run, ok := h.runs[conversationID]
+	if !ok || run.ID != runID {
+		return nil, 0, false
+	}
+	return run, seq, true
+}
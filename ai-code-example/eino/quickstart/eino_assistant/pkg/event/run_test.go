@@ -0,0 +1,109 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+// go test -v -run Test_HubDropsFinishedRuns ./pkg/event
+func Test_HubDropsFinishedRuns(t *testing.T) {
+	grace := ResumeGrace
+	ResumeGrace = 50 * time.Millisecond
+	defer func() { ResumeGrace = grace }()
+
+	h := NewHub()
+	run := h.Start(context.Background(), "a")
+	run.Emit(TypeDone, &Done{})
+
+	// a client that missed the end can still resume within the grace period
+	if _, _, ok := h.Resume("a", formatID(run.ID, 0)); !ok {
+		t.Fatal("finished run is gone before the grace period")
+	}
+	time.Sleep(2 * ResumeGrace)
+	if _, _, ok := h.Resume("a", formatID(run.ID, 0)); ok {
+		t.Fatal("finished run is kept after the grace period")
+	}
+
+	// the timer of a replaced run does not drop the newer one
+	old := h.Start(context.Background(), "b")
+	old.Emit(TypeDone, &Done{})
+	newer := h.Start(context.Background(), "b")
+	time.Sleep(2 * ResumeGrace)
+	if run, ok := h.Running("b"); !ok || run != newer {
+		t.Fatal("newer run was dropped with the finished one")
+	}
+}
+
+// go test -v -run Test_HubRemove ./pkg/event
+func Test_HubRemove(t *testing.T) {
+	h := NewHub()
+	run := h.Start(context.Background(), "a")
+	h.Remove("a")
+
+	if _, ok := h.Running("a"); ok {
+		t.Fatal("removed run is still running")
+	}
+	if run.Context().Err() == nil {
+		t.Fatal("removed run is not cancelled")
+	}
+}
+
+// go test -v -run Test_CollectorReasoning ./pkg/event
+func Test_CollectorReasoning(t *testing.T) {
+	ctx := context.Background()
+	answer := schema.AssistantMessage("eino is a framework", nil)
+	answer.ReasoningContent = "the user asks about eino"
+	script := &fake.Script{Responses: []*fake.Response{{Message: answer}}}
+
+	chain := compose.NewChain[[]*schema.Message, *schema.Message]()
+	chain.AppendChatModel(fake.NewChatModel(script))
+	r, err := chain.Compile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := NewRun(ctx, "a")
+	collector := NewCollector(run)
+	sr, err := r.Stream(ctx, []*schema.Message{schema.UserMessage("what is eino")}, compose.WithCallbacks(collector.Handler()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	Forward(run, collector, sr)
+
+	events, err := run.Next(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reasoning strings.Builder
+	for _, e := range events {
+		if e.Type == TypeReasoning {
+			reasoning.WriteString(e.Data.(*Reasoning).Content)
+		}
+	}
+	if reasoning.String() != answer.ReasoningContent {
+		t.Fatalf("reasoning events = %q, want %q", reasoning.String(), answer.ReasoningContent)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package guardrail
+
+import (
+	"regexp"
+	"strings"
+)
+
+type regexpCheck struct {
+	name     string
+	kind     string
+	patterns []*regexp.Regexp
+}
+
+func (c *regexpCheck) Name() string {
+	return c.name
+}
+
+func (c *regexpCheck) Find(text string) []Match {
+	var matches []Match
+	for _, re := range c.patterns {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			matches = append(matches, Match{Start: loc[0], End: loc[1], Kind: c.kind})
+		}
+	}
+	return matches
+}
+
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget)\s+(all\s+|any\s+)?(the\s+|your\s+)?(previous|prior|above|earlier|preceding)\s+(instructions|prompts?|rules|messages)`),
+	regexp.MustCompile(`(?i)\b(reveal|show|print|repeat|output)\s+(me\s+)?(your|the)\s+(system\s+prompt|initial\s+instructions|hidden\s+instructions)`),
+	regexp.MustCompile(`(?i)\b(developer|jailbreak|DAN)\s+mode\b`),
+	regexp.MustCompile(`(?i)<\|?\s*(im_start|im_end|system)\s*\|?>`),
+	regexp.MustCompile(`忽略(之前|以上|上面|前面|先前)的?(所有)?(指令|指示|提示|规则|设定)`),
+	regexp.MustCompile(`(输出|显示|告诉我|重复)(你的)?(系统提示词?|初始指令)`),
+}
+
+// Injection finds phrases that try to override the instructions of the agent. It is a
+// heuristic, it catches the common phrasings in english and chinese, not every attack.
+func Injection() Check {
+	return &regexpCheck{name: "injection", kind: "prompt_injection", patterns: injectionPatterns}
+}
+
+// Topics finds the topics as case-insensitive substrings.
+func Topics(topics ...string) Check {
+	c := &regexpCheck{name: "topic", kind: "blocked_topic"}
+	for _, topic := range topics {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			c.patterns = append(c.patterns, regexp.MustCompile(`(?i)`+regexp.QuoteMeta(topic)))
+		}
+	}
+	return c
+}
+
+type piiCheck struct{}
+
+// PII finds emails, phone numbers, bank card numbers that pass the Luhn check and chinese
+// resident id numbers with a valid check digit.
+func PII() Check {
+	return piiCheck{}
+}
+
+func (piiCheck) Name() string {
+	return "pii"
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// numberPattern is a run of digits with the separators people write in phone and card
+	// numbers, an id number may end with X.
+	numberPattern = regexp.MustCompile(`\+?\d[\d \-]{7,}[\dXx]`)
+)
+
+func (piiCheck) Find(text string) []Match {
+	var matches []Match
+	for _, loc := range emailPattern.FindAllStringIndex(text, -1) {
+		matches = append(matches, Match{Start: loc[0], End: loc[1], Kind: "email"})
+	}
+	for _, loc := range numberPattern.FindAllStringIndex(text, -1) {
+		// a longer number is not a phone or a card number
+		if loc[0] > 0 && isDigit(text[loc[0]-1]) || loc[1] < len(text) && isDigit(text[loc[1]]) {
+			continue
+		}
+		if kind := classifyNumber(text[loc[0]:loc[1]]); kind != "" {
+			matches = append(matches, Match{Start: loc[0], End: loc[1], Kind: kind})
+			continue
+		}
+		// several numbers separated by spaces, e.g. a phone number followed by a card number
+		start := loc[0]
+		for _, field := range strings.Split(text[loc[0]:loc[1]], " ") {
+			if kind := classifyNumber(field); kind != "" {
+				matches = append(matches, Match{Start: start, End: start + len(field), Kind: kind})
+			}
+			start += len(field) + 1
+		}
+	}
+	return matches
+}
+
+func classifyNumber(s string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '+' {
+			return -1
+		}
+		return r
+	}, s)
+	switch {
+	case isMobile(digits):
+		return "phone"
+	case strings.HasPrefix(s, "+") && len(digits) >= 8 && len(digits) <= 15 && isDigits(digits):
+		return "phone"
+	case isIDNumber(digits):
+		return "id_number"
+	case len(digits) >= 13 && len(digits) <= 19 && isDigits(digits) && luhn(digits):
+		return "card"
+	}
+	return ""
+}
+
+// isMobile reports a chinese mobile number, with or without the country code 86.
+func isMobile(digits string) bool {
+	digits = strings.TrimPrefix(digits, "86")
+	return len(digits) == 11 && digits[0] == '1' && digits[1] >= '3' && isDigits(digits)
+}
+
+var (
+	idWeights    = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCheckCodes = "10X98765432"
+)
+
+// isIDNumber checks the length and the check digit of an 18 digit resident id number.
+func isIDNumber(s string) bool {
+	if len(s) != 18 || !isDigits(s[:17]) {
+		return false
+	}
+	sum := 0
+	for i, w := range idWeights {
+		sum += int(s[i]-'0') * w
+	}
+	return strings.ToUpper(s[17:]) == string(idCheckCodes[sum%11])
+}
+
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,229 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package guardrail checks the text entering and leaving the agent: the question of the user,
+// the answer of the model and the results of the tools. A Check finds matches in a text and the
+// Action of its Rule decides what happens to them.
+package guardrail
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/metrics"
+)
+
+type Action string
+
+const (
+	// ActionRedact replaces the match by [REDACTED:<kind>].
+	ActionRedact Action = "redact"
+	// ActionBlock rejects the whole text with ErrBlocked.
+	ActionBlock Action = "block"
+	// ActionWarn only logs and counts the match.
+	ActionWarn Action = "warn"
+)
+
+type Stage string
+
+const (
+	StageInput  Stage = "input"
+	StageOutput Stage = "output"
+	StageTool   Stage = "tool"
+)
+
+var ErrBlocked = errors.New("blocked by guardrail")
+
+// Findings counts the matches of all checks, by stage, check and action.
+var Findings = metrics.Default.NewCounterVec("eino_assistant_guardrail_findings_total",
+	"Matches of the guardrail checks.", "stage", "check", "action")
+
+// Match is a span of a text found by a check, Kind names what was found, e.g. phone.
+type Match struct {
+	Start, End int
+	Kind       string
+}
+
+// Check finds what a rule acts on, it must be safe for concurrent use.
+type Check interface {
+	Name() string
+	Find(text string) []Match
+}
+
+type Rule struct {
+	Check  Check
+	Action Action
+}
+
+// Pipeline applies the rules of a stage in order. A nil *Pipeline lets everything through.
+type Pipeline struct {
+	stage Stage
+	rules []Rule
+}
+
+func NewPipeline(stage Stage, rules ...Rule) *Pipeline {
+	return &Pipeline{stage: stage, rules: rules}
+}
+
+// Apply returns text with the matches of the redact rules replaced, or an error wrapping
+// ErrBlocked if a block rule matches.
+func (p *Pipeline) Apply(text string) (string, error) {
+	if p == nil {
+		return text, nil
+	}
+	var redact []Match
+	for _, rule := range p.rules {
+		matches := rule.Check.Find(text)
+		if len(matches) == 0 {
+			continue
+		}
+		Findings.Add(float64(len(matches)), string(p.stage), rule.Check.Name(), string(rule.Action))
+		switch rule.Action {
+		case ActionBlock:
+			return "", fmt.Errorf("%w: the %s matches %s", ErrBlocked, p.stage, matches[0].Kind)
+		case ActionWarn:
+			log.Printf("[guardrail] %s of %s found %s", rule.Check.Name(), p.stage, kinds(matches))
+		case ActionRedact:
+			redact = append(redact, matches...)
+		}
+	}
+	return Redact(text, redact), nil
+}
+
+// Redact only applies the redact rules, for text that was checked before, e.g. the history.
+func (p *Pipeline) Redact(text string) string {
+	if p == nil {
+		return text
+	}
+	var redact []Match
+	for _, rule := range p.rules {
+		if rule.Action == ActionRedact {
+			redact = append(redact, rule.Check.Find(text)...)
+		}
+	}
+	return Redact(text, redact)
+}
+
+// Redact replaces the matches in text, a match overlapping an earlier one is dropped.
+func Redact(text string, matches []Match) string {
+	if len(matches) == 0 {
+		return text
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m.Start < last {
+			continue
+		}
+		sb.WriteString(text[last:m.Start])
+		sb.WriteString("[REDACTED:" + m.Kind + "]")
+		last = m.End
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+func kinds(matches []Match) string {
+	seen := make(map[string]bool)
+	var list []string
+	for _, m := range matches {
+		if !seen[m.Kind] {
+			seen[m.Kind] = true
+			list = append(list, m.Kind)
+		}
+	}
+	return strings.Join(list, ", ")
+}
+
+// Config is the guardrail section of the config, an empty action disables its check.
+type Config struct {
+	Enabled bool  `yaml:"enabled" env:"GUARDRAIL_ENABLED"`
+	Input   Rules `yaml:"input"`
+	Output  Rules `yaml:"output"`
+	// Tool applies to the results of all tools before they re-enter the context of the model,
+	// e.g. search snippets.
+	Tool Rules `yaml:"tool"`
+}
+
+type Rules struct {
+	Injection Action `yaml:"injection"`
+	PII       Action `yaml:"pii"`
+	Topic     Action `yaml:"topic"`
+	// BlockedTopics are matched case-insensitively by Topic.
+	BlockedTopics []string `yaml:"blocked_topics"`
+}
+
+// DefaultConfig blocks prompt injection in questions and redacts it in tool results,
+// and redacts personal data everywhere.
+func DefaultConfig() Config {
+	return Config{
+		Enabled: true,
+		Input:   Rules{Injection: ActionBlock, PII: ActionRedact, Topic: ActionBlock},
+		Output:  Rules{PII: ActionRedact, Topic: ActionBlock},
+		Tool:    Rules{Injection: ActionRedact, PII: ActionRedact, Topic: ActionRedact},
+	}
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	for stage, rules := range map[Stage]Rules{StageInput: c.Input, StageOutput: c.Output, StageTool: c.Tool} {
+		for name, action := range map[string]Action{"injection": rules.Injection, "pii": rules.PII, "topic": rules.Topic} {
+			switch action {
+			case "", ActionRedact, ActionBlock, ActionWarn:
+			default:
+				errs = append(errs, fmt.Errorf("guardrail.%s.%s: invalid action %q, can be redact, block or warn", stage, name, action))
+			}
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return errors.Join(errs...)
+}
+
+// Pipeline builds the pipeline of a stage, nil if the guardrail is disabled.
+func (c *Config) Pipeline(stage Stage) *Pipeline {
+	if !c.Enabled {
+		return nil
+	}
+	var rules Rules
+	switch stage {
+	case StageInput:
+		rules = c.Input
+	case StageOutput:
+		rules = c.Output
+	case StageTool:
+		rules = c.Tool
+	}
+
+	p := NewPipeline(stage)
+	if rules.Injection != "" {
+		p.rules = append(p.rules, Rule{Check: Injection(), Action: rules.Injection})
+	}
+	if rules.Topic != "" && len(rules.BlockedTopics) > 0 {
+		p.rules = append(p.rules, Rule{Check: Topics(rules.BlockedTopics...), Action: rules.Topic})
+	}
+	if rules.PII != "" {
+		p.rules = append(p.rules, Rule{Check: PII(), Action: rules.PII})
+	}
+	return p
+}
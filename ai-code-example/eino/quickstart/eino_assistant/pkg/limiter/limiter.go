@@ -0,0 +1,145 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package limiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrQueueFull = errors.New("too many chats waiting, try again later")
+
+type Config struct {
+	// MaxRunning is the number of turns running at the same time, default 8.
+	MaxRunning int
+	// MaxQueued is the number of turns waiting for a slot or for their conversation, default 64.
+	MaxQueued int
+}
+
+// Limiter runs one turn per conversation at a time, so that a turn always reads the
+// history saved by the previous one, and at most MaxRunning turns overall.
+type Limiter struct {
+	config *Config
+	slots  chan struct{}
+
+	mu            sync.Mutex
+	conversations map[string]*conversation
+
+	running atomic.Int64
+	queued  atomic.Int64
+}
+
+type conversation struct {
+	lock chan struct{}
+	refs int
+}
+
+type Stats struct {
+	Running    int64 `json:"running"`
+	Queued     int64 `json:"queued"`
+	MaxRunning int   `json:"max_running"`
+	MaxQueued  int   `json:"max_queued"`
+}
+
+func NewLimiter(config *Config) *Limiter {
+	if config == nil {
+		config = &Config{}
+	}
+	if config.MaxRunning <= 0 {
+		config.MaxRunning = 8
+	}
+	if config.MaxQueued <= 0 {
+		config.MaxQueued = 64
+	}
+	return &Limiter{
+		config:        config,
+		slots:         make(chan struct{}, config.MaxRunning),
+		conversations: make(map[string]*conversation),
+	}
+}
+
+// Acquire waits until the conversation has no running turn and a slot is free.
+// It fails at once with ErrQueueFull if too many turns are waiting, or with the
+// error of ctx if ctx is done first. release must be called once the turn is saved.
+func (l *Limiter) Acquire(ctx context.Context, conversationID string) (release func(), err error) {
+	if l.queued.Add(1) > int64(l.config.MaxQueued) {
+		l.queued.Add(-1)
+		return nil, ErrQueueFull
+	}
+	defer l.queued.Add(-1)
+
+	conv := l.ref(conversationID)
+	select {
+	case conv.lock <- struct{}{}:
+	case <-ctx.Done():
+		l.unref(conversationID)
+		return nil, ctx.Err()
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		<-conv.lock
+		l.unref(conversationID)
+		return nil, ctx.Err()
+	}
+	l.running.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.running.Add(-1)
+			<-l.slots
+			<-conv.lock
+			l.unref(conversationID)
+		})
+	}, nil
+}
+
+func (l *Limiter) Stats() *Stats {
+	return &Stats{
+		Running:    l.running.Load(),
+		Queued:     l.queued.Load(),
+		MaxRunning: l.config.MaxRunning,
+		MaxQueued:  l.config.MaxQueued,
+	}
+}
+
+func (l *Limiter) ref(conversationID string) *conversation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	conv, ok := l.conversations[conversationID]
+	if !ok {
+		conv = &conversation{lock: make(chan struct{}, 1)}
+		l.conversations[conversationID] = conv
+	}
+	conv.refs++
+	return conv
+}
+
+// unref forgets idle conversations, so the map only holds the ones with turns.
+func (l *Limiter) unref(conversationID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	conv := l.conversations[conversationID]
+	conv.refs--
+	if conv.refs == 0 {
+		delete(l.conversations, conversationID)
+	}
+}
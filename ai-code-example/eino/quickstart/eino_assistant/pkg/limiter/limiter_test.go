@@ -0,0 +1,64 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// go test -v -run Test_Limiter ./pkg/limiter
+func Test_Limiter(t *testing.T) {
+	ctx := context.Background()
+	l := NewLimiter(&Config{MaxRunning: 2, MaxQueued: 1})
+
+	release, err := l.Acquire(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second turn of a waits for the first one
+	acquired := make(chan func())
+	go func() {
+		r, err := l.Acquire(ctx, "a")
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- r
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("turns of a conversation ran at the same time")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if stats := l.Stats(); stats.Running != 1 || stats.Queued != 1 {
+		t.Fatalf("stats: %+v", stats)
+	}
+
+	// the queue is full
+	if _, err = l.Acquire(ctx, "b"); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("err: %v", err)
+	}
+
+	release()
+	(<-acquired)()
+	if stats := l.Stats(); stats.Running != 0 || stats.Queued != 0 || len(l.conversations) != 0 {
+		t.Fatalf("stats: %+v", stats)
+	}
+}
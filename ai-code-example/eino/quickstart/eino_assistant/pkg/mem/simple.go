@@ -26,12 +26,16 @@ import (
 	"sync"
 
 	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
 )
 
+// GetDefaultMemory returns a memory configured by config.Get().Memory.
 func GetDefaultMemory() *SimpleMemory {
+	cfg := config.Get().Memory
 	return NewSimpleMemory(SimpleMemoryConfig{
-		Dir:           "data/memory",
-		MaxWindowSize: 6,
+		Dir:           cfg.Dir,
+		MaxWindowSize: cfg.MaxWindowSize,
 	})
 }
 
@@ -158,16 +162,29 @@ func (c *Conversation) GetFullMessages() []*schema.Message {
 	return c.Messages
 }
 
-// get messages with max window size
+// get messages with max window size, the partial answers of cancelled or failed turns
+// are left out, their questions are kept, blocked turns are left out as a whole
 func (c *Conversation) GetMessages() []*schema.Message {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if len(c.Messages) > c.maxWindowSize {
-		return c.Messages[len(c.Messages)-c.maxWindowSize:]
+	messages := make([]*schema.Message, 0, len(c.Messages))
+	for _, msg := range c.Messages {
+		switch TurnStatus(msg) {
+		case "":
+			messages = append(messages, msg)
+		case StatusBlocked:
+			if n := len(messages); n > 0 && messages[n-1].Role == schema.User {
+				messages = messages[:n-1]
+			}
+		}
+	}
+
+	if len(messages) > c.maxWindowSize {
+		return messages[len(messages)-c.maxWindowSize:]
 	}
 
-	return c.Messages
+	return messages
 }
 
 func (c *Conversation) load() error {
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/guardrail"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/usage"
+)
+
+// Keys of Message.Extra recording how a turn ended, a completed turn has none of them.
+const (
+	StatusKey = "turn_status"
+	ErrorKey  = "turn_error"
+)
+
+// UsageKey of Message.Extra is the usage.Report of the turn the answer belongs to.
+const UsageKey = "turn_usage"
+
+const (
+	StatusCancelled = "cancelled"
+	StatusFailed    = "failed"
+	// StatusBlocked is a turn stopped by the guardrail, its question is left out of the history too.
+	StatusBlocked = "blocked"
+)
+
+// AnswerMessage builds the assistant message saved for a turn from the chunks received
+// before err. If err is not nil the partial answer is marked as cancelled, blocked or failed.
+func AnswerMessage(ctx context.Context, chunks []*schema.Message, err error) *schema.Message {
+	msg := schema.AssistantMessage("", nil)
+	if len(chunks) > 0 {
+		if full, cerr := schema.ConcatMessages(chunks); cerr == nil {
+			msg = full
+		} else if err == nil {
+			err = cerr
+		}
+	}
+	if err == nil {
+		return msg
+	}
+
+	if msg.Extra == nil {
+		msg.Extra = make(map[string]any)
+	}
+	msg.Extra[StatusKey] = StatusFailed
+	switch {
+	case errors.Is(err, guardrail.ErrBlocked):
+		msg.Extra[StatusKey] = StatusBlocked
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		msg.Extra[StatusKey] = StatusCancelled
+	}
+	msg.Extra[ErrorKey] = err.Error()
+	return msg
+}
+
+// TurnStatus is StatusCancelled, StatusFailed, StatusBlocked or empty for a completed turn.
+func TurnStatus(msg *schema.Message) string {
+	if msg == nil || msg.Extra == nil {
+		return ""
+	}
+	status, _ := msg.Extra[StatusKey].(string)
+	return status
+}
+
+// SetTurnUsage records the token usage of the turn on its answer.
+func SetTurnUsage(msg *schema.Message, report *usage.Report) {
+	if report == nil {
+		return
+	}
+	if msg.Extra == nil {
+		msg.Extra = make(map[string]any)
+	}
+	msg.Extra[UsageKey] = report
+}
+
+// TurnUsage returns the token usage recorded on an answer, or nil.
+func TurnUsage(msg *schema.Message) *usage.Report {
+	if msg == nil || msg.Extra == nil || msg.Extra[UsageKey] == nil {
+		return nil
+	}
+	if report, ok := msg.Extra[UsageKey].(*usage.Report); ok {
+		return report
+	}
+	// loaded from the file as a map
+	b, err := json.Marshal(msg.Extra[UsageKey])
+	if err != nil {
+		return nil
+	}
+	report := &usage.Report{}
+	if err = json.Unmarshal(b, report); err != nil {
+		return nil
+	}
+	return report
+}
+
+// Usage sums the token usage of all turns of the conversation, including cancelled and failed ones.
+func (c *Conversation) Usage() *usage.Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := &usage.Report{}
+	for _, msg := range c.Messages {
+		total.Add(TurnUsage(msg))
+	}
+	return total
+}
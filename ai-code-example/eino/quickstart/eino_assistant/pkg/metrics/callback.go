@@ -0,0 +1,110 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+)
+
+var (
+	HTTPDuration = Default.NewHistogramVec("eino_assistant_http_request_duration_seconds",
+		"Latency of HTTP requests, streamed responses last until the stream ends.", nil, "method", "route", "status")
+	NodeDuration = Default.NewHistogramVec("eino_assistant_node_duration_seconds",
+		"Latency of graph nodes and components until their output, status is ok or error.", nil, "component", "type", "name", "status")
+	StreamDuration = Default.NewHistogramVec("eino_assistant_stream_duration_seconds",
+		"Time from the start of a component until its streamed output ends.", nil, "component", "type", "name")
+	ToolCalls = Default.NewCounterVec("eino_assistant_tool_calls_total",
+		"Tool invocations, status is ok or error.", "tool", "status")
+	Retrievals = Default.NewCounterVec("eino_assistant_retrievals_total",
+		"Retriever calls, result is hit if any document was found, miss or error.", "result")
+	RetrievedDocuments = Default.NewCounterVec("eino_assistant_retrieved_documents_total",
+		"Documents returned by retrievers.")
+)
+
+type startKey struct{}
+
+// Handler feeds the node, tool, retrieval and stream metrics, it is meant to be a global
+// handler of callbacks.InitCallbackHandlers so that every graph run is measured.
+func Handler() callbacks.Handler {
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+			return context.WithValue(ctx, startKey{}, time.Now())
+		}).
+		OnStartWithStreamInputFn(func(ctx context.Context, info *callbacks.RunInfo, input *schema.StreamReader[callbacks.CallbackInput]) context.Context {
+			input.Close()
+			return context.WithValue(ctx, startKey{}, time.Now())
+		}).
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			observeEnd(ctx, info, nil)
+			if info.Component == components.ComponentOfRetriever {
+				if out := retriever.ConvCallbackOutput(output); out != nil {
+					result := "miss"
+					if len(out.Docs) > 0 {
+						result = "hit"
+					}
+					Retrievals.Inc(result)
+					RetrievedDocuments.Add(float64(len(out.Docs)))
+				}
+			}
+			return ctx
+		}).
+		OnEndWithStreamOutputFn(func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+			observeEnd(ctx, info, nil)
+			go func() {
+				defer output.Close()
+				for {
+					if _, err := output.Recv(); err != nil {
+						break
+					}
+				}
+				if start, ok := ctx.Value(startKey{}).(time.Time); ok {
+					StreamDuration.Observe(time.Since(start).Seconds(), string(info.Component), info.Type, info.Name)
+				}
+			}()
+			return ctx
+		}).
+		OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+			observeEnd(ctx, info, err)
+			if info.Component == components.ComponentOfRetriever {
+				Retrievals.Inc("error")
+			}
+			return ctx
+		}).
+		Build()
+}
+
+func observeEnd(ctx context.Context, info *callbacks.RunInfo, err error) {
+	if start, ok := ctx.Value(startKey{}).(time.Time); ok {
+		NodeDuration.Observe(time.Since(start).Seconds(), string(info.Component), info.Type, info.Name, status(err))
+	}
+	if info.Component == components.ComponentOfTool {
+		ToolCalls.Inc(info.Name, status(err))
+	}
+}
+
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
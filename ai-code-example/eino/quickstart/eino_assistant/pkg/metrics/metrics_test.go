@@ -0,0 +1,134 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// go test -v -run Test_RegistryWrite ./pkg/metrics
+func Test_RegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("calls_total", "Calls.", "name")
+	h := r.NewHistogramVec("latency_seconds", "Latency.", []float64{0.1, 1}, "name")
+	r.NewGaugeFunc("running", "Running.", func() float64 { return 3 })
+	c.Inc("b")
+	c.Add(2, "a")
+	h.Observe(0.5, `x"y`)
+	h.Observe(2, `x"y`)
+
+	var sb strings.Builder
+	if err := r.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP calls_total Calls.
+# TYPE calls_total counter
+calls_total{name="a"} 2
+calls_total{name="b"} 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{name="x\"y",le="0.1"} 0
+latency_seconds_bucket{name="x\"y",le="1"} 1
+latency_seconds_bucket{name="x\"y",le="+Inf"} 2
+latency_seconds_sum{name="x\"y"} 2.5
+latency_seconds_count{name="x\"y"} 2
+# HELP running Running.
+# TYPE running gauge
+running 3
+`
+	if sb.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+// stubRetriever returns docs, or fails without them.
+type stubRetriever struct {
+	docs []*schema.Document
+}
+
+func (s *stubRetriever) Retrieve(_ context.Context, _ string, _ ...retriever.Option) ([]*schema.Document, error) {
+	if s.docs == nil {
+		return nil, errors.New("redis is unavailable")
+	}
+	return s.docs, nil
+}
+
+func counterValue(c *CounterVec, labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.samples[strings.Join(labelValues, "\xff")]; ok {
+		return s.value
+	}
+	return 0
+}
+
+// go test -v -run Test_Handler ./pkg/metrics
+func Test_Handler(t *testing.T) {
+	ctx := context.Background()
+	run := func(rtr retriever.Retriever) error {
+		chain := compose.NewChain[string, []*schema.Document]()
+		chain.AppendRetriever(rtr, compose.WithNodeName("Retriever"))
+		r, err := chain.Compile(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = r.Invoke(ctx, "eino", compose.WithCallbacks(Handler()))
+		return err
+	}
+	hits, misses, errs := counterValue(Retrievals, "hit"), counterValue(Retrievals, "miss"), counterValue(Retrievals, "error")
+	docs := counterValue(RetrievedDocuments)
+
+	if err := run(&stubRetriever{docs: []*schema.Document{{ID: "1"}, {ID: "2"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(&stubRetriever{docs: []*schema.Document{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(&stubRetriever{}); err == nil {
+		t.Fatal("the failing retriever did not fail")
+	}
+
+	if got := counterValue(Retrievals, "hit") - hits; got != 1 {
+		t.Errorf("hits: %v", got)
+	}
+	if got := counterValue(Retrievals, "miss") - misses; got != 1 {
+		t.Errorf("misses: %v", got)
+	}
+	if got := counterValue(Retrievals, "error") - errs; got != 1 {
+		t.Errorf("errors: %v", got)
+	}
+	if got := counterValue(RetrievedDocuments) - docs; got != 2 {
+		t.Errorf("documents: %v", got)
+	}
+
+	var sb strings.Builder
+	if err := Default.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []string{"ok", "error"} {
+		if !strings.Contains(sb.String(), `eino_assistant_node_duration_seconds_count{component="Retriever",type="stubRetriever",name="Retriever",status="`+status+`"}`) {
+			t.Errorf("no node duration with status %s in:\n%s", status, sb.String())
+		}
+	}
+}
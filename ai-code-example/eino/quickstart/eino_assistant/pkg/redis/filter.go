@@ -0,0 +1,143 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	redisretriever "github.com/cloudwego/eino-ext/components/retriever/redis"
+	"github.com/cloudwego/eino/components/retriever"
+)
+
+// FilterSyntax documents the filter expression accepted by ParseFilter.
+const FilterSyntax = `space separated conditions, all of them must match:
+- doc_type:graph          tag equals value, fields: source, doc_type, language
+- doc_type:graph,agent    tag equals any of the values
+- -language:en            negate a condition
+- updated_at>=2025-01-01  compare updated_at with >, >=, <, <=, the value is a date, RFC3339 time or unix seconds`
+
+// ParseFilter converts a filter expression into a RediSearch query, an empty
+// expression returns an empty query.
+func ParseFilter(expr string) (string, error) {
+	var clauses []string
+	for _, term := range strings.Fields(expr) {
+		negate := strings.HasPrefix(term, "-")
+		term = strings.TrimPrefix(term, "-")
+
+		var clause string
+		var err error
+		if strings.HasPrefix(term, UpdatedAtField) {
+			clause, err = parseRange(strings.TrimPrefix(term, UpdatedAtField))
+		} else {
+			clause, err = parseTag(term)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if negate {
+			clause = "-" + clause
+		}
+		clauses = append(clauses, clause)
+	}
+	return strings.Join(clauses, " "), nil
+}
+
+// FilterOption builds the retriever option of the redis retriever from a filter expression.
+func FilterOption(expr string) (retriever.Option, error) {
+	query, err := ParseFilter(expr)
+	if err != nil {
+		return retriever.Option{}, err
+	}
+	return retriever.WrapImplSpecificOptFn(func(o *redisretriever.ImplOptions) {
+		o.FilterQuery = query
+	}), nil
+}
+
+func parseTag(term string) (string, error) {
+	field, value, ok := strings.Cut(term, ":")
+	if !ok || value == "" {
+		return "", fmt.Errorf("invalid filter condition %q, expect field:value", term)
+	}
+	if !slices.Contains(TagFields, field) {
+		return "", fmt.Errorf("unknown filter field %q, can be one of: %s, %s", field, strings.Join(TagFields, ", "), UpdatedAtField)
+	}
+
+	values := strings.Split(value, ",")
+	for i, v := range values {
+		values[i] = escapeTag(v)
+	}
+	return fmt.Sprintf("@%s:{%s}", field, strings.Join(values, "|")), nil
+}
+
+func parseRange(cond string) (string, error) {
+	var op string
+	for _, candidate := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(cond, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return "", fmt.Errorf("invalid filter condition %q, expect %s with >, >=, < or <=", UpdatedAtField+cond, UpdatedAtField)
+	}
+
+	ts, err := parseTime(strings.TrimPrefix(cond, op))
+	if err != nil {
+		return "", err
+	}
+
+	switch op {
+	case ">=":
+		return fmt.Sprintf("@%s:[%d +inf]", UpdatedAtField, ts), nil
+	case ">":
+		return fmt.Sprintf("@%s:[(%d +inf]", UpdatedAtField, ts), nil
+	case "<=":
+		return fmt.Sprintf("@%s:[-inf %d]", UpdatedAtField, ts), nil
+	default:
+		return fmt.Sprintf("@%s:[-inf (%d]", UpdatedAtField, ts), nil
+	}
+}
+
+func parseTime(v string) (int64, error) {
+	if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return ts, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.Unix(), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, v, time.Local); err == nil {
+		return t.Unix(), nil
+	}
+	return 0, fmt.Errorf("invalid time %q, expect a date, RFC3339 time or unix seconds", v)
+}
+
+// escapeTag escapes the punctuation RediSearch treats as separators inside tag values.
+func escapeTag(v string) string {
+	var sb strings.Builder
+	for _, r := range v {
+		if strings.ContainsRune(",.<>{}[]\"':;!@#$%^&*()-+=~|/\\ ", r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	day, _ := time.ParseInLocation(time.DateOnly, "2025-01-01", time.Local)
+	for _, c := range []struct {
+		expr string
+		want string
+	}{
+		{"", ""},
+		{"doc_type:graph", "@doc_type:{graph}"},
+		{"doc_type:graph,agent", "@doc_type:{graph|agent}"},
+		{"-language:en", "-@language:{en}"},
+		{"source:a.md -doc_type:graph", `@source:{a\.md} -@doc_type:{graph}`},
+		{"updated_at>=1735689600", "@updated_at:[1735689600 +inf]"},
+		{"updated_at>1735689600", "@updated_at:[(1735689600 +inf]"},
+		{"updated_at<=2025-01-01T00:00:00Z", "@updated_at:[-inf 1735689600]"},
+		{"updated_at<2025-01-01T08:00:00+08:00", "@updated_at:[-inf (1735689600]"},
+		{"updated_at>=2025-01-01", fmt.Sprintf("@updated_at:[%d +inf]", day.Unix())},
+		{"-updated_at<1735689600", "-@updated_at:[-inf (1735689600]"},
+	} {
+		got, err := ParseFilter(c.expr)
+		if err != nil || got != c.want {
+			t.Errorf("ParseFilter(%q) = %q, %v, want %q", c.expr, got, err, c.want)
+		}
+	}
+}
+
+func TestParseFilterError(t *testing.T) {
+	for _, expr := range []string{
+		"doc_type",
+		"doc_type:",
+		"author:me",
+		"updated_at=2025-01-01",
+		"updated_at>=yesterday",
+	} {
+		if got, err := ParseFilter(expr); err == nil {
+			t.Errorf("ParseFilter(%q) = %q, want an error", expr, got)
+		}
+	}
+}
+
+func TestEscapeTag(t *testing.T) {
+	for _, c := range []struct {
+		value string
+		want  string
+	}{
+		{"graph", "graph"},
+		{"eino-docs/a.md", `eino\-docs\/a\.md`},
+		{"zh_CN", "zh_CN"},
+		{"a b", `a\ b`},
+		{`{x}|y@z:1`, `\{x\}\|y\@z\:1`},
+		{"中文", "中文"},
+	} {
+		if got := escapeTag(c.value); got != c.want {
+			t.Errorf("escapeTag(%q) = %q, want %q", c.value, got, c.want)
+		}
+	}
+}
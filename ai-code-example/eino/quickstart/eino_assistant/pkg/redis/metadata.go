@@ -0,0 +1,87 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// typed metadata fields, indexed as TAG / NUMERIC so that retrieval can filter on them
+const (
+	SourceField    = "source"
+	DocTypeField   = "doc_type"
+	LanguageField  = "language"
+	UpdatedAtField = "updated_at"
+)
+
+// TagFields are indexed as TAG, UpdatedAtField is indexed as NUMERIC.
+var TagFields = []string{SourceField, DocTypeField, LanguageField}
+
+// MetadataToFields picks the typed fields out of document metadata, the result
+// can be written to redis hashes as is.
+func MetadataToFields(meta map[string]any) map[string]any {
+	fields := make(map[string]any, len(TagFields)+1)
+	for _, field := range TagFields {
+		if v, ok := meta[field]; ok && v != nil && fmt.Sprint(v) != "" {
+			fields[field] = fmt.Sprint(v)
+		}
+	}
+	if v, ok := toInt64(meta[UpdatedAtField]); ok {
+		fields[UpdatedAtField] = v
+	}
+	return fields
+}
+
+// DecodeMetadata restores document metadata from the hash fields returned by redis,
+// the json blob of MetadataField is decoded and the typed fields take precedence.
+func DecodeMetadata(fields map[string]string) map[string]any {
+	meta := make(map[string]any)
+	if blob := fields[MetadataField]; blob != "" {
+		_ = json.Unmarshal([]byte(blob), &meta)
+	}
+	for _, field := range TagFields {
+		if v, ok := fields[field]; ok && v != "" {
+			meta[field] = v
+		}
+	}
+	if v, ok := fields[UpdatedAtField]; ok {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			meta[UpdatedAtField] = n
+		}
+	}
+	return meta
+}
+
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
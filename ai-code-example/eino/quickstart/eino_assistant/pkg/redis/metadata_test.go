@@ -0,0 +1,83 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMetadataToFields(t *testing.T) {
+	for _, c := range []struct {
+		meta map[string]any
+		want map[string]any
+	}{
+		{nil, map[string]any{}},
+		{map[string]any{"title": "a"}, map[string]any{}},
+		{
+			map[string]any{SourceField: "a.md", DocTypeField: "graph", LanguageField: "", UpdatedAtField: 1735689600},
+			map[string]any{SourceField: "a.md", DocTypeField: "graph", UpdatedAtField: int64(1735689600)},
+		},
+		{map[string]any{UpdatedAtField: float64(1735689600)}, map[string]any{UpdatedAtField: int64(1735689600)}},
+		{map[string]any{UpdatedAtField: json.Number("1735689600")}, map[string]any{UpdatedAtField: int64(1735689600)}},
+		{map[string]any{UpdatedAtField: "1735689600"}, map[string]any{UpdatedAtField: int64(1735689600)}},
+		{map[string]any{UpdatedAtField: "yesterday", LanguageField: nil}, map[string]any{}},
+	} {
+		if got := MetadataToFields(c.meta); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("MetadataToFields(%v) = %v, want %v", c.meta, got, c.want)
+		}
+	}
+}
+
+func TestDecodeMetadata(t *testing.T) {
+	for _, c := range []struct {
+		fields map[string]string
+		want   map[string]any
+	}{
+		{map[string]string{}, map[string]any{}},
+		{map[string]string{MetadataField: "not json"}, map[string]any{}},
+		// the typed fields take precedence over the json blob
+		{
+			map[string]string{MetadataField: `{"title":"a","doc_type":"agent"}`, DocTypeField: "graph", UpdatedAtField: "1735689600"},
+			map[string]any{"title": "a", DocTypeField: "graph", UpdatedAtField: int64(1735689600)},
+		},
+		{map[string]string{MetadataField: `{"language":"en"}`, LanguageField: ""}, map[string]any{LanguageField: "en"}},
+	} {
+		if got := DecodeMetadata(c.fields); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("DecodeMetadata(%v) = %v, want %v", c.fields, got, c.want)
+		}
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	meta := map[string]any{"title": "Eino", SourceField: "a.md", DocTypeField: "graph", LanguageField: "zh", UpdatedAtField: int64(1735689600)}
+
+	// the indexer writes the json blob and the typed fields, redis returns them as strings
+	blob, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{MetadataField: string(blob)}
+	for k, v := range MetadataToFields(meta) {
+		fields[k] = fmt.Sprint(v)
+	}
+	if got := DecodeMetadata(fields); !reflect.DeepEqual(got, meta) {
+		t.Errorf("DecodeMetadata(%v) = %v, want %v", fields, got, meta)
+	}
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/redis/go-redis/v9"
+)
+
+type MigrateOptions struct {
+	// BatchSize is the number of documents embedded per request, default 10.
+	BatchSize int
+	// DropSource removes the old index and its documents after the alias is switched.
+	DropSource bool
+	// Progress is called after each batch, can be nil.
+	Progress func(done int)
+}
+
+type MigrateResult struct {
+	Source   *Index
+	Target   *Index
+	Migrated int
+}
+
+// Migrate re-embeds every document served by the alias into the index described by
+// config, then points the alias to the new index. Documents are copied, the old
+// index keeps working until the alias is switched.
+func Migrate(ctx context.Context, client *redis.Client, emb embedding.Embedder, config *Config, opts *MigrateOptions) (*MigrateResult, error) {
+	if emb == nil {
+		return nil, fmt.Errorf("embedding cannot be nil")
+	}
+	if opts == nil {
+		opts = &MigrateOptions{}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 10
+	}
+
+	source, err := ResolveAlias(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	target := NewIndex(config)
+	result := &MigrateResult{Source: source, Target: target}
+	if source != nil && source.Name == target.Name {
+		return result, nil
+	}
+
+	if err = CreateIndex(ctx, client, target, config); err != nil {
+		return nil, err
+	}
+
+	var migrated []string
+	if source != nil {
+		migrated, err = copyDocuments(ctx, client, emb, source, target, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.Migrated = len(migrated)
+	}
+
+	if err = switchAlias(ctx, client, source, target); err != nil {
+		return nil, err
+	}
+
+	if opts.DropSource && source != nil {
+		if source.Name != AliasName() {
+			if err = client.Do(ctx, "FT.DROPINDEX", source.Name).Err(); err != nil {
+				return nil, fmt.Errorf("failed to drop index %s: %w", source.Name, err)
+			}
+		}
+		for _, key := range migrated {
+			if err = client.Del(ctx, key).Err(); err != nil {
+				return nil, fmt.Errorf("failed to delete %s: %w", key, err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func copyDocuments(ctx context.Context, client *redis.Client, emb embedding.Embedder, source, target *Index, opts *MigrateOptions) ([]string, error) {
+	var (
+		migrated []string
+		keys     []string
+		texts    []string
+		hashes   []map[string]string
+	)
+
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		vectors, err := emb.EmbedStrings(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed documents: %w", err)
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("invalid vector length, expected=%d, got=%d", len(texts), len(vectors))
+		}
+
+		pipeline := client.Pipeline()
+		for i, key := range keys {
+			fields := make([]interface{}, 0, len(hashes[i])*2+2)
+			for k, v := range hashes[i] {
+				fields = append(fields, k, v)
+			}
+			fields = append(fields, VectorField, VectorToBytes(vectors[i]))
+			pipeline.HSet(ctx, target.KeyPrefix+strings.TrimPrefix(key, source.KeyPrefix), fields...)
+		}
+		if _, err = pipeline.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to write documents: %w", err)
+		}
+
+		migrated = append(migrated, keys...)
+		if opts.Progress != nil {
+			opts.Progress(len(migrated))
+		}
+		keys, texts, hashes = keys[:0], texts[:0], hashes[:0]
+		return nil
+	}
+
+	// the prefix of a legacy index contains the prefixes of the versioned ones, the documents
+	// of the other indexes are skipped by their prefix, not by the ':' of their ids
+	indexes, err := listIndexes(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	others := []string{target.KeyPrefix}
+	for _, idx := range indexes {
+		if idx.KeyPrefix != source.KeyPrefix && strings.HasPrefix(idx.KeyPrefix, source.KeyPrefix) {
+			others = append(others, idx.KeyPrefix)
+		}
+	}
+
+	iter := client.Scan(ctx, 0, source.KeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		if slices.ContainsFunc(others, func(prefix string) bool { return strings.HasPrefix(key, prefix) }) {
+			continue
+		}
+
+		fields, err := client.HGetAll(ctx, key).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		content, ok := fields[ContentField]
+		if !ok {
+			continue
+		}
+		delete(fields, VectorField)
+		// documents indexed before typed metadata only have the json blob
+		for k, v := range MetadataToFields(DecodeMetadata(fields)) {
+			if _, ok := fields[k]; !ok {
+				fields[k] = fmt.Sprint(v)
+			}
+		}
+
+		keys = append(keys, key)
+		texts = append(texts, content)
+		hashes = append(hashes, fields)
+
+		if len(keys) >= opts.BatchSize {
+			if err = flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan documents: %w", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return migrated, nil
+}
+
+// switchAlias points the alias to target. FT.ALIASUPDATE is atomic, a legacy index
+// named after the alias has to be dropped (keeping its documents) before the alias is added.
+func switchAlias(ctx context.Context, client *redis.Client, source, target *Index) error {
+	switch {
+	case source == nil:
+		if err := client.Do(ctx, "FT.ALIASADD", AliasName(), target.Name).Err(); err != nil {
+			return fmt.Errorf("failed to add index alias: %w", err)
+		}
+	case source.Name == AliasName():
+		if err := client.Do(ctx, "FT.DROPINDEX", source.Name).Err(); err != nil {
+			return fmt.Errorf("failed to drop legacy index: %w", err)
+		}
+		if err := client.Do(ctx, "FT.ALIASADD", AliasName(), target.Name).Err(); err != nil {
+			return fmt.Errorf("failed to add index alias: %w", err)
+		}
+	default:
+		if err := client.Do(ctx, "FT.ALIASUPDATE", AliasName(), target.Name).Err(); err != nil {
+			return fmt.Errorf("failed to update index alias: %w", err)
+		}
+	}
+	return nil
+}
+
+// VectorToBytes encodes a vector as FLOAT32 little endian, the layout of redis vector fields.
+func VectorToBytes(vector []float64) []byte {
+	b := make([]byte, len(vector)*4)
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(float32(v)))
+	}
+	return b
+}
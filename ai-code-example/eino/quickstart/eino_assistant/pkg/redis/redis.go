@@ -19,11 +19,15 @@ package redis
 import (
 	"context"
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/cloudwego/eino/components/embedding"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
 )
 
 const (
@@ -36,80 +40,365 @@ const (
 	DistanceField = "distance"
 )
 
-var initOnce sync.Once
+// SchemaVersion is bumped whenever the index schema changes, documents are moved
+// to the new schema by the migration command like a change of embedding model.
+const SchemaVersion = 2
+
+const (
+	AlgorithmFLAT = "FLAT"
+	AlgorithmHNSW = "HNSW"
+)
 
-func Init() error {
-	var err error
+var (
+	initOnce  sync.Once
+	initIndex *Index
+	initErr   error
+)
+
+// Init probes the embedding dimension of emb and makes sure the versioned index
+// for it exists and is the one served by the alias. It only runs once per process.
+func Init(ctx context.Context, emb embedding.Embedder) (*Index, error) {
 	initOnce.Do(func() {
-		err = InitRedisIndex(context.Background(), &Config{
-			RedisAddr: os.Getenv("REDIS_ADDR"),
-			Dimension: 4096,
-		})
+		var dim int
+		dim, initErr = ProbeDimension(ctx, emb)
+		if initErr != nil {
+			return
+		}
+
+		config := DefaultConfig()
+		config.Dimension = dim
+		initIndex, initErr = InitRedisIndex(ctx, config)
 	})
-	return err
+	return initIndex, initErr
 }
 
 type Config struct {
 	RedisAddr string
+	// Model is the embedding model name, the index is versioned by Model, Dimension, the vector
+	// algorithm with its parameters and SchemaVersion, see IndexVersion.
+	Model     string
 	Dimension int
+
+	// Algorithm of the vector field, FLAT or HNSW, default HNSW.
+	Algorithm      string
+	DistanceMetric string
+	HNSW           HNSWConfig
 }
 
-func InitRedisIndex(ctx context.Context, config *Config) (err error) {
-	if config.Dimension <= 0 {
-		return fmt.Errorf("dimension must be positive")
+// HNSWConfig only takes effect when Algorithm is HNSW, zero values fall back to redis defaults.
+type HNSWConfig struct {
+	M              int
+	EFConstruction int
+	EFRuntime      int
+}
+
+// DefaultConfig is NewConfig of the loaded assistant config.
+func DefaultConfig() *Config {
+	return NewConfig(config.Get())
+}
+
+// NewConfig takes the index config from the assistant config, Dimension is left for the caller to fill in.
+func NewConfig(cfg *config.Config) *Config {
+	c := &Config{
+		RedisAddr:      cfg.Redis.Addr,
+		Model:          fake.ModelName(cfg.Model.EmbeddingModel),
+		Algorithm:      strings.ToUpper(cfg.Redis.IndexAlgorithm),
+		DistanceMetric: "COSINE",
+		HNSW: HNSWConfig{
+			M:              cfg.Redis.HNSWM,
+			EFConstruction: cfg.Redis.HNSWConstruction,
+			EFRuntime:      cfg.Redis.HNSWRuntime,
+		},
+	}
+	if c.Algorithm == "" {
+		c.Algorithm = AlgorithmHNSW
 	}
+	return c
+}
 
-	client := redis.NewClient(&redis.Options{
-		Addr:     config.RedisAddr,
+// Index describes one physical vector index and the key prefix of its documents.
+type Index struct {
+	Name      string
+	KeyPrefix string
+	Dimension int
+}
+
+// AliasName is the name retrievers search on, it always points to the active Index.
+func AliasName() string {
+	return RedisPrefix + IndexName
+}
+
+// IndexVersion names an index after the embedding model, its dimension, the vector algorithm
+// with its build parameters and the schema version, so that switching model never mixes vectors of
+// different sizes, and a changed algorithm builds a new index instead of being ignored.
+// EF_RUNTIME only tunes the queries, it is left out.
+func IndexVersion(config *Config) string {
+	model := strings.ToLower(config.Model)
+	model = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '.' {
+			return r
+		}
+		return '-'
+	}, model)
+	if model == "" {
+		model = "default"
+	}
+	return fmt.Sprintf("%s_%d_%s_v%d", model, config.Dimension, algorithmVersion(config), SchemaVersion)
+}
+
+// algorithmVersion is the lower case algorithm followed by the HNSW build parameters that are set,
+// e.g. hnsw-m16-efc200, the defaults of redis are left out.
+func algorithmVersion(config *Config) string {
+	version := strings.ToLower(config.Algorithm)
+	if version == "" {
+		version = strings.ToLower(AlgorithmHNSW)
+	}
+	if !strings.EqualFold(config.Algorithm, AlgorithmFLAT) {
+		for _, p := range []struct {
+			name  string
+			value int
+		}{
+			{"m", config.HNSW.M},
+			{"efc", config.HNSW.EFConstruction},
+		} {
+			if p.value > 0 {
+				version += fmt.Sprintf("-%s%d", p.name, p.value)
+			}
+		}
+	}
+	if config.DistanceMetric != "" && !strings.EqualFold(config.DistanceMetric, "COSINE") {
+		version += "-" + strings.ToLower(config.DistanceMetric)
+	}
+	return version
+}
+
+func NewIndex(config *Config) *Index {
+	version := IndexVersion(config)
+	return &Index{
+		Name:      fmt.Sprintf("%s%s:%s", RedisPrefix, IndexName, version),
+		KeyPrefix: fmt.Sprintf("%s%s:", RedisPrefix, version),
+		Dimension: config.Dimension,
+	}
+}
+
+// ProbeDimension embeds a short text to find out the vector size of emb.
+func ProbeDimension(ctx context.Context, emb embedding.Embedder) (int, error) {
+	if emb == nil {
+		return 0, fmt.Errorf("embedding cannot be nil")
+	}
+	vectors, err := emb.EmbedStrings(ctx, []string{"dimension probe"})
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe embedding dimension: %w", err)
+	}
+	if len(vectors) != 1 || len(vectors[0]) == 0 {
+		return 0, fmt.Errorf("failed to probe embedding dimension: empty vector")
+	}
+	return len(vectors[0]), nil
+}
+
+func NewClient(addr string) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     addr,
 		Protocol: 2,
 	})
+}
 
-	defer func() {
-		if err != nil {
-			client.Close()
-		}
-	}()
+// InitRedisIndex creates the versioned index if needed and points the alias to it.
+// If the alias already serves an index of another version, documents must be
+// re-embedded with the migration command first, so an error is returned.
+func InitRedisIndex(ctx context.Context, config *Config) (idx *Index, err error) {
+	if config.Dimension <= 0 {
+		return nil, fmt.Errorf("dimension must be positive")
+	}
+
+	client := NewClient(config.RedisAddr)
+	defer client.Close()
 
 	if err = client.Ping(ctx).Err(); err != nil {
-		return fmt.Errorf("failed to connect to Redis: %w", err)
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	indexName := fmt.Sprintf("%s%s", RedisPrefix, IndexName)
+	idx = NewIndex(config)
 
-	// 检查是否存在索引
-	exists, err := client.Do(ctx, "FT.INFO", indexName).Result()
+	current, err := ResolveAlias(ctx, client)
 	if err != nil {
-		if !strings.Contains(err.Error(), "Unknown index name") {
-			return fmt.Errorf("failed to check if index exists: %w", err)
+		return nil, err
+	}
+	if current != nil && current.Name != idx.Name {
+		return nil, fmt.Errorf("index alias %s serves %s, but embedding model %q with algorithm %s requires %s, run cmd/indexmigrate to rebuild the index",
+			AliasName(), current.Name, config.Model, algorithmVersion(config), idx.Name)
+	}
+
+	if err = CreateIndex(ctx, client, idx, config); err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		if err = client.Do(ctx, "FT.ALIASADD", AliasName(), idx.Name).Err(); err != nil {
+			return nil, fmt.Errorf("failed to add index alias: %w", err)
 		}
-		err = nil
-	} else if exists != nil {
+	}
+
+	return idx, nil
+}
+
+// CreateIndex creates idx if it does not exist yet.
+func CreateIndex(ctx context.Context, client *redis.Client, idx *Index, config *Config) error {
+	// 检查是否存在索引
+	exists, err := indexExists(ctx, client, idx.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
 		return nil
 	}
 
+	vectorArgs := []interface{}{
+		"TYPE", "FLOAT32",
+		"DIM", idx.Dimension,
+		"DISTANCE_METRIC", config.DistanceMetric,
+	}
+	algorithm := config.Algorithm
+	switch algorithm {
+	case AlgorithmFLAT:
+	case AlgorithmHNSW:
+		if config.HNSW.M > 0 {
+			vectorArgs = append(vectorArgs, "M", config.HNSW.M)
+		}
+		if config.HNSW.EFConstruction > 0 {
+			vectorArgs = append(vectorArgs, "EF_CONSTRUCTION", config.HNSW.EFConstruction)
+		}
+		if config.HNSW.EFRuntime > 0 {
+			vectorArgs = append(vectorArgs, "EF_RUNTIME", config.HNSW.EFRuntime)
+		}
+	default:
+		return fmt.Errorf("unsupported vector algorithm: %s", algorithm)
+	}
+
 	// Create new index
 	createIndexArgs := []interface{}{
-		"FT.CREATE", indexName,
+		"FT.CREATE", idx.Name,
 		"ON", "HASH",
-		"PREFIX", "1", RedisPrefix,
+		"PREFIX", "1", idx.KeyPrefix,
 		"SCHEMA",
 		ContentField, "TEXT",
 		MetadataField, "TEXT",
-		VectorField, "VECTOR", "FLAT",
-		"6",
-		"TYPE", "FLOAT32",
-		"DIM", config.Dimension,
-		"DISTANCE_METRIC", "COSINE",
+		SourceField, "TAG",
+		DocTypeField, "TAG",
+		LanguageField, "TAG",
+		UpdatedAtField, "NUMERIC", "SORTABLE",
+		VectorField, "VECTOR", algorithm,
+		len(vectorArgs),
 	}
+	createIndexArgs = append(createIndexArgs, vectorArgs...)
 
 	if err = client.Do(ctx, createIndexArgs...).Err(); err != nil {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
 
 	// 验证索引是否创建成功
-	if _, err = client.Do(ctx, "FT.INFO", indexName).Result(); err != nil {
+	if _, err = client.Do(ctx, "FT.INFO", idx.Name).Result(); err != nil {
 		return fmt.Errorf("failed to verify index creation: %w", err)
 	}
 
 	return nil
 }
+
+// ResolveAlias returns the index currently served by the alias, nil if there is none.
+// Indexes created before versioning were named after the alias itself, these are
+// returned with Name equal to AliasName.
+func ResolveAlias(ctx context.Context, client *redis.Client) (*Index, error) {
+	return describeIndex(ctx, client, AliasName())
+}
+
+// listIndexes returns every index of the redis server, nil if none.
+func listIndexes(ctx context.Context, client *redis.Client) ([]*Index, error) {
+	res, err := client.Do(ctx, "FT._LIST").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	names, _ := res.([]interface{})
+	indexes := make([]*Index, 0, len(names))
+	for _, name := range names {
+		idx, err := describeIndex(ctx, client, fmt.Sprint(name))
+		if err != nil {
+			return nil, err
+		}
+		if idx != nil {
+			indexes = append(indexes, idx)
+		}
+	}
+	return indexes, nil
+}
+
+// describeIndex reads the name, key prefix and dimension of the index or alias name,
+// nil if it does not exist.
+func describeIndex(ctx context.Context, client *redis.Client, name string) (*Index, error) {
+	res, err := client.Do(ctx, "FT.INFO", name).Result()
+	if err != nil {
+		if isUnknownIndex(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check if index exists: %w", err)
+	}
+
+	info := toMap(res)
+	idx := &Index{}
+	idx.Name, _ = info["index_name"].(string)
+
+	definition := toMap(info["index_definition"])
+	if prefixes, ok := definition["prefixes"].([]interface{}); ok && len(prefixes) > 0 {
+		idx.KeyPrefix, _ = prefixes[0].(string)
+	}
+
+	if attributes, ok := info["attributes"].([]interface{}); ok {
+		for _, attr := range attributes {
+			fields, ok := attr.([]interface{})
+			if !ok {
+				continue
+			}
+			// attribute is a flat list, DIM is not always a pair of the leading key-value part
+			for i := 0; i+1 < len(fields); i++ {
+				if name, _ := fields[i].(string); strings.EqualFold(name, "dim") {
+					idx.Dimension, _ = strconv.Atoi(fmt.Sprint(fields[i+1]))
+				}
+			}
+		}
+	}
+
+	return idx, nil
+}
+
+func indexExists(ctx context.Context, client *redis.Client, name string) (bool, error) {
+	_, err := client.Do(ctx, "FT.INFO", name).Result()
+	if err != nil {
+		if isUnknownIndex(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check if index exists: %w", err)
+	}
+	return true, nil
+}
+
+func isUnknownIndex(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unknown index name") || strings.Contains(msg, "no such index")
+}
+
+// toMap converts a RESP2 flat key-value list into a map.
+func toMap(v interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	list, ok := v.([]interface{})
+	if !ok {
+		if mm, ok := v.(map[interface{}]interface{}); ok {
+			for k, val := range mm {
+				m[fmt.Sprint(k)] = val
+			}
+		}
+		return m
+	}
+	for i := 0; i+1 < len(list); i += 2 {
+		m[fmt.Sprint(list[i])] = list[i+1]
+	}
+	return m
+}
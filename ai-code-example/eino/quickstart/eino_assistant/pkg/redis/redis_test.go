@@ -0,0 +1,35 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import "testing"
+
+func TestIndexVersion(t *testing.T) {
+	for _, c := range []struct {
+		config *Config
+		want   string
+	}{
+		{&Config{Model: "doubao-embedding-large", Dimension: 4096, Algorithm: AlgorithmHNSW}, "doubao-embedding-large_4096_hnsw_v2"},
+		{&Config{Model: "Doubao/Embedding", Dimension: 8, Algorithm: AlgorithmFLAT, HNSW: HNSWConfig{M: 16}}, "doubao-embedding_8_flat_v2"},
+		{&Config{Dimension: 8, Algorithm: AlgorithmHNSW, HNSW: HNSWConfig{M: 16, EFConstruction: 200}}, "default_8_hnsw-m16-efc200_v2"},
+		{&Config{Dimension: 8, Algorithm: AlgorithmHNSW, HNSW: HNSWConfig{EFRuntime: 10}, DistanceMetric: "L2"}, "default_8_hnsw-l2_v2"},
+	} {
+		if got := IndexVersion(c.config); got != c.want {
+			t.Errorf("IndexVersion(%+v) = %s, want %s", c.config, got, c.want)
+		}
+	}
+}
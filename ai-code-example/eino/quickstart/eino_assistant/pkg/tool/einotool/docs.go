@@ -0,0 +1,104 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einotool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino-ext/components/document/loader/file"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+
+	redispkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/redis"
+)
+
+// titleKey is the metadata key the markdown splitter of knowledgeindexing puts the "#" heading in.
+const titleKey = "title"
+
+// maxSectionRunes keeps a few long sections from filling the context of the agent.
+const maxSectionRunes = 1500
+
+// DocSection is a section of the indexed eino-docs.
+type DocSection struct {
+	// Headings are the title of the page followed by the headings within the section.
+	Headings []string `json:"headings"`
+	Source   string   `json:"source"`
+	DocType  string   `json:"doc_type,omitempty"`
+	Score    float64  `json:"score"`
+	Content  string   `json:"content"`
+}
+
+// searchDocs retrieves the sections of the docs best matching query, docType narrows them to
+// a doc category, eino_index or empty searches all of them.
+func searchDocs(ctx context.Context, rtr retriever.Retriever, query, docType string, topK int) ([]*DocSection, error) {
+	opts := []retriever.Option{retriever.WithTopK(topK)}
+	if docType != "" && docType != "eino_index" {
+		opt, err := redispkg.FilterOption(redispkg.DocTypeField + ":" + docType)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+
+	docs, err := rtr.Retrieve(ctx, query, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search docs: %w", err)
+	}
+
+	sections := make([]*DocSection, 0, len(docs))
+	for _, doc := range docs {
+		sections = append(sections, newDocSection(doc))
+	}
+	return sections, nil
+}
+
+func newDocSection(doc *schema.Document) *DocSection {
+	section := &DocSection{Score: doc.Score(), Content: doc.Content}
+	source, _ := doc.MetaData[redispkg.SourceField].(string)
+	if source == "" {
+		source, _ = doc.MetaData[file.MetaKeySource].(string)
+	}
+	section.Source = source
+	section.DocType, _ = doc.MetaData[redispkg.DocTypeField].(string)
+
+	if title, _ := doc.MetaData[titleKey].(string); title != "" {
+		section.Headings = append(section.Headings, title)
+	}
+	for _, line := range strings.Split(doc.Content, "\n") {
+		if heading, ok := markdownHeading(line); ok {
+			section.Headings = append(section.Headings, heading)
+		}
+	}
+
+	if runes := []rune(section.Content); len(runes) > maxSectionRunes {
+		section.Content = string(runes[:maxSectionRunes]) + "..."
+	}
+	return section
+}
+
+// markdownHeading returns the text of an ATX heading line, e.g. "## Graph" is "Graph".
+func markdownHeading(line string) (string, bool) {
+	text := strings.TrimLeft(line, "#")
+	level := len(line) - len(text)
+	if level == 0 || level > 6 || !strings.HasPrefix(text, " ") {
+		return "", false
+	}
+	text = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(text), "#"))
+	return text, text != ""
+}
@@ -19,11 +19,18 @@ package einotool
 import (
 	"context"
 	"embed"
-	"os"
+	"encoding/json"
+	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/cloudwego/eino/components/retriever"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	configpkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
 )
 
 //go:embed templates/*
@@ -31,10 +38,12 @@ var templateFS embed.FS
 
 const desc = `eino tool can get eino project info, 
 action:
+- search_docs: search the eino docs by query, returns the best matching sections with their headings and source paths
+- list_examples: list the example projects of the local eino-examples checkout, optionally filtered by query
 - get_example_project: get the example project url, path of eino-examples
 - get_github_repo: get the github repo url, e.g. eino, eino-ext, eino-examples
 - get_doc_url: get the doc url of eino website
-- init_template: init the eino project template, to create files from template
+- init_template: generate an eino project from a template, with its go.mod, the chat model provider, tools and memory are chosen by template_params
 `
 
 type EinoAssistantToolImpl struct {
@@ -43,15 +52,29 @@ type EinoAssistantToolImpl struct {
 
 type EinoAssistantToolConfig struct {
 	BaseDir string
+	// Retriever searches the indexed eino-docs for search_docs, which is unavailable without it.
+	Retriever retriever.Retriever
+	// ExamplesDir is the eino-examples checkout list_examples scans, default the one the
+	// gitclone tool clones into ReposDir.
+	ExamplesDir string
 }
 
 func defaultEinoAssistantToolConfig(ctx context.Context) (*EinoAssistantToolConfig, error) {
 	config := &EinoAssistantToolConfig{
-		BaseDir: "./data/eino",
+		BaseDir:     configpkg.Get().Data.EinoDir,
+		ExamplesDir: DefaultExamplesDir(configpkg.Get().Data.ReposDir),
 	}
 	return config, nil
 }
 
+// DefaultExamplesDir is where the gitclone tool clones eino-examples into reposDir.
+func DefaultExamplesDir(reposDir string) string {
+	return filepath.Join(reposDir, "cloudwego", "eino-examples")
+}
+
+// defaultDocsTopK is the number of sections search_docs returns if top_k is not set.
+const defaultDocsTopK = 3
+
 func NewEinoAssistantTool(ctx context.Context, config *EinoAssistantToolConfig) (tn tool.BaseTool, err error) {
 	if config == nil {
 		config, err = defaultEinoAssistantToolConfig(ctx)
@@ -89,22 +112,71 @@ var (
 		"graph":      {"https://github.com/cloudwego/eino-examples/tree/main/compose/graph/tool_call_agent.go"},
 		"quickstart": {"https://github.com/cloudwego/eino-examples/tree/main/quickstart"},
 	}
-
-	Template = map[string][]string{
-		"react_agent": {"react_agent/main.go"},
-		"simple_llm":  {"simple_llm/main.go"},
-		"http_agent":  {"http_agent/main.go", "http_agent/README.md", "http_agent/client/main.go"},
-	}
 )
 
 func (e *EinoAssistantToolImpl) ToEinoTool() (tool.BaseTool, error) {
-	return utils.InferTool("eino_tool", desc, e.Invoke)
+	it, err := utils.InferTool("eino_tool", desc, e.Invoke)
+	if err != nil {
+		return nil, err
+	}
+	return approval.Declare(it, e.Risk), nil
+}
+
+// Risk is medium for init_template, which writes files, and high if it overwrites the changed ones.
+func (e *EinoAssistantToolImpl) Risk(argumentsInJSON string) approval.Risk {
+	var req EinoToolRequest
+	if err := json.Unmarshal([]byte(argumentsInJSON), &req); err != nil {
+		return approval.RiskHigh
+	}
+	if req.Action != EinoToolActionInitTemplate {
+		return approval.RiskLow
+	}
+	if req.TemplateParams != nil && req.TemplateParams.Conflict == ConflictOverwrite {
+		return approval.RiskHigh
+	}
+	return approval.RiskMedium
 }
 
 func (e *EinoAssistantToolImpl) Invoke(ctx context.Context, req *EinoToolRequest) (res *EinoToolResponse, err error) {
 	res = &EinoToolResponse{}
 
 	switch req.Action {
+	case EinoToolActionSearchDocs:
+		if req.Query == "" {
+			res.Error = "query is required"
+			return
+		}
+		if e.config.Retriever == nil {
+			res.Error = "docs search is not configured, the doc urls are available by get_doc_url"
+			return
+		}
+		topK := req.TopK
+		if topK <= 0 {
+			topK = defaultDocsTopK
+		}
+		sections, err := searchDocs(ctx, e.config.Retriever, req.Query, req.DocType, topK)
+		if err != nil {
+			res.Error = err.Error()
+			return res, nil
+		}
+		if len(sections) == 0 {
+			res.Message = "no sections found, try other words or without doc_type"
+			return res, nil
+		}
+		res.Message = fmt.Sprintf("found %d sections", len(sections))
+		res.Sections = sections
+	case EinoToolActionListExamples:
+		examples, err := listExamples(e.config.ExamplesDir, req.Query)
+		if err != nil {
+			res.Error = err.Error()
+			return res, nil
+		}
+		res.Message = fmt.Sprintf("found %d examples in %s", len(examples), e.config.ExamplesDir)
+		if len(examples) > maxExamples {
+			res.Message += fmt.Sprintf(", the first %d are listed, narrow them by query", maxExamples)
+			examples = examples[:maxExamples]
+		}
+		res.Examples = examples
 	case EinoToolActionGetExampleProject:
 		exampleURL := EinoExample[req.ExampleType]
 		if len(exampleURL) == 0 {
@@ -127,42 +199,15 @@ func (e *EinoAssistantToolImpl) Invoke(ctx context.Context, req *EinoToolRequest
 		}
 		res.Message = docURL
 	case EinoToolActionInitTemplate:
-		templateURL := Template[req.TemplateType]
-		if len(templateURL) == 0 {
-			res.Error = "invalid template type, can be one of: react_agent, simple_llm, http_agent"
-			return res, nil
-		}
-
-		baseDir := e.config.BaseDir
-		for _, file := range templateURL {
-			// Read template file
-			content, err := templateFS.ReadFile(filepath.Join("templates", file))
-			if err != nil {
-				res.Error = "failed to read template file: " + err.Error()
-				return res, nil
-			}
-
-			// Create target directory
-			targetPath := filepath.Join(baseDir, file)
-			if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-				res.Error = "failed to create directory: " + err.Error()
-				return res, nil
-			}
-
-			// Write file
-			if err := os.WriteFile(targetPath, content, 0644); err != nil {
-				res.Error = "failed to write file: " + err.Error()
-				return res, nil
-			}
-		}
-		absPath, err := filepath.Abs(filepath.Join(baseDir, req.TemplateType))
+		result, err := Scaffold(e.config.BaseDir, req.TemplateType, req.TemplateParams)
 		if err != nil {
-			absPath = filepath.Join(baseDir, req.TemplateType)
+			res.Error = err.Error()
+			return res, nil
 		}
-		res.Message = "success, init template, path is: " + absPath
+		res.Message = scaffoldMessage(result, ignoredParams(req.TemplateType, req.TemplateParams))
 		return res, nil
 	default:
-		res.Error = "invalid action, can be one of: get_example_project, get_github_repo, get_doc_url"
+		res.Error = "invalid action, can be one of: search_docs, list_examples, get_example_project, get_github_repo, get_doc_url, init_template"
 	}
 
 	return res, nil
@@ -171,6 +216,8 @@ func (e *EinoAssistantToolImpl) Invoke(ctx context.Context, req *EinoToolRequest
 type EinoToolAction string
 
 const (
+	EinoToolActionSearchDocs        EinoToolAction = "search_docs"         // 搜索文档
+	EinoToolActionListExamples      EinoToolAction = "list_examples"       // 列出本地示例
 	EinoToolActionGetExampleProject EinoToolAction = "get_example_project" // 获取示例项目
 	EinoToolActionGetGithubRepo     EinoToolAction = "get_github_repo"     // 获取 github 仓库
 	EinoToolActionGetDocURL         EinoToolAction = "get_doc_url"         // 获取文档地址
@@ -178,14 +225,59 @@ const (
 )
 
 type EinoToolRequest struct {
-	Action       EinoToolAction `json:"action" jsonschema:"description='The action of the request',enum=get_example_project,enum=get_github_repo,enum=get_doc_url,enum=init_template"`
-	ExampleType  string         `json:"example_type,omitempty" jsonschema:"description='The type of the example project, only for action: get_example_project',enum=agent,enum=components,enum=graph,enum=quickstart"`
-	RepoType     string         `json:"repo_type,omitempty" jsonschema:"description='The type of the repo, only for action: get_github_repo',enum=eino,enum=eino-ext,enum=eino-examples"`
-	DocType      string         `json:"doc_type,omitempty" jsonschema:"description='The type of the doc, only for action: get_doc_url',enum=eino_index,enum=quickstart,enum=graph,enum=agent,enum=components,enum=integrate"`
-	TemplateType string         `json:"template_type,omitempty" jsonschema:"description='The template of the project, only for action: init_template',enum=react_agent,enum=simple_llm,enum=http_agent"`
+	Action       EinoToolAction `json:"action" jsonschema:"enum=search_docs,enum=list_examples,enum=get_example_project,enum=get_github_repo,enum=get_doc_url,enum=init_template" jsonschema_description:"The action of the request"`
+	Query        string         `json:"query,omitempty" jsonschema_description:"The topic to search for action: search_docs or the words to filter by for action: list_examples"`
+	TopK         int            `json:"top_k,omitempty" jsonschema_description:"Max number of sections to return only for action: search_docs"`
+	ExampleType  string         `json:"example_type,omitempty" jsonschema:"enum=agent,enum=components,enum=graph,enum=quickstart" jsonschema_description:"The type of the example project, only for action: get_example_project"`
+	RepoType     string         `json:"repo_type,omitempty" jsonschema:"enum=eino,enum=eino-ext,enum=eino-examples" jsonschema_description:"The type of the repo, only for action: get_github_repo"`
+	DocType      string         `json:"doc_type,omitempty" jsonschema:"enum=eino_index,enum=quickstart,enum=graph,enum=agent,enum=components,enum=integrate" jsonschema_description:"The type of the doc for action: get_doc_url or the doc category to search in for action: search_docs"`
+	TemplateType string         `json:"template_type,omitempty" jsonschema:"enum=simple_llm,enum=react_agent,enum=http_agent,enum=graph_workflow,enum=mcp_server,enum=adk_multi_agent" jsonschema_description:"The template of the project, only for action: init_template"`
+
+	TemplateParams *TemplateParams `json:"template_params,omitempty" jsonschema_description:"The options of the project, only for action: init_template"`
 }
 
 type EinoToolResponse struct {
-	Message string `json:"message" jsonschema:"description=The message of the response"`
-	Error   string `json:"error" jsonschema:"description=The error of the response"`
+	Message  string        `json:"message" jsonschema_description:"The message of the response"`
+	Sections []*DocSection `json:"sections,omitempty" jsonschema_description:"The matched doc sections of search_docs"`
+	Examples []*Example    `json:"examples,omitempty" jsonschema_description:"The example projects of list_examples"`
+	Error    string        `json:"error" jsonschema_description:"The error of the response"`
+}
+
+// scaffoldMessage tells the agent what init_template did, with the diffs of the files it kept.
+func scaffoldMessage(res *ScaffoldResult, ignored []string) string {
+	dir, err := filepath.Abs(res.Dir)
+	if err != nil {
+		dir = res.Dir
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "success, init template, path is: %s\n", dir)
+	for _, files := range []struct {
+		what  string
+		paths []string
+	}{
+		{"written", res.Written},
+		{"overwritten", res.Overwritten},
+		{"unchanged", res.Unchanged},
+		{"skipped, they exist with other content", res.Skipped},
+	} {
+		if len(files.paths) > 0 {
+			fmt.Fprintf(&sb, "%s: %s\n", files.what, strings.Join(files.paths, ", "))
+		}
+	}
+	if len(ignored) > 0 {
+		fmt.Fprintf(&sb, "the template does not use: %s\n", strings.Join(ignored, ", "))
+	}
+	sb.WriteString("run go mod tidy in the project before go run, see its README.md\n")
+	if len(res.Diffs) > 0 {
+		sb.WriteString("these files exist with other content and were kept, the diffs to the template are:\n")
+		paths := make([]string, 0, len(res.Diffs))
+		for path := range res.Diffs {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			sb.WriteString(res.Diffs[path])
+		}
+	}
+	return sb.String()
 }
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einotool
+
+import (
+	"bufio"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Example is a runnable program of the local eino-examples checkout.
+type Example struct {
+	// Path is relative to the checkout, e.g. flow/agent/react.
+	Path        string `json:"path"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+}
+
+// maxExamples keeps the whole checkout from being listed at once.
+const maxExamples = 50
+
+// skippedDirs are never examples, nor contain them.
+var skippedDirs = map[string]bool{"vendor": true, "testdata": true, "node_modules": true}
+
+// listExamples scans dir for the directories with a main package, sorted by path. If query is
+// set only the examples with all of its words in their path, title or description are kept.
+func listExamples(dir, query string) ([]*Example, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("eino-examples is not cloned to %s, clone %s with the gitclone tool first", dir, EinoRepo["eino-examples"])
+	}
+	words := strings.Fields(strings.ToLower(query))
+
+	var examples []*Example
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+		ex := readExample(path)
+		if ex == nil {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		ex.Path = filepath.ToSlash(rel)
+		ex.URL = strings.TrimSuffix(EinoRepo["eino-examples"]+"/tree/main/"+ex.Path, "/.")
+		if matchWords(words, ex.Path, ex.Title, ex.Description) {
+			examples = append(examples, ex)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	sort.Slice(examples, func(i, j int) bool { return examples[i].Path < examples[j].Path })
+	return examples, nil
+}
+
+// readExample returns the example of dir, nil if none of its go files is a main package. The title
+// and description come from the README, else the package comment.
+func readExample(dir string) *Example {
+	goFiles, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	sort.Strings(goFiles)
+	var doc string
+	isMain := false
+	for _, path := range goFiles {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || f.Name.Name != "main" {
+			continue
+		}
+		isMain = true
+		if f.Doc != nil && doc == "" {
+			doc = f.Doc.Text()
+		}
+	}
+	if !isMain {
+		return nil
+	}
+
+	ex := &Example{}
+	for _, name := range []string{"README.md", "README_zh.md", "readme.md"} {
+		if ex.Title, ex.Description = readReadme(filepath.Join(dir, name)); ex.Title != "" || ex.Description != "" {
+			break
+		}
+	}
+	if ex.Description == "" {
+		ex.Description = firstParagraph(doc)
+	}
+	return ex
+}
+
+// readReadme returns the first heading and the first paragraph of a README.
+func readReadme(path string) (title, description string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+
+	var paragraph []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if heading, ok := markdownHeading(line); ok {
+			if len(paragraph) > 0 {
+				break
+			}
+			if title == "" {
+				title = heading
+			}
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "```") || strings.HasPrefix(line, "<") || strings.HasPrefix(line, "!") {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	return title, strings.Join(paragraph, " ")
+}
+
+func firstParagraph(text string) string {
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(text), "\n\n")
+	return strings.Join(strings.Fields(paragraph), " ")
+}
+
+func matchWords(words []string, fields ...string) bool {
+	text := strings.ToLower(strings.Join(fields, " "))
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
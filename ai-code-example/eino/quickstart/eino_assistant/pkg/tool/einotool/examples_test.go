@@ -0,0 +1,76 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einotool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_listExamples(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"flow/agent/react/main.go":   "package main\n\nfunc main() {}\n",
+		"flow/agent/react/README.md": "# React Agent\n\nA react agent which calls tools.\nIt loops until done.\n\n## Run\n",
+		"compose/graph/main.go":      "// Graph shows a graph with branches.\n//\n// More words.\npackage main\n",
+		"components/tool/tool.go":    "package tool\n",
+		".git/hooks/main.go":         "package main\n",
+		"vendor/x/main.go":           "package main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	examples, err := listExamples(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != 2 {
+		t.Fatalf("examples %+v", examples)
+	}
+	graph, react := examples[0], examples[1]
+	if graph.Path != "compose/graph" || graph.Description != "Graph shows a graph with branches." {
+		t.Fatalf("graph %+v", graph)
+	}
+	if react.Title != "React Agent" || react.Description != "A react agent which calls tools. It loops until done." ||
+		react.URL != "https://github.com/cloudwego/eino-examples/tree/main/flow/agent/react" {
+		t.Fatalf("react %+v", react)
+	}
+
+	examples, err = listExamples(dir, "REACT tools")
+	if err != nil || len(examples) != 1 || examples[0].Path != "flow/agent/react" {
+		t.Fatalf("examples %+v, err %v", examples, err)
+	}
+	if _, err = listExamples(filepath.Join(dir, "missing"), ""); err == nil {
+		t.Fatal("expected an error for a missing checkout")
+	}
+}
+
+func Test_markdownHeading(t *testing.T) {
+	for line, want := range map[string]string{"## Graph ##": "Graph", "# 快速开始": "快速开始", "#tag": "", "text": "", "####### deep": ""} {
+		if got, _ := markdownHeading(line); got != want {
+			t.Errorf("%q: got %q, want %q", line, got, want)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einotool
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ProjectTemplate is a project init_template can generate. Its files are templates/<name>
+// and the Shared files, the ones ending with .tmpl are rendered with text/template.
+type ProjectTemplate struct {
+	Description string
+	// Shared are the files of templates/shared the project needs, e.g. model.go.tmpl.
+	Shared []string
+	// Params are the TemplateParams the project uses besides name and module.
+	Params []string
+	// Requires are the modules of its go.mod besides the ones of the provider, the tools and the memory.
+	Requires []string
+}
+
+var Templates = map[string]*ProjectTemplate{
+	"simple_llm": {
+		Description: "a chain of a chat template and a chat model",
+		Shared:      []string{"model.go.tmpl"},
+		Params:      []string{"provider"},
+	},
+	"react_agent": {
+		Description: "a react agent calling tools",
+		Shared:      []string{"model.go.tmpl", "tools.go.tmpl"},
+		Params:      []string{"provider", "tools"},
+	},
+	"http_agent": {
+		Description: "a react agent served over http with sse and conversation memory",
+		Shared:      []string{"model.go.tmpl", "tools.go.tmpl", "memory.go.tmpl"},
+		Params:      []string{"provider", "tools", "memory"},
+		Requires:    []string{"github.com/cloudwego/hertz", "github.com/hertz-contrib/sse"},
+	},
+	"graph_workflow": {
+		Description: "a graph that routes between the chat model and the tools with a branch",
+		Shared:      []string{"model.go.tmpl", "tools.go.tmpl"},
+		Params:      []string{"provider", "tools"},
+	},
+	"mcp_server": {
+		Description: "an MCP server exposing tools over stdio or sse",
+		Requires:    []string{"github.com/mark3labs/mcp-go"},
+	},
+	"adk_multi_agent": {
+		Description: "an adk supervisor handing the work to a researcher and a writer agent",
+		Shared:      []string{"model.go.tmpl", "tools.go.tmpl"},
+		Params:      []string{"provider", "tools"},
+	},
+}
+
+// Versions are the versions of the modules the generated go.mod requires, run go mod tidy
+// in the project to add the indirect ones.
+var Versions = map[string]string{
+	"github.com/cloudwego/eino":                                "v0.7.13",
+	"github.com/cloudwego/eino-ext/components/model/ark":       "v0.1.54",
+	"github.com/cloudwego/eino-ext/components/model/openai":    "v0.1.8",
+	"github.com/cloudwego/eino-ext/components/model/ollama":    "v0.1.6",
+	"github.com/cloudwego/eino-ext/components/tool/duckduckgo": "v0.0.0-20250117061805-cd80d1780d76",
+	"github.com/cloudwego/eino-ext/components/tool/mcp":        "v0.0.8",
+	"github.com/mark3labs/mcp-go":                              "v0.43.2",
+	"github.com/cloudwego/hertz":                               "v0.10.3",
+	"github.com/hertz-contrib/sse":                             "v0.0.6-0.20240617114443-10a844794bf3",
+	"github.com/redis/go-redis/v9":                             "v9.7.0",
+}
+
+var (
+	Providers = map[string]string{
+		"ark":    "github.com/cloudwego/eino-ext/components/model/ark",
+		"openai": "github.com/cloudwego/eino-ext/components/model/openai",
+		"ollama": "github.com/cloudwego/eino-ext/components/model/ollama",
+	}
+
+	// Tools are the tools an agent can be generated with, the value is the module, if any.
+	Tools = map[string]string{
+		"duckduckgo":   "github.com/cloudwego/eino-ext/components/tool/duckduckgo",
+		"mcp":          "github.com/cloudwego/eino-ext/components/tool/mcp",
+		"current_time": "",
+	}
+
+	// Memories are the backends of the conversation memory, the value is the module, if any.
+	Memories = map[string]string{
+		"memory": "",
+		"file":   "",
+		"redis":  "github.com/redis/go-redis/v9",
+	}
+)
+
+// ConflictPolicy decides what happens to a file of the project that exists with other content.
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // keep the file
+	ConflictOverwrite ConflictPolicy = "overwrite" // replace the file
+	ConflictDiff      ConflictPolicy = "diff"      // keep the file and report the diff to the template
+)
+
+// TemplateParams are the options of init_template. The descriptions are in jsonschema_description,
+// the jsonschema tag would split them on commas.
+type TemplateParams struct {
+	Name     string         `json:"name,omitempty" jsonschema_description:"The directory of the project under the eino dir and the name of its binary; default the template type"`
+	Module   string         `json:"module,omitempty" jsonschema_description:"The module path of the generated go.mod; default the name"`
+	Provider string         `json:"provider,omitempty" jsonschema:"enum=ark,enum=openai,enum=ollama" jsonschema_description:"The provider of the chat model; default ark"`
+	Tools    []string       `json:"tools,omitempty" jsonschema_description:"The tools of the agent: duckduckgo or mcp or current_time; default duckduckgo"`
+	Memory   string         `json:"memory,omitempty" jsonschema:"enum=memory,enum=file,enum=redis" jsonschema_description:"Where the conversations are kept; default memory"`
+	Conflict ConflictPolicy `json:"conflict,omitempty" jsonschema:"enum=skip,enum=overwrite,enum=diff" jsonschema_description:"What to do with files that exist with other content; default skip"`
+}
+
+// withDefaults validates the params of template typ and fills in the defaults.
+func (p *TemplateParams) withDefaults(typ string) (*TemplateParams, error) {
+	params := TemplateParams{}
+	if p != nil {
+		params = *p
+	}
+	if params.Name == "" {
+		params.Name = typ
+	}
+	if !validName.MatchString(params.Name) {
+		return nil, fmt.Errorf("invalid name %q, use letters, digits, - and _", params.Name)
+	}
+	if params.Module == "" {
+		params.Module = params.Name
+	}
+	if !validModule.MatchString(params.Module) || strings.Contains(params.Module, "..") {
+		return nil, fmt.Errorf("invalid module path %q", params.Module)
+	}
+	if params.Provider == "" {
+		params.Provider = "ark"
+	}
+	if _, ok := Providers[params.Provider]; !ok {
+		return nil, fmt.Errorf("invalid provider %q, can be one of: %s", params.Provider, keys(Providers))
+	}
+	if len(params.Tools) == 0 {
+		params.Tools = []string{"duckduckgo"}
+	}
+	for _, t := range params.Tools {
+		if _, ok := Tools[t]; !ok {
+			return nil, fmt.Errorf("invalid tool %q, can be one of: %s", t, keys(Tools))
+		}
+	}
+	if params.Memory == "" {
+		params.Memory = "memory"
+	}
+	if _, ok := Memories[params.Memory]; !ok {
+		return nil, fmt.Errorf("invalid memory %q, can be one of: %s", params.Memory, keys(Memories))
+	}
+	switch params.Conflict {
+	case "":
+		params.Conflict = ConflictSkip
+	case ConflictSkip, ConflictOverwrite, ConflictDiff:
+	default:
+		return nil, fmt.Errorf("invalid conflict policy %q, can be one of: skip, overwrite, diff", params.Conflict)
+	}
+	return &params, nil
+}
+
+var (
+	validName   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+	validModule = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._~/-]*$`)
+)
+
+// HasTool is used by the templates.
+func (p *TemplateParams) HasTool(name string) bool {
+	for _, t := range p.Tools {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ScaffoldResult lists the files of the project by what happened to them, the paths
+// are relative to Dir.
+type ScaffoldResult struct {
+	Dir         string
+	Written     []string
+	Unchanged   []string
+	Skipped     []string
+	Overwritten []string
+	// Diffs are the differences of the files kept by ConflictDiff, by path.
+	Diffs map[string]string
+}
+
+// Scaffold generates the project of template typ in baseDir/<name>.
+func Scaffold(baseDir, typ string, p *TemplateParams) (*ScaffoldResult, error) {
+	tpl, ok := Templates[typ]
+	if !ok {
+		return nil, fmt.Errorf("invalid template type %q, can be one of: %s", typ, keys(Templates))
+	}
+	params, err := p.withDefaults(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := render(typ, tpl, params)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ScaffoldResult{Dir: filepath.Join(baseDir, params.Name), Diffs: make(map[string]string)}
+	paths := make([]string, 0, len(files))
+	for name := range files {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+
+	for _, name := range paths {
+		content := files[name]
+		target := filepath.Join(res.Dir, filepath.FromSlash(name))
+		old, err := os.ReadFile(target)
+		switch {
+		case os.IsNotExist(err):
+			res.Written = append(res.Written, name)
+		case err != nil:
+			return nil, err
+		case bytes.Equal(old, content):
+			res.Unchanged = append(res.Unchanged, name)
+			continue
+		case params.Conflict == ConflictOverwrite:
+			res.Overwritten = append(res.Overwritten, name)
+		case params.Conflict == ConflictDiff:
+			res.Diffs[name] = unifiedDiff(name, string(old), string(content))
+			continue
+		default:
+			res.Skipped = append(res.Skipped, name)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// render returns the files of the project by their path in it.
+func render(typ string, tpl *ProjectTemplate, params *TemplateParams) (map[string][]byte, error) {
+	sources := map[string]string{"go.mod.tmpl": "templates/go.mod.tmpl"}
+	for _, name := range tpl.Shared {
+		sources[name] = "templates/shared/" + name
+	}
+	root := "templates/" + typ
+	err := walk(root, func(file string) {
+		sources[strings.TrimPrefix(file, root+"/")] = file
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &templateData{TemplateParams: params, Requires: requires(tpl, params), template: tpl}
+	files := make(map[string][]byte, len(sources))
+	for name, file := range sources {
+		content, err := templateFS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file: %w", err)
+		}
+		if strings.HasSuffix(name, ".tmpl") {
+			name = strings.TrimSuffix(name, ".tmpl")
+			if content, err = execute(file, content, data); err != nil {
+				return nil, err
+			}
+		}
+		if path.Ext(name) == ".go" {
+			formatted, err := format.Source(content)
+			if err != nil {
+				return nil, fmt.Errorf("template %s renders invalid go: %w", file, err)
+			}
+			content = formatted
+		}
+		files[name] = content
+	}
+	return files, nil
+}
+
+type templateData struct {
+	*TemplateParams
+	Requires []module
+
+	template *ProjectTemplate
+}
+
+func (d *templateData) UsesTools() bool {
+	return d.template.uses("tools")
+}
+
+func (d *templateData) UsesMemory() bool {
+	return d.template.uses("memory")
+}
+
+func (t *ProjectTemplate) uses(param string) bool {
+	for _, p := range t.Params {
+		if p == param {
+			return true
+		}
+	}
+	return false
+}
+
+type module struct {
+	Path    string
+	Version string
+}
+
+// requires are the modules the project imports directly.
+func requires(tpl *ProjectTemplate, params *TemplateParams) []module {
+	paths := map[string]bool{"github.com/cloudwego/eino": true}
+	for _, r := range tpl.Requires {
+		paths[r] = true
+	}
+	if tpl.uses("provider") {
+		paths[Providers[params.Provider]] = true
+	}
+	if tpl.uses("tools") {
+		for _, t := range params.Tools {
+			paths[Tools[t]] = true
+		}
+		if params.HasTool("mcp") {
+			paths["github.com/mark3labs/mcp-go"] = true
+		}
+	}
+	if tpl.uses("memory") {
+		paths[Memories[params.Memory]] = true
+	}
+	delete(paths, "")
+
+	mods := make([]module, 0, len(paths))
+	for p := range paths {
+		mods = append(mods, module{Path: p, Version: Versions[p]})
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Path < mods[j].Path })
+	return mods
+}
+
+func execute(file string, content []byte, data *templateData) ([]byte, error) {
+	t, err := template.New(path.Base(file)).Parse(string(content))
+	if err == nil {
+		// the sections the READMEs share
+		t, err = t.ParseFS(templateFS, "templates/partials.tmpl")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", file, err)
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", file, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// walk calls fn with every file under dir of templateFS.
+func walk(dir string, fn func(file string)) error {
+	entries, err := templateFS.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read template dir: %w", err)
+	}
+	for _, e := range entries {
+		file := dir + "/" + e.Name()
+		if e.IsDir() {
+			if err = walk(file, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		fn(file)
+	}
+	return nil
+}
+
+// ignoredParams are the params given that the template does not use.
+func ignoredParams(typ string, p *TemplateParams) []string {
+	tpl := Templates[typ]
+	if tpl == nil || p == nil {
+		return nil
+	}
+	given := map[string]bool{"provider": p.Provider != "", "tools": len(p.Tools) > 0, "memory": p.Memory != ""}
+	for _, used := range tpl.Params {
+		delete(given, used)
+	}
+	var ignored []string
+	for name, ok := range given {
+		if ok {
+			ignored = append(ignored, name)
+		}
+	}
+	sort.Strings(ignored)
+	return ignored
+}
+
+func keys[V any](m map[string]V) string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
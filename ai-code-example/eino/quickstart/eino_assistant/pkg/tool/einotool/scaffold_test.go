@@ -0,0 +1,79 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package einotool
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Scaffold(t *testing.T) {
+	// every template renders, the go files are formatted by render
+	for typ := range Templates {
+		params := &TemplateParams{Tools: []string{"duckduckgo", "mcp", "current_time"}, Memory: "redis"}
+		if _, err := Scaffold(t.TempDir(), typ, params); err != nil {
+			t.Fatalf("template %s: %v", typ, err)
+		}
+	}
+
+	base := t.TempDir()
+	res, err := Scaffold(base, "simple_llm", &TemplateParams{Module: "example.com/demo", Provider: "openai"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(res.Written, ",") != "README.md,go.mod,main.go,model.go" {
+		t.Fatalf("written %v", res.Written)
+	}
+	goMod, _ := os.ReadFile(filepath.Join(base, "simple_llm", "go.mod"))
+	if !strings.Contains(string(goMod), "module example.com/demo") || !strings.Contains(string(goMod), "components/model/openai v") {
+		t.Fatalf("go.mod:\n%s", goMod)
+	}
+
+	mainGo := filepath.Join(base, "simple_llm", "main.go")
+	if err = os.WriteFile(mainGo, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		conflict ConflictPolicy
+		check    func(res *ScaffoldResult) bool
+		kept     bool
+	}{
+		{ConflictSkip, func(res *ScaffoldResult) bool { return len(res.Skipped) == 1 }, true},
+		{ConflictDiff, func(res *ScaffoldResult) bool { return strings.Contains(res.Diffs["main.go"], "\n+import (\n") }, true},
+		{ConflictOverwrite, func(res *ScaffoldResult) bool { return len(res.Overwritten) == 1 }, false},
+	} {
+		res, err := Scaffold(base, "simple_llm", &TemplateParams{Module: "example.com/demo", Provider: "openai", Conflict: c.conflict})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !c.check(res) || len(res.Unchanged) != 3 || len(res.Written) != 0 {
+			t.Fatalf("%s: %+v", c.conflict, res)
+		}
+		content, _ := os.ReadFile(mainGo)
+		if kept := string(content) == "package main\n"; kept != c.kept {
+			t.Fatalf("%s: main.go kept=%v", c.conflict, kept)
+		}
+	}
+
+	for _, params := range []*TemplateParams{{Name: "../demo"}, {Provider: "x"}, {Tools: []string{"x"}}, {Conflict: "x"}} {
+		if _, err := Scaffold(base, "simple_llm", params); err == nil {
+			t.Fatalf("params %+v: expected an error", params)
+		}
+	}
+}
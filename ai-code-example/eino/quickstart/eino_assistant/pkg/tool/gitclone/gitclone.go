@@ -26,6 +26,9 @@ import (
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
+	configpkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
 )
 
 type GitCloneFileImpl struct {
@@ -38,7 +41,7 @@ type GitCloneFileConfig struct {
 
 func defaultGitCloneFileConfig(ctx context.Context) (*GitCloneFileConfig, error) {
 	config := &GitCloneFileConfig{
-		BaseDir: "./data/repos",
+		BaseDir: configpkg.Get().Data.ReposDir,
 	}
 	return config, nil
 }
@@ -62,7 +65,12 @@ func NewGitCloneFile(ctx context.Context, config *GitCloneFileConfig) (tn tool.B
 }
 
 func (g *GitCloneFileImpl) ToEinoTool() (tool.BaseTool, error) {
-	return utils.InferTool("gitclone", "git clone or pull a repository", g.Invoke)
+	it, err := utils.InferTool("gitclone", "git clone or pull a repository", g.Invoke)
+	if err != nil {
+		return nil, err
+	}
+	// cloning downloads and pulling changes the files under the base dir
+	return approval.Declare(it, func(string) approval.Risk { return approval.RiskHigh }), nil
 }
 
 func (g *GitCloneFileImpl) Invoke(ctx context.Context, req *GitCloneRequest) (res *GitCloneResponse, err error) {
@@ -174,8 +182,8 @@ const (
 )
 
 type GitCloneRequest struct {
-	Url    string         `json:"url" jsonschema:"description=The URL of the repository to clone"`
-	Action GitCloneAction `json:"action" jsonschema:"description=The action to perform, 'clone' or 'pull'"`
+	Url    string         `json:"url" jsonschema_description:"The URL of the repository to clone"`
+	Action GitCloneAction `json:"action" jsonschema_description:"The action to perform, 'clone' or 'pull'"`
 }
 
 type GitCloneResponse struct {
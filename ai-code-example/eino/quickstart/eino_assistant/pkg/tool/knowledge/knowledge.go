@@ -0,0 +1,103 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package knowledge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+
+	redispkg "github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/redis"
+)
+
+const desc = `search the eino knowledge base, the documents can be filtered by metadata.
+filter syntax: ` + redispkg.FilterSyntax
+
+type KnowledgeToolImpl struct {
+	config *KnowledgeToolConfig
+}
+
+type KnowledgeToolConfig struct {
+	Retriever retriever.Retriever
+	TopK      int
+}
+
+func NewKnowledgeTool(ctx context.Context, config *KnowledgeToolConfig) (tn tool.BaseTool, err error) {
+	if config == nil || config.Retriever == nil {
+		return nil, fmt.Errorf("retriever cannot be empty")
+	}
+	if config.TopK <= 0 {
+		config.TopK = 5
+	}
+	t := &KnowledgeToolImpl{config: config}
+	tn, err = t.ToEinoTool()
+	if err != nil {
+		return nil, err
+	}
+	return tn, nil
+}
+
+func (k *KnowledgeToolImpl) ToEinoTool() (tool.BaseTool, error) {
+	return utils.InferTool("knowledge_search", desc, k.Invoke)
+}
+
+func (k *KnowledgeToolImpl) Invoke(ctx context.Context, req *KnowledgeRequest) (res *KnowledgeResponse, err error) {
+	res = &KnowledgeResponse{}
+
+	if req.Query == "" {
+		res.Error = "query is required"
+		return res, nil
+	}
+
+	topK := k.config.TopK
+	if req.TopK > 0 {
+		topK = req.TopK
+	}
+	opts := []retriever.Option{retriever.WithTopK(topK)}
+	if req.Filter != "" {
+		opt, err := redispkg.FilterOption(req.Filter)
+		if err != nil {
+			res.Error = err.Error()
+			return res, nil
+		}
+		opts = append(opts, opt)
+	}
+
+	docs, err := k.config.Retriever.Retrieve(ctx, req.Query, opts...)
+	if err != nil {
+		res.Error = fmt.Sprintf("failed to search knowledge: %v", err)
+		return res, nil
+	}
+
+	res.Documents = docs
+	return res, nil
+}
+
+type KnowledgeRequest struct {
+	Query  string `json:"query" jsonschema_description:"The query to search"`
+	Filter string `json:"filter,omitempty" jsonschema_description:"Optional metadata filter expression, e.g. doc_type:graph language:zh"`
+	TopK   int    `json:"top_k,omitempty" jsonschema_description:"Max number of documents to return"`
+}
+
+type KnowledgeResponse struct {
+	Documents []*schema.Document `json:"documents" jsonschema_description:"The matched documents with metadata"`
+	Error     string             `json:"error" jsonschema_description:"The error of the response"`
+}
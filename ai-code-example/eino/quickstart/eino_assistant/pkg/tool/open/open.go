@@ -25,6 +25,8 @@ import (
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
 )
 
 type OpenFileToolImpl struct {
@@ -55,7 +57,12 @@ func NewOpenFileTool(ctx context.Context, config *OpenFileToolConfig) (tn tool.B
 }
 
 func (of *OpenFileToolImpl) ToEinoTool() (tool.InvokableTool, error) {
-	return utils.InferTool("open", "open a file/dir/web url in the system by default application", of.Invoke)
+	it, err := utils.InferTool("open", "open a file/dir/web url in the system by default application", of.Invoke)
+	if err != nil {
+		return nil, err
+	}
+	// the default application may run the file
+	return approval.Declare(it, func(string) approval.Risk { return approval.RiskMedium }), nil
 }
 
 func (of *OpenFileToolImpl) Invoke(ctx context.Context, req OpenReq) (res OpenRes, err error) {
@@ -83,11 +90,11 @@ func (of *OpenFileToolImpl) Invoke(ctx context.Context, req OpenReq) (res OpenRe
 }
 
 type OpenReq struct {
-	URI string `json:"uri" jsonschema:"description=The uri of the file/dir/web url to open"`
+	URI string `json:"uri" jsonschema_description:"The uri of the file/dir/web url to open"`
 }
 
 type OpenRes struct {
-	Message string `json:"message" jsonschema:"description=The message of the operation"`
+	Message string `json:"message" jsonschema_description:"The message of the operation"`
 }
 
 func isFilePath(path string) bool {
@@ -26,6 +26,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/config"
 )
 
 var defaultStorage *Storage
@@ -39,7 +41,7 @@ type Storage struct {
 
 func GetDefaultStorage() *Storage {
 	if defaultStorage == nil {
-		InitDefaultStorage("./data/task")
+		InitDefaultStorage(config.Get().Data.TaskDir)
 	}
 	return defaultStorage
 }
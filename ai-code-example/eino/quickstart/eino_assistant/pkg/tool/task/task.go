@@ -18,11 +18,14 @@ package task
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
 	"github.com/google/uuid"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/approval"
 )
 
 type Action string
@@ -36,34 +39,34 @@ const (
 )
 
 type Task struct {
-	ID        string `json:"id" jsonschema:"description:id of the task"`
-	Title     string `json:"title" jsonschema:"description:title of the task"`
-	Content   string `json:"content" jsonschema:"description:content of the task"`
-	Completed bool   `json:"completed" jsonschema:"description:completed status of the task"`
-	Deadline  string `json:"deadline" jsonschema:"description:deadline of the task"`
+	ID        string `json:"id" jsonschema_description:"id of the task"`
+	Title     string `json:"title" jsonschema_description:"title of the task"`
+	Content   string `json:"content" jsonschema_description:"content of the task"`
+	Completed bool   `json:"completed" jsonschema_description:"completed status of the task"`
+	Deadline  string `json:"deadline" jsonschema_description:"deadline of the task"`
 	IsDeleted bool   `json:"is_deleted" jsonschema:"-"`
 
-	CreatedAt string `json:"created_at" jsonschema:"description:created time of the task"`
+	CreatedAt string `json:"created_at" jsonschema_description:"created time of the task"`
 }
 
 type TaskRequest struct {
-	Action Action      `json:"action" jsonschema:"description:action to perform, enum:add,update,delete,list"`
-	Task   *Task       `json:"task" jsonschema:"description:task to add, update, or delete"`
-	List   *ListParams `json:"list" jsonschema:"description:list parameters"`
+	Action Action      `json:"action" jsonschema:"enum=add,enum=update,enum=delete,enum=list" jsonschema_description:"action to perform"`
+	Task   *Task       `json:"task" jsonschema_description:"task to add, update, or delete"`
+	List   *ListParams `json:"list" jsonschema_description:"list parameters"`
 }
 
 type ListParams struct {
-	Query  string `json:"query" jsonschema:"description:query to search"`
-	IsDone *bool  `json:"is_done" jsonschema:"description:filter by completed status"`
-	Limit  *int   `json:"limit" jsonschema:"description:limit the number of results"`
+	Query  string `json:"query" jsonschema_description:"query to search"`
+	IsDone *bool  `json:"is_done" jsonschema_description:"filter by completed status"`
+	Limit  *int   `json:"limit" jsonschema_description:"limit the number of results"`
 }
 
 type TaskResponse struct {
-	Status string `json:"status" jsonschema:"description:status of the response"`
+	Status string `json:"status" jsonschema_description:"status of the response"`
 
-	TaskList []*Task `json:"task_list" jsonschema:"description:list of tasks"`
+	TaskList []*Task `json:"task_list" jsonschema_description:"list of tasks"`
 
-	Error string `json:"error" jsonschema:"description:error message"`
+	Error string `json:"error" jsonschema_description:"error message"`
 }
 
 type TaskToolImpl struct {
@@ -120,7 +123,20 @@ func NewTaskTool(ctx context.Context, config *TaskToolConfig) (tn tool.BaseTool,
 }
 
 func (t *TaskToolImpl) ToEinoTool() (tool.BaseTool, error) {
-	return utils.InferTool("task_manager", "task manager tool, you can add, get, update, delete, list tasks", t.Invoke)
+	it, err := utils.InferTool("task_manager", "task manager tool, you can add, get, update, delete, list tasks", t.Invoke)
+	if err != nil {
+		return nil, err
+	}
+	return approval.Declare(it, t.Risk), nil
+}
+
+// Risk is high for delete, which can not be undone.
+func (t *TaskToolImpl) Risk(argumentsInJSON string) approval.Risk {
+	var req TaskRequest
+	if err := json.Unmarshal([]byte(argumentsInJSON), &req); err != nil || req.Action == ActionDelete {
+		return approval.RiskHigh
+	}
+	return approval.RiskLow
 }
 
 func (t *TaskToolImpl) Invoke(ctx context.Context, req *TaskRequest) (res *TaskResponse, err error) {
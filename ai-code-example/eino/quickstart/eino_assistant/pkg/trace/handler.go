@@ -0,0 +1,180 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+type spanKey struct{}
+
+type span struct {
+	id    string
+	start time.Time
+}
+
+// Handler records the runs of all components and nodes, pass it with compose.WithCallbacks.
+func (s *Store) Handler() callbacks.Handler {
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+			ctx, e := s.start(ctx, info)
+			e.Input = s.payload(input)
+			s.Add(e)
+			return ctx
+		}).
+		OnStartWithStreamInputFn(func(ctx context.Context, info *callbacks.RunInfo, input *schema.StreamReader[callbacks.CallbackInput]) context.Context {
+			input.Close()
+			ctx, e := s.start(ctx, info)
+			e.Stream = true
+			s.Add(e)
+			return ctx
+		}).
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			e := s.end(ctx, info, PhaseEnd)
+			e.Output = s.payload(output)
+			s.Add(e)
+			return ctx
+		}).
+		OnEndWithStreamOutputFn(func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+			go func() {
+				defer output.Close()
+				var chunks []callbacks.CallbackOutput
+				var err error
+				for {
+					var chunk callbacks.CallbackOutput
+					chunk, err = output.Recv()
+					if err != nil {
+						break
+					}
+					if s.config.Detail {
+						chunks = append(chunks, chunk)
+					}
+				}
+
+				phase := PhaseEnd
+				if !errors.Is(err, io.EOF) {
+					phase = PhaseError
+				}
+				e := s.end(ctx, info, phase)
+				e.Stream = true
+				e.Output = s.payload(concatChunks(chunks))
+				if phase == PhaseError {
+					e.Error = err.Error()
+				}
+				s.Add(e)
+			}()
+			return ctx
+		}).
+		OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+			e := s.end(ctx, info, PhaseError)
+			e.Error = err.Error()
+			s.Add(e)
+			return ctx
+		}).
+		Build()
+}
+
+func (s *Store) start(ctx context.Context, info *callbacks.RunInfo) (context.Context, *Event) {
+	e := s.newEvent(ctx, info, PhaseStart)
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		e.ParentID = parent.id
+	}
+	sp := &span{id: s.newSpanID(), start: e.Time}
+	e.SpanID = sp.id
+	return context.WithValue(ctx, spanKey{}, sp), e
+}
+
+func (s *Store) end(ctx context.Context, info *callbacks.RunInfo, phase Phase) *Event {
+	e := s.newEvent(ctx, info, phase)
+	if sp, ok := ctx.Value(spanKey{}).(*span); ok {
+		e.SpanID = sp.id
+		e.Duration = float64(e.Time.Sub(sp.start).Microseconds()) / 1000
+	}
+	return e
+}
+
+func (s *Store) newEvent(ctx context.Context, info *callbacks.RunInfo, phase Phase) *Event {
+	runID, conversationID := RunFromContext(ctx)
+	e := &Event{
+		Time:           time.Now(),
+		RunID:          runID,
+		ConversationID: conversationID,
+		Phase:          phase,
+	}
+	if info != nil {
+		e.Name, e.Component, e.Type = info.Name, string(info.Component), info.Type
+	}
+	return e
+}
+
+// payload marshals v if details are recorded, a too long json is kept as a truncated string.
+func (s *Store) payload(v any) json.RawMessage {
+	if !s.config.Detail || v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal("unable to marshal: " + err.Error())
+		return b
+	}
+	if len(b) > s.config.MaxPayload {
+		b, _ = json.Marshal(string(b[:s.config.MaxPayload]) + "...(truncated)")
+	}
+	return b
+}
+
+// concatChunks joins streamed messages into one, other outputs are kept as a list.
+func concatChunks(chunks []callbacks.CallbackOutput) any {
+	if len(chunks) == 0 {
+		return nil
+	}
+	var (
+		msgs  []*schema.Message
+		usage *model.TokenUsage
+	)
+	for _, chunk := range chunks {
+		switch out := chunk.(type) {
+		case *model.CallbackOutput:
+			if out.Message != nil {
+				msgs = append(msgs, out.Message)
+			}
+			if out.TokenUsage != nil {
+				usage = out.TokenUsage
+			}
+		case *schema.Message:
+			msgs = append(msgs, out)
+		default:
+			return chunks
+		}
+	}
+	msg, err := schema.ConcatMessages(msgs)
+	if err != nil {
+		return chunks
+	}
+	if _, ok := chunks[0].(*schema.Message); ok {
+		return msg
+	}
+	return &model.CallbackOutput{Message: msg, TokenUsage: usage}
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+type Config struct {
+	// Dir is where trace.jsonl and its rotated backups trace.jsonl.1 ... are written, default log.
+	Dir string
+	// MaxFileSize rotates the file when it would grow past it, default 10MB.
+	MaxFileSize int64
+	// MaxBackups is the number of rotated files kept, default 3.
+	MaxBackups int
+	// BufferSize is the number of recent events kept in memory for queries, default 10000.
+	BufferSize int
+	// MaxPayload truncates the json of inputs and outputs, default 64KB.
+	MaxPayload int
+	// Detail records the inputs and outputs of the components.
+	Detail bool
+	// Echo also prints every event to the standard logger.
+	Echo bool
+}
+
+// Store keeps the recent events in a ring buffer for queries and live subscribers,
+// and appends all of them to a rotating jsonl file.
+type Store struct {
+	config *Config
+
+	mu     sync.Mutex
+	seq    int64
+	ring   []*Event
+	next   int
+	file   *os.File
+	size   int64
+	subs   map[*subscriber]struct{}
+	spanID int64
+}
+
+type subscriber struct {
+	runID string
+	ch    chan *Event
+}
+
+// Query selects events by conversation or run id, an empty field matches all.
+type Query struct {
+	ConversationID string
+	RunID          string
+	// AfterSeq only returns events with a larger sequence number.
+	AfterSeq int64
+	// Limit returns the latest events only, default 1000.
+	Limit int
+}
+
+func NewStore(config *Config) (*Store, error) {
+	if config == nil {
+		config = &Config{Detail: true}
+	}
+	if config.Dir == "" {
+		config.Dir = "log"
+	}
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = 10 << 20
+	}
+	if config.MaxBackups <= 0 {
+		config.MaxBackups = 3
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 10000
+	}
+	if config.MaxPayload <= 0 {
+		config.MaxPayload = 64 << 10
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trace dir: %w", err)
+	}
+
+	s := &Store{
+		config: config,
+		ring:   make([]*Event, config.BufferSize),
+		subs:   make(map[*subscriber]struct{}),
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) path() string {
+	return filepath.Join(s.config.Dir, "trace.jsonl")
+}
+
+func (s *Store) open() error {
+	f, err := os.OpenFile(s.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat trace file: %w", err)
+	}
+	s.file, s.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts trace.jsonl.N to N+1, dropping the oldest, and starts a new file.
+func (s *Store) rotate() error {
+	s.file.Close()
+	os.Remove(s.path() + "." + strconv.Itoa(s.config.MaxBackups))
+	for i := s.config.MaxBackups - 1; i > 0; i-- {
+		os.Rename(s.path()+"."+strconv.Itoa(i), s.path()+"."+strconv.Itoa(i+1))
+	}
+	if err := os.Rename(s.path(), s.path()+".1"); err != nil {
+		return fmt.Errorf("failed to rotate trace file: %w", err)
+	}
+	return s.open()
+}
+
+func (s *Store) newSpanID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spanID++
+	return strconv.FormatInt(s.spanID, 36)
+}
+
+// Add records e, subscribers which cannot keep up miss events instead of blocking the graph.
+func (s *Store) Add(e *Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	e.Seq = s.seq
+	s.ring[s.next] = e
+	s.next = (s.next + 1) % len(s.ring)
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("[trace] failed to marshal event: %v", err)
+		return
+	}
+	line = append(line, '\n')
+	if s.file != nil {
+		if s.size > 0 && s.size+int64(len(line)) > s.config.MaxFileSize {
+			if err = s.rotate(); err != nil {
+				log.Printf("[trace] %v", err)
+			}
+		}
+		if s.file != nil {
+			n, _ := s.file.Write(line)
+			s.size += int64(n)
+		}
+	}
+	if s.config.Echo {
+		log.Printf("[trace] %s", line[:len(line)-1])
+	}
+
+	for sub := range s.subs {
+		if sub.runID != "" && sub.runID != e.RunID {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
+
+// Query returns the matching events of the ring buffer in order.
+func (s *Store) Query(q *Query) []*Event {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*Event
+	for i := range s.ring {
+		e := s.ring[(s.next+i)%len(s.ring)]
+		if e == nil || e.Seq <= q.AfterSeq ||
+			(q.ConversationID != "" && e.ConversationID != q.ConversationID) ||
+			(q.RunID != "" && e.RunID != q.RunID) {
+			continue
+		}
+		events = append(events, e)
+	}
+	if len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events
+}
+
+// Subscribe receives the events added from now on, of runID or of all runs if it is empty.
+func (s *Store) Subscribe(runID string) (events <-chan *Event, unsubscribe func()) {
+	sub := &subscriber{runID: runID, ch: make(chan *Event, 256)}
+	s.mu.Lock()
+	s.subs[sub] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, sub)
+			s.mu.Unlock()
+		})
+	}
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+)
+
+// go test -v -run Test_Store ./pkg/trace
+func Test_Store(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(&Config{Dir: dir, MaxFileSize: 512, MaxBackups: 2, BufferSize: 4, Detail: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	live, unsubscribe := s.Subscribe("run_1")
+	defer unsubscribe()
+
+	h := s.Handler()
+	info := &callbacks.RunInfo{Name: "ChatModel", Type: "Fake", Component: components.ComponentOfChatModel}
+	ctx := WithRun(context.Background(), "run_1", "conv_1")
+	ctx = h.OnStart(ctx, info, "hello")
+	h.OnError(ctx, info, errors.New("boom"))
+	h.OnEnd(WithRun(context.Background(), "run_2", "conv_1"), info, "other run")
+
+	events := s.Query(&Query{RunID: "run_1"})
+	if len(events) != 2 || events[0].Phase != PhaseStart || events[1].Phase != PhaseError || events[1].SpanID != events[0].SpanID {
+		t.Fatalf("events: %+v", events)
+	}
+	if events := s.Query(&Query{ConversationID: "conv_1"}); len(events) != 3 {
+		t.Fatalf("conversation events: %d", len(events))
+	}
+	if e := <-live; e.Seq != 1 {
+		t.Fatalf("live: %+v", e)
+	}
+
+	// the ring keeps the latest events and the file rotates
+	for i := 0; i < 20; i++ {
+		h.OnStart(ctx, info, "hello")
+	}
+	if events := s.Query(&Query{}); len(events) != 4 || events[3].Seq != 23 {
+		t.Fatalf("ring: %+v", events)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "trace.jsonl.2")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "trace.jsonl.3")); err == nil {
+		t.Fatal("too many backups")
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package trace records the callbacks of the graph as structured events.
+//
+// Every component run is a span with a start event and an end or error event, the span
+// of the enclosing run is its parent. Events carry the run and conversation ids set on the
+// context with WithRun, so the traces of a chat turn can be queried and followed live.
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type Phase string
+
+const (
+	PhaseStart Phase = "start"
+	PhaseEnd   Phase = "end"
+	PhaseError Phase = "error"
+)
+
+type Event struct {
+	Seq            int64     `json:"seq"`
+	Time           time.Time `json:"time"`
+	RunID          string    `json:"run_id,omitempty"`
+	ConversationID string    `json:"conversation_id,omitempty"`
+	SpanID         string    `json:"span_id"`
+	ParentID       string    `json:"parent_id,omitempty"`
+	Phase          Phase     `json:"phase"`
+	// Name is the node name, Component and Type describe the component, e.g. ChatModel / Ark.
+	Name      string `json:"name,omitempty"`
+	Component string `json:"component,omitempty"`
+	Type      string `json:"type,omitempty"`
+	// Duration is set on end and error events, for streamed output it lasts until the stream ends.
+	Duration float64         `json:"duration_ms,omitempty"`
+	Stream   bool            `json:"stream,omitempty"`
+	Input    json.RawMessage `json:"input,omitempty"`
+	Output   json.RawMessage `json:"output,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+type runKey struct{}
+
+type runInfo struct {
+	runID          string
+	conversationID string
+}
+
+// WithRun marks the callbacks under ctx as belonging to the run of a conversation.
+func WithRun(ctx context.Context, runID, conversationID string) context.Context {
+	return context.WithValue(ctx, runKey{}, &runInfo{runID: runID, conversationID: conversationID})
+}
+
+// RunFromContext returns the ids set by WithRun, empty if there are none.
+func RunFromContext(ctx context.Context) (runID, conversationID string) {
+	if info, ok := ctx.Value(runKey{}).(*runInfo); ok {
+		return info.runID, info.conversationID
+	}
+	return "", ""
+}
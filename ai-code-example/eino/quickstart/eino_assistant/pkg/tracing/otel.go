@@ -0,0 +1,204 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/trace"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/usage"
+)
+
+// NewOTelHandler maps every graph, node and component run to a span, nested by the
+// context. Chat models, embedders and tools carry the attributes of the OpenTelemetry
+// GenAI semantic conventions, other components eino.component / eino.type / eino.name.
+func NewOTelHandler(tracer oteltrace.Tracer) callbacks.Handler {
+	h := &otelHandler{tracer: tracer}
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(h.onStart).
+		OnStartWithStreamInputFn(func(ctx context.Context, info *callbacks.RunInfo, input *schema.StreamReader[callbacks.CallbackInput]) context.Context {
+			input.Close()
+			return h.onStart(ctx, info, nil)
+		}).
+		OnEndFn(h.onEnd).
+		OnEndWithStreamOutputFn(h.onEndWithStreamOutput).
+		OnErrorFn(h.onError).
+		Build()
+}
+
+type otelHandler struct {
+	tracer oteltrace.Tracer
+}
+
+func (h *otelHandler) onStart(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+	if info == nil {
+		return ctx
+	}
+	name := string(info.Component)
+	if info.Name != "" {
+		name += " " + info.Name
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("eino.component", string(info.Component)),
+		attribute.String("eino.type", info.Type),
+		attribute.String("eino.name", info.Name),
+	}
+	if runID, conversationID := trace.RunFromContext(ctx); runID != "" {
+		attrs = append(attrs, attribute.String("eino.run_id", runID))
+		if conversationID != "" {
+			attrs = append(attrs, attribute.String("gen_ai.conversation.id", conversationID))
+		}
+	}
+
+	switch info.Component {
+	case components.ComponentOfChatModel:
+		attrs = append(attrs,
+			attribute.String("gen_ai.operation.name", "chat"),
+			attribute.String("gen_ai.system", strings.ToLower(info.Type)))
+		if in := model.ConvCallbackInput(input); in != nil && in.Config != nil {
+			name = "chat " + in.Config.Model
+			attrs = append(attrs, attribute.String("gen_ai.request.model", in.Config.Model))
+			if in.Config.MaxTokens > 0 {
+				attrs = append(attrs, attribute.Int("gen_ai.request.max_tokens", in.Config.MaxTokens))
+			}
+			if in.Config.Temperature > 0 {
+				attrs = append(attrs, attribute.Float64("gen_ai.request.temperature", float64(in.Config.Temperature)))
+			}
+			if in.Config.TopP > 0 {
+				attrs = append(attrs, attribute.Float64("gen_ai.request.top_p", float64(in.Config.TopP)))
+			}
+		}
+	case components.ComponentOfEmbedding:
+		attrs = append(attrs,
+			attribute.String("gen_ai.operation.name", "embeddings"),
+			attribute.String("gen_ai.system", strings.ToLower(info.Type)))
+		if in := embedding.ConvCallbackInput(input); in != nil && in.Config != nil {
+			name = "embeddings " + in.Config.Model
+			attrs = append(attrs, attribute.String("gen_ai.request.model", in.Config.Model))
+		}
+	case components.ComponentOfTool:
+		name = "execute_tool " + info.Name
+		attrs = append(attrs,
+			attribute.String("gen_ai.operation.name", "execute_tool"),
+			attribute.String("gen_ai.tool.name", info.Name))
+		if in := tool.ConvCallbackInput(input); in != nil {
+			attrs = append(attrs, attribute.String("gen_ai.tool.call.arguments", in.ArgumentsInJSON))
+		}
+	}
+
+	ctx, _ = h.tracer.Start(ctx, name, oteltrace.WithAttributes(attrs...))
+	return ctx
+}
+
+func (h *otelHandler) onEnd(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+	span := oteltrace.SpanFromContext(ctx)
+	if info != nil {
+		span.SetAttributes(outputAttributes(info, output)...)
+	}
+	span.End()
+	return ctx
+}
+
+// onEndWithStreamOutput ends the span once the stream is read, the usage of a chat model
+// comes with the last chunks.
+func (h *otelHandler) onEndWithStreamOutput(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+	span := oteltrace.SpanFromContext(ctx)
+	go func() {
+		defer output.Close()
+		var (
+			last *model.CallbackOutput
+			msgs []*schema.Message
+		)
+		for {
+			chunk, err := output.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				break
+			}
+			if out := model.ConvCallbackOutput(chunk); out != nil {
+				if out.Message != nil {
+					msgs = append(msgs, out.Message)
+				}
+				if usage.Of(out) != nil {
+					last = out
+				}
+			}
+		}
+		if info != nil && info.Component == components.ComponentOfChatModel {
+			out := &model.CallbackOutput{}
+			if last != nil {
+				out.TokenUsage = usage.Of(last)
+			}
+			if msg, err := schema.ConcatMessages(msgs); err == nil {
+				out.Message = msg
+			}
+			span.SetAttributes(outputAttributes(info, out)...)
+		}
+		span.End()
+	}()
+	return ctx
+}
+
+func (h *otelHandler) onError(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+	span := oteltrace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.End()
+	return ctx
+}
+
+func outputAttributes(info *callbacks.RunInfo, output callbacks.CallbackOutput) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	switch info.Component {
+	case components.ComponentOfChatModel:
+		out := model.ConvCallbackOutput(output)
+		if out == nil {
+			return nil
+		}
+		if u := usage.Of(out); u != nil {
+			attrs = append(attrs,
+				attribute.Int("gen_ai.usage.input_tokens", u.PromptTokens),
+				attribute.Int("gen_ai.usage.output_tokens", u.CompletionTokens))
+		}
+		if out.Message != nil && out.Message.ResponseMeta != nil && out.Message.ResponseMeta.FinishReason != "" {
+			attrs = append(attrs, attribute.StringSlice("gen_ai.response.finish_reasons", []string{out.Message.ResponseMeta.FinishReason}))
+		}
+	case components.ComponentOfRetriever:
+		if out := retriever.ConvCallbackOutput(output); out != nil {
+			attrs = append(attrs, attribute.Int("eino.retriever.documents", len(out.Docs)))
+		}
+	}
+	return attrs
+}
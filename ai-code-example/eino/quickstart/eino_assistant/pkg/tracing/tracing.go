@@ -0,0 +1,191 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tracing sets up the global callback handler exporting traces to one of the
+// supported backends: Langfuse, CozeLoop, OpenTelemetry or none. The Config is part of
+// the assistant config, the env tags name the env vars overriding it.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/cloudwego/eino-ext/callbacks/langfuse"
+	"github.com/cloudwego/eino/callbacks"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+type Provider string
+
+const (
+	ProviderNone     Provider = "none"
+	ProviderLangfuse Provider = "langfuse"
+	ProviderCozeLoop Provider = "cozeloop"
+	ProviderOTel     Provider = "otel"
+)
+
+type Config struct {
+	Provider Provider `yaml:"provider" env:"TRACING_PROVIDER"`
+	// ServiceName is the service of the spans and the trace name of Langfuse, default eino-assistant.
+	ServiceName string         `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	Langfuse    LangfuseConfig `yaml:"langfuse"`
+	CozeLoop    CozeLoopConfig `yaml:"cozeloop"`
+	OTel        OTelConfig     `yaml:"otel"`
+}
+
+type LangfuseConfig struct {
+	// Host default https://cloud.langfuse.com
+	Host      string   `yaml:"host" env:"LANGFUSE_HOST"`
+	PublicKey string   `yaml:"public_key" env:"LANGFUSE_PUBLIC_KEY"`
+	SecretKey string   `yaml:"secret_key" env:"LANGFUSE_SECRET_KEY"`
+	Release   string   `yaml:"release" env:"LANGFUSE_RELEASE"`
+	UserID    string   `yaml:"user_id" env:"LANGFUSE_USER_ID"`
+	Tags      []string `yaml:"tags" env:"LANGFUSE_TAGS"`
+}
+
+// CozeLoopConfig reports to the OpenTelemetry endpoint of CozeLoop.
+type CozeLoopConfig struct {
+	WorkspaceID string `yaml:"workspace_id" env:"COZELOOP_WORKSPACE_ID"`
+	APIToken    string `yaml:"api_token" env:"COZELOOP_API_TOKEN"`
+	// Endpoint default https://api.coze.cn/v1/loop/opentelemetry/v1/traces
+	Endpoint string `yaml:"endpoint" env:"COZELOOP_OTLP_ENDPOINT"`
+}
+
+type OTelConfig struct {
+	// Endpoint is the OTLP/HTTP traces url, the OTEL_EXPORTER_OTLP_* env is used if it is empty.
+	Endpoint string            `yaml:"endpoint"`
+	Headers  map[string]string `yaml:"headers"`
+	// File writes the spans as json lines to a local file instead of exporting them.
+	File string `yaml:"file" env:"OTEL_TRACES_FILE"`
+}
+
+// Validate reports an unknown provider or the missing keys of the chosen one.
+func (c *Config) Validate() error {
+	switch c.Provider {
+	case "", ProviderNone, ProviderOTel:
+		return nil
+	case ProviderLangfuse:
+		if c.Langfuse.PublicKey == "" || c.Langfuse.SecretKey == "" {
+			return fmt.Errorf("tracing.langfuse.public_key (LANGFUSE_PUBLIC_KEY) and secret_key (LANGFUSE_SECRET_KEY) are required by langfuse")
+		}
+	case ProviderCozeLoop:
+		if c.CozeLoop.WorkspaceID == "" || c.CozeLoop.APIToken == "" {
+			return fmt.Errorf("tracing.cozeloop.workspace_id (COZELOOP_WORKSPACE_ID) and api_token (COZELOOP_API_TOKEN) are required by cozeloop")
+		}
+	default:
+		return fmt.Errorf("tracing.provider (TRACING_PROVIDER) %q is unknown, expect langfuse, cozeloop, otel or none", c.Provider)
+	}
+	return nil
+}
+
+// Init builds the handler of the configured provider, nil for none. shutdown flushes
+// the pending traces and must be called before the process exits.
+func Init(ctx context.Context, config *Config) (handler callbacks.Handler, shutdown func(context.Context) error, err error) {
+	if err = config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if config.ServiceName == "" {
+		config.ServiceName = "eino-assistant"
+	}
+	noop := func(context.Context) error { return nil }
+
+	switch config.Provider {
+	case "", ProviderNone:
+		return nil, noop, nil
+	case ProviderLangfuse:
+		c := config.Langfuse
+		if c.Host == "" {
+			c.Host = "https://cloud.langfuse.com"
+		}
+		log.Printf("[tracing] use langfuse, watch at: %s", c.Host)
+		handler, flush := langfuse.NewLangfuseHandler(&langfuse.Config{
+			Host:      c.Host,
+			PublicKey: c.PublicKey,
+			SecretKey: c.SecretKey,
+			Name:      config.ServiceName,
+			Release:   c.Release,
+			UserID:    c.UserID,
+			Tags:      c.Tags,
+		})
+		return handler, func(context.Context) error { flush(); return nil }, nil
+	case ProviderCozeLoop:
+		c := config.CozeLoop
+		if c.Endpoint == "" {
+			c.Endpoint = "https://api.coze.cn/v1/loop/opentelemetry/v1/traces"
+		}
+		log.Printf("[tracing] use cozeloop, workspace: %s", c.WorkspaceID)
+		return initOTel(ctx, config.ServiceName, &OTelConfig{
+			Endpoint: c.Endpoint,
+			Headers: map[string]string{
+				"Authorization":         "Bearer " + c.APIToken,
+				"cozeloop-workspace-id": c.WorkspaceID,
+			},
+		})
+	case ProviderOTel:
+		log.Printf("[tracing] use opentelemetry")
+		return initOTel(ctx, config.ServiceName, &config.OTel)
+	}
+	return nil, noop, nil
+}
+
+func initOTel(ctx context.Context, serviceName string, config *OTelConfig) (callbacks.Handler, func(context.Context) error, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		file     *os.File
+		err      error
+	)
+	if config.File != "" {
+		file, err = os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open traces file: %w", err)
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	} else {
+		var opts []otlptracehttp.Option
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(config.Endpoint))
+		}
+		if len(config.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	}
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return nil, nil, fmt.Errorf("failed to create span exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	shutdown := func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if file != nil {
+			file.Close()
+		}
+		return err
+	}
+	return NewOTelHandler(tp.Tracer("github.com/cloudwego/eino-examples/quickstart/eino_assistant")), shutdown, nil
+}
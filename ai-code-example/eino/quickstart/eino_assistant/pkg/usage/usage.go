@@ -0,0 +1,181 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package usage accounts the tokens used by the chat models of a turn, per node.
+package usage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	template "github.com/cloudwego/eino/utils/callbacks"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/metrics"
+)
+
+// Tokens counts the tokens of all turns of the process, kind is prompt or completion.
+var Tokens = metrics.Default.NewCounterVec("eino_assistant_tokens_total",
+	"Tokens used by chat models.", "node", "model", "kind")
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u *Usage) Add(o *Usage) {
+	if o == nil {
+		return
+	}
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.TotalTokens += o.TotalTokens
+}
+
+// Report is the usage of a turn or a conversation, in total and by node name.
+type Report struct {
+	Usage
+	Nodes map[string]*Usage `json:"nodes,omitempty"`
+}
+
+func (r *Report) Add(o *Report) {
+	if o == nil {
+		return
+	}
+	r.Usage.Add(&o.Usage)
+	for node, u := range o.Nodes {
+		r.addNode(node, u)
+	}
+}
+
+func (r *Report) addNode(node string, u *Usage) {
+	if r.Nodes == nil {
+		r.Nodes = make(map[string]*Usage)
+	}
+	if r.Nodes[node] == nil {
+		r.Nodes[node] = &Usage{}
+	}
+	r.Nodes[node].Add(u)
+}
+
+// Of returns the usage of a chat model output, falling back to the response meta,
+// components without callbacks of their own only report the message.
+func Of(output *model.CallbackOutput) *model.TokenUsage {
+	if output == nil {
+		return nil
+	}
+	if output.TokenUsage != nil {
+		return output.TokenUsage
+	}
+	if output.Message == nil || output.Message.ResponseMeta == nil || output.Message.ResponseMeta.Usage == nil {
+		return nil
+	}
+	u := output.Message.ResponseMeta.Usage
+	return &model.TokenUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+}
+
+// Recorder collects the usage of one turn, pass its Handler with compose.WithCallbacks.
+type Recorder struct {
+	mu      sync.Mutex
+	pending sync.WaitGroup
+	report  Report
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+type modelKey struct{}
+
+func (r *Recorder) Handler() callbacks.Handler {
+	return template.NewHandlerHelper().
+		ChatModel(&template.ModelCallbackHandler{
+			OnStart: func(ctx context.Context, info *callbacks.RunInfo, input *model.CallbackInput) context.Context {
+				if input != nil && input.Config != nil && input.Config.Model != "" {
+					return context.WithValue(ctx, modelKey{}, input.Config.Model)
+				}
+				return ctx
+			},
+			OnEnd: func(ctx context.Context, info *callbacks.RunInfo, output *model.CallbackOutput) context.Context {
+				r.add(ctx, info, Of(output))
+				return ctx
+			},
+			OnEndWithStreamOutput: func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[*model.CallbackOutput]) context.Context {
+				r.pending.Add(1)
+				go func() {
+					defer r.pending.Done()
+					defer output.Close()
+
+					// streamed usage is reported by the last chunks, only the last one counts
+					var usage *model.TokenUsage
+					for {
+						chunk, err := output.Recv()
+						if err != nil {
+							break
+						}
+						if u := Of(chunk); u != nil {
+							usage = u
+						}
+					}
+					r.add(ctx, info, usage)
+				}()
+				return ctx
+			},
+		}).
+		Handler()
+}
+
+func (r *Recorder) add(ctx context.Context, info *callbacks.RunInfo, tu *model.TokenUsage) {
+	if tu == nil {
+		return
+	}
+	node, modelName := info.Name, info.Type
+	if node == "" {
+		node = info.Type
+	}
+	if name, ok := ctx.Value(modelKey{}).(string); ok {
+		modelName = name
+	}
+	Tokens.Add(float64(tu.PromptTokens), node, modelName, "prompt")
+	Tokens.Add(float64(tu.CompletionTokens), node, modelName, "completion")
+
+	u := &Usage{
+		PromptTokens:     tu.PromptTokens,
+		CompletionTokens: tu.CompletionTokens,
+		TotalTokens:      tu.TotalTokens,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Usage.Add(u)
+	r.report.addNode(node, u)
+}
+
+// Report waits for the streamed model outputs to be read and returns the usage of the turn.
+func (r *Recorder) Report() *Report {
+	r.pending.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := &Report{}
+	report.Add(&r.report)
+	return report
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package usage_test
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/mem"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/usage"
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+func answer(content string, prompt, completion int) *fake.Response {
+	msg := schema.AssistantMessage(content, nil)
+	msg.ResponseMeta = &schema.ResponseMeta{
+		FinishReason: "stop",
+		Usage:        &schema.TokenUsage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion},
+	}
+	return &fake.Response{Message: msg}
+}
+
+// go test -v -run Test_TurnUsage ./pkg/usage
+func Test_TurnUsage(t *testing.T) {
+	ctx := context.Background()
+	// every turn calls the rewrite model, then the answer model
+	script := &fake.Script{Responses: []*fake.Response{
+		answer("query 1", 10, 2),
+		answer("answer 1", 100, 20),
+		answer("query 2", 30, 4),
+		answer("answer 2", 200, 40),
+	}}
+	cm := fake.NewChatModel(script)
+	chain := compose.NewChain[[]*schema.Message, *schema.Message]()
+	chain.AppendChatModel(cm, compose.WithNodeName("Rewrite")).
+		AppendLambda(compose.InvokableLambda(func(ctx context.Context, msg *schema.Message) ([]*schema.Message, error) {
+			return []*schema.Message{schema.UserMessage(msg.Content)}, nil
+		})).
+		AppendChatModel(cm, compose.WithNodeName("Answer"))
+	r, err := chain.Compile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	conversation := mem.NewSimpleMemory(mem.SimpleMemoryConfig{Dir: dir}).GetConversation("a", true)
+	input := []*schema.Message{schema.UserMessage("what is eino")}
+
+	// an invoked turn
+	recorder := usage.NewRecorder()
+	msg, err := r.Invoke(ctx, input, compose.WithCallbacks(recorder.Handler()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem.SetTurnUsage(msg, recorder.Report())
+	conversation.Append(msg)
+	want := &usage.Report{
+		Usage: usage.Usage{PromptTokens: 110, CompletionTokens: 22, TotalTokens: 132},
+		Nodes: map[string]*usage.Usage{
+			"Rewrite": {PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+			"Answer":  {PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120},
+		},
+	}
+	if got := mem.TurnUsage(msg); !reflect.DeepEqual(got, want) {
+		t.Fatalf("turn usage = %+v, want %+v", got, want)
+	}
+
+	// a streamed turn, its usage comes with the last chunk
+	recorder = usage.NewRecorder()
+	sr, err := r.Stream(ctx, input, compose.WithCallbacks(recorder.Handler()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunks []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	msg = mem.AnswerMessage(ctx, chunks, nil)
+	mem.SetTurnUsage(msg, recorder.Report())
+	conversation.Append(msg)
+	if got := mem.TurnUsage(msg); got.TotalTokens != 274 || got.Nodes["Answer"].CompletionTokens != 40 {
+		t.Fatalf("turn usage = %+v", got)
+	}
+
+	// the conversation sums its turns, also once loaded from its file
+	want = &usage.Report{
+		Usage: usage.Usage{PromptTokens: 340, CompletionTokens: 66, TotalTokens: 406},
+		Nodes: map[string]*usage.Usage{
+			"Rewrite": {PromptTokens: 40, CompletionTokens: 6, TotalTokens: 46},
+			"Answer":  {PromptTokens: 300, CompletionTokens: 60, TotalTokens: 360},
+		},
+	}
+	if got := conversation.Usage(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("conversation usage = %+v, want %+v", got, want)
+	}
+	loaded := mem.NewSimpleMemory(mem.SimpleMemoryConfig{Dir: dir}).GetConversation("a", false)
+	if got := loaded.Usage(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded conversation usage = %+v, want %+v", got, want)
+	}
+}
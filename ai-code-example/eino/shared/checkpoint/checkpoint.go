@@ -0,0 +1,287 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package checkpoint stores the checkpoints of agent runs in files or redis, so that a run can
+// be resumed by another process, e.g. after a crash or a restart.
+//
+// Eino saves a checkpoint when a run is interrupted only, so the tools of a run call Save before
+// they do anything: it interrupts the run once per tool call, the caller saves the checkpoint and
+// resumes the run at once, see IsStep. A run that crashes goes on from its last tool calls.
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	BackendFile  = "file"
+	BackendRedis = "redis"
+	// BackendOff disables checkpoints.
+	BackendOff = "off"
+)
+
+type Config struct {
+	// Backend is file, redis or off, default file.
+	Backend string `yaml:"backend" env:"CHECKPOINT"`
+	// Dir of the file backend, default ./data/checkpoints.
+	Dir string `yaml:"dir" env:"CHECKPOINT_DIR"`
+	// RedisAddr of the redis backend, default localhost:6379.
+	RedisAddr string `yaml:"redis_addr" env:"CHECKPOINT_REDIS_ADDR"`
+	// TTL of a checkpoint, zero keeps it until the run finishes.
+	TTL time.Duration `yaml:"ttl" env:"CHECKPOINT_TTL"`
+}
+
+func (c *Config) Validate() error {
+	switch c.Backend {
+	case "", BackendFile, BackendRedis, BackendOff:
+	default:
+		return fmt.Errorf("checkpoint.backend (CHECKPOINT): unknown backend %q, can be %s, %s or %s", c.Backend, BackendFile, BackendRedis, BackendOff)
+	}
+	if c.TTL < 0 {
+		return fmt.Errorf("checkpoint.ttl (CHECKPOINT_TTL) must not be negative, got %v", c.TTL)
+	}
+	return nil
+}
+
+// FromEnv reads CHECKPOINT, the backend, CHECKPOINT_DIR, CHECKPOINT_REDIS_ADDR and
+// CHECKPOINT_TTL, e.g. 72h.
+func FromEnv() (*Config, error) {
+	config := &Config{
+		Backend:   os.Getenv("CHECKPOINT"),
+		Dir:       os.Getenv("CHECKPOINT_DIR"),
+		RedisAddr: os.Getenv("CHECKPOINT_REDIS_ADDR"),
+	}
+	if ttl := os.Getenv("CHECKPOINT_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHECKPOINT_TTL: %w", err)
+		}
+		config.TTL = d
+	}
+	return config, nil
+}
+
+// Store is a compose.CheckPointStore whose checkpoints can be removed once their run finished.
+type Store interface {
+	compose.CheckPointStore
+	Delete(ctx context.Context, checkPointID string) error
+}
+
+// NewStore returns nil if the backend is off.
+func NewStore(config *Config) (Store, error) {
+	switch config.Backend {
+	case "", BackendFile:
+		dir := config.Dir
+		if dir == "" {
+			dir = "./data/checkpoints"
+		}
+		return NewFileStore(dir, config.TTL), nil
+	case BackendRedis:
+		addr := config.RedisAddr
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		return NewRedisStore(redis.NewClient(&redis.Options{Addr: addr}), config.TTL), nil
+	case BackendOff:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown checkpoint backend %q, can be %s, %s or %s", config.Backend, BackendFile, BackendRedis, BackendOff)
+	}
+}
+
+// FileStore stores one file per checkpoint id under dir.
+type FileStore struct {
+	dir string
+	ttl time.Duration
+}
+
+func NewFileStore(dir string, ttl time.Duration) *FileStore {
+	return &FileStore{dir: dir, ttl: ttl}
+}
+
+func (s *FileStore) Get(_ context.Context, checkPointID string) ([]byte, bool, error) {
+	path, err := s.path(checkPointID)
+	if err != nil {
+		return nil, false, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if s.ttl > 0 && time.Since(info.ModTime()) > s.ttl {
+		os.Remove(path)
+		return nil, false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Set writes to a temp file first, so that a crash never leaves half a checkpoint.
+func (s *FileStore) Set(_ context.Context, checkPointID string, checkPoint []byte) error {
+	path, err := s.path(checkPointID)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, checkPointID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(checkPoint); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (s *FileStore) Delete(_ context.Context, checkPointID string) error {
+	path, err := s.path(checkPointID)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path rejects the ids that are not a plain file name, e.g. ../x.
+func (s *FileStore) path(checkPointID string) (string, error) {
+	if checkPointID == "" || checkPointID != filepath.Base(checkPointID) || strings.HasPrefix(checkPointID, ".") {
+		return "", fmt.Errorf("invalid checkpoint id %q", checkPointID)
+	}
+	return filepath.Join(s.dir, checkPointID+".ckpt"), nil
+}
+
+// RedisStore stores the checkpoints as strings with the prefix checkpoint:.
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+const redisPrefix = "checkpoint:"
+
+func (s *RedisStore) Get(ctx context.Context, checkPointID string) ([]byte, bool, error) {
+	data, err := s.client.Get(ctx, redisPrefix+checkPointID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, checkPointID string, checkPoint []byte) error {
+	return s.client.Set(ctx, redisPrefix+checkPointID, checkPoint, s.ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, checkPointID string) error {
+	return s.client.Del(ctx, redisPrefix+checkPointID).Err()
+}
+
+func init() {
+	schema.RegisterName[*Step]("checkpoint_step")
+}
+
+// Step is the info of the interrupts of Save, the run goes on once its checkpoint is saved.
+type Step struct{}
+
+// IsStep reports whether every interrupt that caused an interruption came from Save, the caller
+// resumes such a run at once instead of waiting for the user.
+func IsStep(interrupts []*compose.InterruptCtx) bool {
+	steps := 0
+	for _, ic := range interrupts {
+		if !ic.IsRootCause {
+			continue
+		}
+		if _, ok := ic.Info.(*Step); !ok {
+			return false
+		}
+		steps++
+	}
+	return steps > 0
+}
+
+type stepsKey struct{}
+
+// WithSteps marks a run that has a checkpoint store and id, so that Save checkpoints it.
+func WithSteps(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stepsKey{}, true)
+}
+
+// Resumable reports whether the run of ctx saves checkpoints, only such a run can be interrupted
+// for the user, e.g. to approve a tool call.
+func Resumable(ctx context.Context) bool {
+	saving, _ := ctx.Value(stepsKey{}).(bool)
+	return saving
+}
+
+type stopKey struct{}
+
+// WithStop returns ctx for a run and the function that stops it: the next tool call of the
+// run interrupts it in Save, the runner then saves a checkpoint and the run ends.
+// Cancelling ctx instead would end the run without one.
+func WithStop(ctx context.Context) (context.Context, func()) {
+	stopped := &atomic.Bool{}
+	return context.WithValue(ctx, stopKey{}, stopped), func() { stopped.Store(true) }
+}
+
+// Save interrupts the run of ctx before a tool call, tools call it before they do anything.
+// The interrupt is a Step if the run saves checkpoints, see WithSteps, or ends the run if it was
+// stopped, see WithStop. A resumed call is not interrupted again, so the tool runs on resume.
+func Save(ctx context.Context) error {
+	if wasInterrupted, _, _ := compose.GetInterruptState[any](ctx); wasInterrupted {
+		return nil
+	}
+	if stopped, ok := ctx.Value(stopKey{}).(*atomic.Bool); ok && stopped.Load() {
+		return compose.Interrupt(ctx, "the run was stopped, resume it from its checkpoint")
+	}
+	if Resumable(ctx) {
+		return compose.Interrupt(ctx, &Step{})
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/redis/go-redis/v9"
+)
+
+// testStore runs the checks every backend has to pass.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	if _, ok, err := store.Get(ctx, "run-1"); ok || err != nil {
+		t.Fatalf("Get of a missing checkpoint = %v, %v, want false, nil", ok, err)
+	}
+	for _, data := range []string{"first", "second"} {
+		if err := store.Set(ctx, "run-1", []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		got, ok, err := store.Get(ctx, "run-1")
+		if err != nil || !ok || string(got) != data {
+			t.Fatalf("Get = %q, %v, %v, want %q", got, ok, err, data)
+		}
+	}
+	if err := store.Delete(ctx, "run-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := store.Get(ctx, "run-1"); ok {
+		t.Fatal("deleted checkpoint is still there")
+	}
+	if err := store.Delete(ctx, "run-1"); err != nil {
+		t.Fatalf("Delete of a missing checkpoint: %v", err)
+	}
+}
+
+// go test -v -run Test_FileStore ./checkpoint
+func Test_FileStore(t *testing.T) {
+	dir := t.TempDir()
+	testStore(t, NewFileStore(dir, 0))
+
+	store := NewFileStore(dir, 0)
+	for _, id := range []string{"", "../run", "a/b", ".hidden"} {
+		if err := store.Set(context.Background(), id, []byte("x")); err == nil {
+			t.Errorf("Set(%q) wrote outside of the store", id)
+		}
+	}
+	// Set leaves no temp file behind
+	if err := store.Set(context.Background(), "run-2", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 1 || filepath.Base(files[0]) != "run-2.ckpt" {
+		t.Fatalf("files in the store = %v, want run-2.ckpt only", files)
+	}
+}
+
+// go test -v -run Test_FileStoreTTL ./checkpoint
+func Test_FileStoreTTL(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewFileStore(dir, time.Hour)
+	if err := store.Set(ctx, "run-1", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := store.Get(ctx, "run-1"); !ok {
+		t.Fatal("fresh checkpoint expired")
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "run-1.ckpt"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := store.Get(ctx, "run-1"); ok || err != nil {
+		t.Fatalf("Get of an expired checkpoint = %v, %v, want false, nil", ok, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "run-1.ckpt")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expired checkpoint was not removed: %v", err)
+	}
+}
+
+// go test -v -run Test_RedisStore ./checkpoint
+func Test_RedisStore(t *testing.T) {
+	addr := os.Getenv("CHECKPOINT_REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("redis is unavailable: %v", err)
+	}
+
+	store := NewRedisStore(client, time.Minute)
+	defer store.Delete(ctx, "run-1")
+	testStore(t, store)
+
+	if err := store.Set(ctx, "run-1", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := client.TTL(ctx, redisPrefix+"run-1").Val(); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("ttl of the checkpoint = %v, want at most a minute", ttl)
+	}
+}
+
+type echoInput struct {
+	Text string `json:"text"`
+}
+
+// go test -v -run Test_SaveSteps ./checkpoint
+func Test_SaveSteps(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	echo, err := utils.InferTool("echo", "echo the text", func(ctx context.Context, in *echoInput) (string, error) {
+		if err := Save(ctx); err != nil {
+			return "", err
+		}
+		calls++
+		return in.Text, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: []tool.BaseTool{echo}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := compose.NewGraph[*schema.Message, []*schema.Message]()
+	_ = g.AddToolsNode("tools", tools)
+	_ = g.AddEdge(compose.START, "tools")
+	_ = g.AddEdge("tools", compose.END)
+	store := NewFileStore(t.TempDir(), 0)
+	r, err := g.Compile(ctx, compose.WithCheckPointStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := schema.AssistantMessage("", []schema.ToolCall{
+		{ID: "call-1", Function: schema.FunctionCall{Name: "echo", Arguments: `{"text":"hello"}`}},
+	})
+	_, err = r.Invoke(WithSteps(ctx), input, compose.WithCheckPointID("run-1"))
+	info, ok := compose.ExtractInterruptInfo(err)
+	if !ok || !IsStep(info.InterruptContexts) {
+		t.Fatalf("first run = %v, want a step interrupt", err)
+	}
+	if _, saved, _ := store.Get(ctx, "run-1"); !saved || calls != 0 {
+		t.Fatalf("checkpoint saved = %v, tool calls = %d, want a checkpoint before the call", saved, calls)
+	}
+
+	out, err := r.Invoke(WithSteps(ctx), input, compose.WithCheckPointID("run-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].Content != "hello" || calls != 1 {
+		t.Fatalf("resumed run = %v after %d calls, want hello after one call", out, calls)
+	}
+
+	// a run without checkpoints is not interrupted
+	if _, err = r.Invoke(ctx, input); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// go test -v -run Test_SaveStopped ./checkpoint
+func Test_SaveStopped(t *testing.T) {
+	ctx, stop := WithStop(context.Background())
+	if err := Save(ctx); err != nil {
+		t.Fatalf("Save of a running run = %v", err)
+	}
+	stop()
+	err := Save(ctx)
+	if _, ok := compose.IsInterruptRerunError(err); !ok {
+		t.Fatalf("Save of a stopped run = %v, want an interrupt", err)
+	}
+}
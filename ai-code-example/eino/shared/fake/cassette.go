@@ -0,0 +1,412 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fake
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+type CassetteMode string
+
+const (
+	// ModeRecord calls the wrapped component and writes what it returns.
+	ModeRecord CassetteMode = "record"
+	// ModeReplay serves recorded responses only, an unrecorded request is an error.
+	ModeReplay CassetteMode = "replay"
+)
+
+// Cassette stores model interactions under Dir, one json file per request:
+//
+//	chat/<hash>.json       the normalized request, the message and the streamed chunks
+//	embedding/<hash>.json  the model, the text and its vector
+//
+// The hash is computed from the normalized request, so whitespace around contents,
+// tool call ids and the key order of tool arguments do not change it.
+// Embeddings are keyed by model and text, vectors of different models never mix.
+// A nil *Cassette is valid and wraps nothing.
+type Cassette struct {
+	Dir  string
+	Mode CassetteMode
+}
+
+var ErrCassetteMiss = errors.New("no recorded response in cassette")
+
+func NewCassette(dir string, mode CassetteMode) (*Cassette, error) {
+	if mode != ModeRecord && mode != ModeReplay {
+		return nil, fmt.Errorf("invalid cassette mode %q, can be %s or %s", mode, ModeRecord, ModeReplay)
+	}
+	if dir == "" {
+		dir = "./testdata/cassettes"
+	}
+	return &Cassette{Dir: dir, Mode: mode}, nil
+}
+
+// Replaying reports whether the wrapped components are never called, so they need not be created.
+func (c *Cassette) Replaying() bool {
+	return c != nil && c.Mode == ModeReplay
+}
+
+// WrapChatModel records or replays cm, cm can be nil when replaying.
+func (c *Cassette) WrapChatModel(cm model.ToolCallingChatModel) (model.ToolCallingChatModel, error) {
+	if c == nil {
+		return cm, nil
+	}
+	if cm == nil && !c.Replaying() {
+		return nil, fmt.Errorf("cassette mode %s needs a chat model to record", c.Mode)
+	}
+	return &RecordingChatModel{cassette: c, cm: cm}, nil
+}
+
+// WrapEmbedder records or replays the vectors emb returns for modelName, emb can be nil when replaying.
+// The model set by embedding.WithModel takes precedence over modelName.
+func (c *Cassette) WrapEmbedder(emb embedding.Embedder, modelName string) (embedding.Embedder, error) {
+	if c == nil {
+		return emb, nil
+	}
+	if emb == nil && !c.Replaying() {
+		return nil, fmt.Errorf("cassette mode %s needs an embedder to record", c.Mode)
+	}
+	return &RecordingEmbedder{cassette: c, emb: emb, model: modelName}, nil
+}
+
+type chatRecord struct {
+	Request *chatRequest      `json:"request"`
+	Message *schema.Message   `json:"message"`
+	Chunks  []*schema.Message `json:"chunks,omitempty"`
+}
+
+type chatRequest struct {
+	Messages    []*requestMessage `json:"messages"`
+	Tools       []*requestTool    `json:"tools,omitempty"`
+	Model       string            `json:"model,omitempty"`
+	Temperature *float32          `json:"temperature,omitempty"`
+	TopP        *float32          `json:"top_p,omitempty"`
+	MaxTokens   *int              `json:"max_tokens,omitempty"`
+	Stop        []string          `json:"stop,omitempty"`
+	ToolChoice  string            `json:"tool_choice,omitempty"`
+}
+
+type requestMessage struct {
+	Role      schema.RoleType `json:"role"`
+	Content   string          `json:"content,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	ToolCalls []string        `json:"tool_calls,omitempty"`
+}
+
+type requestTool struct {
+	Name   string `json:"name"`
+	Desc   string `json:"desc,omitempty"`
+	Params any    `json:"params,omitempty"`
+}
+
+type embeddingRecord struct {
+	Model  string    `json:"model,omitempty"`
+	Text   string    `json:"text"`
+	Vector []float64 `json:"vector"`
+}
+
+// RecordingChatModel records the responses of the wrapped model, or replays them.
+type RecordingChatModel struct {
+	cassette *Cassette
+	cm       model.ToolCallingChatModel
+	tools    []*schema.ToolInfo
+}
+
+var _ model.ToolCallingChatModel = (*RecordingChatModel)(nil)
+
+func (r *RecordingChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	req, key, err := r.request(input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.cassette.Replaying() {
+		rec, err := r.load(key)
+		if err != nil {
+			return nil, err
+		}
+		if rec.Message == nil {
+			return schema.ConcatMessages(rec.Chunks)
+		}
+		return rec.Message, nil
+	}
+
+	msg, err := r.cm.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.cassette.save(filepath.Join("chat", key), &chatRecord{Request: req, Message: msg}); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Stream replays the recorded chunks, a response recorded by Generate is sent as one chunk.
+// When recording, chunks are forwarded as they arrive and written once the stream ends.
+func (r *RecordingChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	req, key, err := r.request(input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.cassette.Replaying() {
+		rec, err := r.load(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(rec.Chunks) == 0 {
+			return schema.StreamReaderFromArray([]*schema.Message{rec.Message}), nil
+		}
+		return schema.StreamReaderFromArray(rec.Chunks), nil
+	}
+
+	sr, err := r.cm.Stream(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sw.Close()
+		defer sr.Close()
+
+		var chunks []*schema.Message
+		for {
+			chunk, err := sr.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				sw.Send(nil, err)
+				return
+			}
+			chunks = append(chunks, chunk)
+			if sw.Send(chunk, nil) {
+				// the reader is closed, an incomplete stream is not recorded
+				return
+			}
+		}
+
+		msg, err := schema.ConcatMessages(chunks)
+		if err == nil {
+			err = r.cassette.save(filepath.Join("chat", key), &chatRecord{Request: req, Message: msg, Chunks: chunks})
+		}
+		if err != nil {
+			sw.Send(nil, err)
+		}
+	}()
+	return out, nil
+}
+
+func (r *RecordingChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	wrapped := &RecordingChatModel{cassette: r.cassette, tools: tools}
+	if r.cm != nil {
+		cm, err := r.cm.WithTools(tools)
+		if err != nil {
+			return nil, err
+		}
+		wrapped.cm = cm
+	}
+	return wrapped, nil
+}
+
+func (r *RecordingChatModel) GetType() string {
+	if r.cm != nil {
+		if typ, ok := components.GetType(r.cm); ok {
+			return "Recording" + typ
+		}
+	}
+	return "Recording"
+}
+
+func (r *RecordingChatModel) request(input []*schema.Message, opts ...model.Option) (*chatRequest, string, error) {
+	options := model.GetCommonOptions(&model.Options{Tools: r.tools}, opts...)
+	req := &chatRequest{
+		Temperature: options.Temperature,
+		TopP:        options.TopP,
+		MaxTokens:   options.MaxTokens,
+		Stop:        options.Stop,
+	}
+	if options.Model != nil {
+		req.Model = *options.Model
+	}
+	if options.ToolChoice != nil {
+		req.ToolChoice = string(*options.ToolChoice)
+	}
+
+	for _, m := range input {
+		rm := &requestMessage{
+			Role:    m.Role,
+			Content: strings.TrimSpace(m.Content),
+			Name:    m.Name,
+		}
+		for _, tc := range m.ToolCalls {
+			rm.ToolCalls = append(rm.ToolCalls, tc.Function.Name+"("+compactJSON(tc.Function.Arguments)+")")
+		}
+		req.Messages = append(req.Messages, rm)
+	}
+
+	for _, t := range options.Tools {
+		rt := &requestTool{Name: t.Name, Desc: t.Desc}
+		if t.ParamsOneOf != nil {
+			params, err := t.ParamsOneOf.ToJSONSchema()
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to convert params of tool %s: %w", t.Name, err)
+			}
+			rt.Params = params
+		}
+		req.Tools = append(req.Tools, rt)
+	}
+
+	key, err := hashJSON(req)
+	if err != nil {
+		return nil, "", err
+	}
+	return req, key, nil
+}
+
+func (r *RecordingChatModel) load(key string) (*chatRecord, error) {
+	rec := &chatRecord{}
+	if err := r.cassette.load(filepath.Join("chat", key), rec); err != nil {
+		return nil, err
+	}
+	if rec.Message == nil && len(rec.Chunks) == 0 {
+		return nil, fmt.Errorf("empty chat record %s", key)
+	}
+	return rec, nil
+}
+
+// RecordingEmbedder records vectors one text at a time, so replay does not depend on batching.
+type RecordingEmbedder struct {
+	cassette *Cassette
+	emb      embedding.Embedder
+	model    string
+}
+
+var _ embedding.Embedder = (*RecordingEmbedder)(nil)
+
+func (r *RecordingEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	modelName := r.model
+	modelName = *embedding.GetCommonOptions(&embedding.Options{Model: &modelName}, opts...).Model
+
+	if r.cassette.Replaying() {
+		vectors := make([][]float64, len(texts))
+		for i, text := range texts {
+			rec := &embeddingRecord{}
+			if err := r.cassette.load(embeddingKey(modelName, text), rec); err != nil {
+				return nil, err
+			}
+			vectors[i] = rec.Vector
+		}
+		return vectors, nil
+	}
+
+	vectors, err := r.emb.EmbedStrings(ctx, texts, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("invalid vector length, expected=%d, got=%d", len(texts), len(vectors))
+	}
+	for i, text := range texts {
+		if err = r.cassette.save(embeddingKey(modelName, text), &embeddingRecord{Model: modelName, Text: text, Vector: vectors[i]}); err != nil {
+			return nil, err
+		}
+	}
+	return vectors, nil
+}
+
+func (r *RecordingEmbedder) GetType() string {
+	if r.emb != nil {
+		if typ, ok := components.GetType(r.emb); ok {
+			return "Recording" + typ
+		}
+	}
+	return "Recording"
+}
+
+func (c *Cassette) load(name string, v any) error {
+	path := filepath.Join(c.Dir, name+".json")
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrCassetteMiss, path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to unmarshal cassette %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Cassette) save(name string, v any) error {
+	path := filepath.Join(c.Dir, name+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette dir: %w", err)
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err = os.WriteFile(path, b, 0644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+func hashJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// embeddingKey separates model and text by a NUL byte, so no pair of them hashes like another.
+func embeddingKey(modelName, text string) string {
+	sum := sha256.Sum256([]byte(modelName + "\x00" + text))
+	return filepath.Join("embedding", hex.EncodeToString(sum[:]))
+}
+
+// compactJSON removes insignificant whitespace and orders object keys, invalid json is kept as is.
+func compactJSON(s string) string {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return strings.TrimSpace(s)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+	return string(b)
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+var cloneTool = &schema.ToolInfo{
+	Name: "git_clone",
+	Desc: "clone a repository",
+	ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+		"url": {Type: schema.String, Required: true},
+	}),
+}
+
+// go test -v -run Test_CassetteRoundTrip ./fake
+func Test_CassetteRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	hello := []*schema.Message{schema.UserMessage("hi")}
+	clone := []*schema.Message{schema.UserMessage("please clone x")}
+
+	recorder, err := NewCassette(dir, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := recorder.WrapChatModel(NewChatModel(&Script{Responses: []*Response{
+		{Match: "hi", Message: schema.AssistantMessage("hello, what can I do for you?", nil)},
+		{Match: "clone", Message: schema.AssistantMessage("", []schema.ToolCall{{
+			ID:       "call_1",
+			Function: schema.FunctionCall{Name: "git_clone", Arguments: `{"url": "x"}`},
+		}})},
+	}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err = recorded.WithTools([]*schema.ToolInfo{cloneTool})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := recorded.Generate(ctx, hello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantChunks := readAll(t, recorded, clone)
+
+	replayer, err := NewCassette(dir, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := replayer.WrapChatModel(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withTools, err := replayed.WithTools([]*schema.ToolInfo{cloneTool})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := withTools.Generate(ctx, []*schema.Message{schema.UserMessage("  hi\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Content != want.Content {
+		t.Fatalf("replayed content: %q, want %q", got.Content, want.Content)
+	}
+
+	gotChunks := readAll(t, withTools, clone)
+	if len(gotChunks) != len(wantChunks) {
+		t.Fatalf("replayed %d chunks, want %d", len(gotChunks), len(wantChunks))
+	}
+	msg, err := schema.ConcatMessages(gotChunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.ToolCalls) != 1 || msg.ToolCalls[0].Function.Name != "git_clone" || msg.ToolCalls[0].Function.Arguments != `{"url": "x"}` {
+		t.Fatalf("replayed tool calls: %v", msg.ToolCalls)
+	}
+
+	// a response recorded by Generate streams as one chunk
+	if chunks := readAll(t, withTools, hello); len(chunks) != 1 || chunks[0].Content != want.Content {
+		t.Fatalf("replayed chunks: %v", chunks)
+	}
+
+	// the tools are part of the request
+	if _, err = replayed.Generate(ctx, hello); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("expected a cassette miss without tools, got %v", err)
+	}
+	if _, err = withTools.Stream(ctx, []*schema.Message{schema.UserMessage("bye")}); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("expected a cassette miss, got %v", err)
+	}
+}
+
+// go test -v -run Test_CassetteNeedsComponentToRecord ./fake
+func Test_CassetteNeedsComponentToRecord(t *testing.T) {
+	c, err := NewCassette(t.TempDir(), ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.WrapChatModel(nil); err == nil {
+		t.Fatal("expected an error for a nil chat model")
+	}
+	if _, err = c.WrapEmbedder(nil, "m"); err == nil {
+		t.Fatal("expected an error for a nil embedder")
+	}
+}
+
+// go test -v -run Test_CassetteEmbedding ./fake
+func Test_CassetteEmbedding(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	texts := []string{"eino graph", "redis 向量"}
+
+	recorder, err := NewCassette(dir, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := recorder.WrapEmbedder(NewEmbedder(8), "embedding-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := recorded.EmbedStrings(ctx, texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewCassette(dir, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := replayer.WrapEmbedder(nil, "embedding-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// recorded in one batch, replayed one text at a time
+	got, err := replayed.EmbedStrings(ctx, texts[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got[0], want[1]) {
+		t.Fatal("replayed vector differs from the recorded one")
+	}
+
+	if _, err = replayed.EmbedStrings(ctx, texts, embedding.WithModel("embedding-b")); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("expected a cassette miss for another model, got %v", err)
+	}
+	other, err := replayer.WrapEmbedder(nil, "embedding-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = other.EmbedStrings(ctx, texts); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("expected a cassette miss for another model, got %v", err)
+	}
+}
+
+func readAll(t *testing.T, cm model.ToolCallingChatModel, input []*schema.Message) []*schema.Message {
+	t.Helper()
+	sr, err := cm.Stream(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sr.Close()
+
+	var chunks []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			return chunks
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, chunk)
+	}
+}
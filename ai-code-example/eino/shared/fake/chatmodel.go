@@ -0,0 +1,190 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Script is the fixture replayed by ChatModel, loaded from a json file like:
+//
+//	{"responses": [
+//	  {"match": "clone", "message": {"role": "assistant", "tool_calls": [...]}},
+//	  {"message": {"role": "assistant", "content": "done"}}
+//	]}
+//
+// Each response is used once. The first unused response whose Match is contained
+// in the content of the last input message is returned, an empty Match matches anything.
+type Script struct {
+	Responses []*Response `json:"responses"`
+
+	mu   sync.Mutex
+	used []bool
+}
+
+type Response struct {
+	Match   string          `json:"match,omitempty"`
+	Message *schema.Message `json:"message"`
+}
+
+func LoadScript(path string) (*Script, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	s := &Script{}
+	if err = json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fixture %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *Script) next(input []*schema.Message) (*schema.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.used) != len(s.Responses) {
+		s.used = make([]bool, len(s.Responses))
+	}
+
+	var last string
+	if len(input) > 0 {
+		last = input[len(input)-1].Content
+	}
+	for i, resp := range s.Responses {
+		if s.used[i] || resp.Message == nil || !strings.Contains(last, resp.Match) {
+			continue
+		}
+		s.used[i] = true
+		msg := *resp.Message
+		if msg.Role == "" {
+			msg.Role = schema.Assistant
+		}
+		return &msg, nil
+	}
+	return nil, fmt.Errorf("fake chat model: no scripted response left for input %q", last)
+}
+
+// ChatModel replays a Script, without a script it echoes the last input message.
+// Copies created by WithTools share the script.
+type ChatModel struct {
+	script *Script
+	tools  []*schema.ToolInfo
+}
+
+var _ model.ToolCallingChatModel = (*ChatModel)(nil)
+
+func NewChatModel(script *Script) *ChatModel {
+	return &ChatModel{script: script}
+}
+
+func (c *ChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	var (
+		msg *schema.Message
+		err error
+	)
+	if c.script == nil {
+		msg = echo(input)
+	} else if msg, err = c.script.next(input); err != nil {
+		return nil, err
+	}
+
+	if msg.ResponseMeta == nil {
+		msg.ResponseMeta = &schema.ResponseMeta{
+			FinishReason: "stop",
+			Usage:        usage(input, msg),
+		}
+		if len(msg.ToolCalls) > 0 {
+			msg.ResponseMeta.FinishReason = "tool_calls"
+		}
+	}
+	return msg, nil
+}
+
+// Stream sends the reasoning and the content in small chunks, tool calls and response meta come with the last chunk.
+func (c *ChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := c.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []*schema.Message
+	reasoning := []rune(msg.ReasoningContent)
+	for i := 0; i < len(reasoning); i += 8 {
+		chunks = append(chunks, &schema.Message{
+			Role:             msg.Role,
+			ReasoningContent: string(reasoning[i:min(i+8, len(reasoning))]),
+		})
+	}
+	runes := []rune(msg.Content)
+	for i := 0; i < len(runes); i += 8 {
+		chunks = append(chunks, &schema.Message{
+			Role:    msg.Role,
+			Content: string(runes[i:min(i+8, len(runes))]),
+		})
+	}
+	toolCalls := make([]schema.ToolCall, len(msg.ToolCalls))
+	for i, tc := range msg.ToolCalls {
+		idx := i
+		tc.Index = &idx
+		toolCalls[i] = tc
+	}
+	chunks = append(chunks, &schema.Message{
+		Role:         msg.Role,
+		ToolCalls:    toolCalls,
+		ResponseMeta: msg.ResponseMeta,
+	})
+	return schema.StreamReaderFromArray(chunks), nil
+}
+
+func (c *ChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return &ChatModel{script: c.script, tools: tools}, nil
+}
+
+func (c *ChatModel) GetType() string {
+	return "Fake"
+}
+
+func echo(input []*schema.Message) *schema.Message {
+	var last string
+	if len(input) > 0 {
+		last = input[len(input)-1].Content
+	}
+	return schema.AssistantMessage("fake response: "+last, nil)
+}
+
+// usage counts words, which is good enough to test token accounting.
+func usage(input []*schema.Message, output *schema.Message) *schema.TokenUsage {
+	var prompt int
+	for _, m := range input {
+		prompt += len(tokenize(m.Content))
+	}
+	completion := len(tokenize(output.Content)) + len(tokenize(output.ReasoningContent))
+	return &schema.TokenUsage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+}
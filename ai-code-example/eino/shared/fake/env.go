@@ -0,0 +1,85 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fake has offline stand-ins for the chat models and embedders: a scripted chat model,
+// a deterministic embedder and cassettes that record real calls and replay them.
+//
+// It is in the shared module, which ai-code-example and eino_assistant both require with a
+// replace to the local directory, so the two modules use this one copy.
+package fake
+
+import (
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Enabled reports whether EINO_FAKE=true, which makes the model and embedding
+// constructors return the offline fakes of this package.
+func Enabled() bool {
+	return os.Getenv("EINO_FAKE") == "true"
+}
+
+// Offline reports whether no model provider is called, either fakes are enabled
+// or recorded cassettes are replayed, so the provider credentials are not required.
+func Offline() bool {
+	return Enabled() || CassetteMode(os.Getenv("FAKE_CASSETTE_MODE")) == ModeReplay
+}
+
+var (
+	scriptOnce sync.Once
+	script     *Script
+	scriptErr  error
+)
+
+// NewChatModelFromEnv replays the fixture of FAKE_CHAT_FIXTURE, or echoes if it is not set.
+// All models of a process share one script, so a fixture can describe a whole run.
+func NewChatModelFromEnv() (*ChatModel, error) {
+	scriptOnce.Do(func() {
+		if path := os.Getenv("FAKE_CHAT_FIXTURE"); path != "" {
+			script, scriptErr = LoadScript(path)
+		}
+	})
+	if scriptErr != nil {
+		return nil, scriptErr
+	}
+	return NewChatModel(script), nil
+}
+
+// NewEmbedderFromEnv uses FAKE_EMBEDDING_DIM as dimension, default 256.
+func NewEmbedderFromEnv() *Embedder {
+	dim, _ := strconv.Atoi(os.Getenv("FAKE_EMBEDDING_DIM"))
+	return NewEmbedder(dim)
+}
+
+// ModelName returns "fake" when fakes are enabled, so that indexes and caches
+// keyed by model name never mix fake vectors with real ones.
+func ModelName(name string) string {
+	if Enabled() {
+		return "fake"
+	}
+	return name
+}
+
+// NewCassetteFromEnv reads FAKE_CASSETTE_MODE (record or replay) and FAKE_CASSETTE_DIR,
+// it returns nil when the mode is not set.
+func NewCassetteFromEnv() (*Cassette, error) {
+	mode := os.Getenv("FAKE_CASSETTE_MODE")
+	if mode == "" {
+		return nil, nil
+	}
+	return NewCassette(os.Getenv("FAKE_CASSETTE_DIR"), CassetteMode(mode))
+}
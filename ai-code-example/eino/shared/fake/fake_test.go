@@ -0,0 +1,88 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fake
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// go test -v -run Test_Embedder ./fake
+func Test_Embedder(t *testing.T) {
+	emb := NewEmbedder(64)
+	vectors, err := emb.EmbedStrings(context.Background(), []string{"eino graph", "eino graph", "redis 向量"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors[0]) != 64 {
+		t.Fatalf("dimension: %d", len(vectors[0]))
+	}
+	if !slices.Equal(vectors[0], vectors[1]) || slices.Equal(vectors[0], vectors[2]) {
+		t.Fatal("vectors are not deterministic")
+	}
+}
+
+// go test -v -run Test_ChatModel ./fake
+func Test_ChatModel(t *testing.T) {
+	cm := NewChatModel(&Script{Responses: []*Response{
+		{Match: "clone", Message: schema.AssistantMessage("", []schema.ToolCall{{
+			ID:       "call_1",
+			Function: schema.FunctionCall{Name: "git_clone", Arguments: `{"url":"x"}`},
+		}})},
+		{Message: schema.AssistantMessage("done", nil)},
+	}})
+	ctx := context.Background()
+
+	msg, err := cm.Generate(ctx, []*schema.Message{schema.UserMessage("please clone x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.ToolCalls) != 1 || msg.ResponseMeta.FinishReason != "tool_calls" {
+		t.Fatalf("unexpected message: %v", msg)
+	}
+
+	sr, err := cm.Stream(ctx, []*schema.Message{schema.ToolMessage("ok", "call_1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunks []*schema.Message
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	msg, err = schema.ConcatMessages(chunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Content != "done" {
+		t.Fatalf("unexpected content: %q", msg.Content)
+	}
+
+	if _, err = cm.Generate(ctx, []*schema.Message{schema.UserMessage("again")}); err == nil {
+		t.Fatal("expected an error once the script is exhausted")
+	}
+}
@@ -0,0 +1,401 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	CacheFile  = "file"
+	CacheRedis = "redis"
+)
+
+// CacheConfig caches the responses of deterministic calls, those with temperature 0,
+// see NewCached. Other calls always reach the model.
+type CacheConfig struct {
+	// Backend is file or redis.
+	Backend string `yaml:"backend"`
+	// Dir of the file backend, default ./data/llm_cache.
+	Dir string `yaml:"dir"`
+	// RedisAddr of the redis backend, default localhost:6379.
+	RedisAddr string `yaml:"redis_addr"`
+	// TTL of a response, zero keeps it until it is removed.
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// NewCacheStore returns nil if config is nil.
+func NewCacheStore(config *CacheConfig) (CacheStore, error) {
+	if config == nil {
+		return nil, nil
+	}
+	switch config.Backend {
+	case CacheFile:
+		dir := config.Dir
+		if dir == "" {
+			dir = "./data/llm_cache"
+		}
+		return NewFileCache(dir, config.TTL), nil
+	case CacheRedis:
+		addr := config.RedisAddr
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		return NewRedisCache(redis.NewClient(&redis.Options{Addr: addr}), config.TTL), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q, can be %s or %s", config.Backend, CacheFile, CacheRedis)
+	}
+}
+
+// CacheStore stores the responses by key, the stores expire them after their TTL.
+type CacheStore interface {
+	// Get returns nil without error if key is not stored or expired.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// FileCache stores one file per key under dir, an expired file is removed when it is read.
+type FileCache struct {
+	dir string
+	ttl time.Duration
+}
+
+func NewFileCache(dir string, ttl time.Duration) *FileCache {
+	return &FileCache{dir: dir, ttl: ttl}
+}
+
+func (c *FileCache) Get(_ context.Context, key string) ([]byte, error) {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.ttl > 0 && time.Since(info.ModTime()) > c.ttl {
+		os.Remove(path)
+		return nil, nil
+	}
+	return os.ReadFile(path)
+}
+
+// Set writes to a temp file first, so that a concurrent Get never reads half a file.
+func (c *FileCache) Set(_ context.Context, key string, value []byte) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(value); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (c *FileCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// RedisCache stores the responses as strings with the prefix llm_cache:.
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisCache(client *redis.Client, ttl time.Duration) *RedisCache {
+	return &RedisCache{client: client, ttl: ttl}
+}
+
+const redisCachePrefix = "llm_cache:"
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, redisCachePrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte) error {
+	return c.client.Set(ctx, redisCachePrefix+key, value, c.ttl).Err()
+}
+
+type cacheOptions struct {
+	bypass bool
+}
+
+// WithCacheBypass is a call option that skips the cached response, the new response
+// replaces it.
+func WithCacheBypass() model.Option {
+	return model.WrapImplSpecificOptFn(func(o *cacheOptions) {
+		o.bypass = true
+	})
+}
+
+// cachedModel answers deterministic calls from store, see NewCached.
+type cachedModel struct {
+	cm          model.ToolCallingChatModel
+	store       CacheStore
+	id          string
+	temperature *float32
+	tools       []*schema.ToolInfo
+}
+
+var _ model.ToolCallingChatModel = (*cachedModel)(nil)
+
+// NewCached caches the responses of cm in store. A call is cached if its temperature,
+// the one of the call options or else temperature, is 0. The key is the hash of id, the
+// messages, the tools and the call options, id identifies the model and its config.
+// A cached response is streamed as one chunk, a stream is cached once it is read to its end.
+// The errors of store are logged, a call never fails because of the cache.
+func NewCached(cm model.ToolCallingChatModel, store CacheStore, id string, temperature *float32) model.ToolCallingChatModel {
+	return &cachedModel{cm: cm, store: store, id: id, temperature: temperature}
+}
+
+func (c *cachedModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	key, ok := c.key(input, opts...)
+	if !ok {
+		return c.cm.Generate(ctx, input, opts...)
+	}
+	if msg := c.get(ctx, key, opts...); msg != nil {
+		return msg, nil
+	}
+
+	msg, err := c.cm.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.set(ctx, key, msg)
+	return msg, nil
+}
+
+func (c *cachedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	key, ok := c.key(input, opts...)
+	if !ok {
+		return c.cm.Stream(ctx, input, opts...)
+	}
+	if msg := c.get(ctx, key, opts...); msg != nil {
+		return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+	}
+
+	sr, err := c.cm.Stream(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sw.Close()
+		defer sr.Close()
+
+		var chunks []*schema.Message
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if closed := sw.Send(chunk, err); closed || err != nil {
+				// an incomplete stream is not cached
+				return
+			}
+			chunks = append(chunks, chunk)
+		}
+		msg, err := schema.ConcatMessages(chunks)
+		if err != nil {
+			log.Printf("[llm] failed to cache the stream of %s, err=%v", c.id, err)
+			return
+		}
+		c.set(ctx, key, msg)
+	}()
+	return out, nil
+}
+
+func (c *cachedModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	cm, err := c.cm.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedModel{cm: cm, store: c.store, id: c.id, temperature: c.temperature, tools: tools}, nil
+}
+
+func (c *cachedModel) get(ctx context.Context, key string, opts ...model.Option) *schema.Message {
+	if model.GetImplSpecificOptions(&cacheOptions{}, opts...).bypass {
+		return nil
+	}
+	b, err := c.store.Get(ctx, key)
+	if err != nil {
+		log.Printf("[llm] failed to read the cache of %s, err=%v", c.id, err)
+		return nil
+	}
+	if b == nil {
+		return nil
+	}
+	msg := &schema.Message{}
+	if err = json.Unmarshal(b, msg); err != nil {
+		log.Printf("[llm] invalid cached response %s, err=%v", key, err)
+		return nil
+	}
+	// a cached response costs no tokens
+	if msg.ResponseMeta != nil {
+		msg.ResponseMeta.Usage = nil
+	}
+	return msg
+}
+
+func (c *cachedModel) set(ctx context.Context, key string, msg *schema.Message) {
+	b, err := json.Marshal(msg)
+	if err == nil {
+		// the caller may be gone once a stream ends, the response is still worth keeping
+		err = c.store.Set(context.WithoutCancel(ctx), key, b)
+	}
+	if err != nil {
+		log.Printf("[llm] failed to cache the response of %s, err=%v", c.id, err)
+	}
+}
+
+type cacheRequest struct {
+	ID          string             `json:"id"`
+	Messages    []*cacheMessage    `json:"messages"`
+	Tools       []*cacheTool       `json:"tools,omitempty"`
+	Model       *string            `json:"model,omitempty"`
+	MaxTokens   *int               `json:"max_tokens,omitempty"`
+	TopP        *float32           `json:"top_p,omitempty"`
+	Stop        []string           `json:"stop,omitempty"`
+	ToolChoice  *schema.ToolChoice `json:"tool_choice,omitempty"`
+	AllowedTool []string           `json:"allowed_tools,omitempty"`
+}
+
+// cacheMessage leaves out the ids of tool calls, they differ on every run and do not
+// change the answer.
+type cacheMessage struct {
+	Role       schema.RoleType           `json:"role"`
+	Content    string                    `json:"content,omitempty"`
+	Name       string                    `json:"name,omitempty"`
+	ToolCalls  []string                  `json:"tool_calls,omitempty"`
+	ToolName   string                    `json:"tool_name,omitempty"`
+	UserInput  []schema.MessageInputPart `json:"user_input,omitempty"`
+	MultiParts []schema.ChatMessagePart  `json:"multi_content,omitempty"`
+}
+
+type cacheTool struct {
+	Name   string `json:"name"`
+	Desc   string `json:"desc,omitempty"`
+	Params any    `json:"params,omitempty"`
+}
+
+// key returns false if the call is not deterministic or cannot be hashed.
+func (c *cachedModel) key(input []*schema.Message, opts ...model.Option) (string, bool) {
+	options := model.GetCommonOptions(&model.Options{Temperature: c.temperature, Tools: c.tools}, opts...)
+	if options.Temperature == nil || *options.Temperature != 0 {
+		return "", false
+	}
+
+	req := &cacheRequest{
+		ID:          c.id,
+		Model:       options.Model,
+		MaxTokens:   options.MaxTokens,
+		TopP:        options.TopP,
+		Stop:        options.Stop,
+		ToolChoice:  options.ToolChoice,
+		AllowedTool: options.AllowedToolNames,
+	}
+	for _, m := range input {
+		cm := &cacheMessage{
+			Role:       m.Role,
+			Content:    m.Content,
+			Name:       m.Name,
+			ToolName:   m.ToolName,
+			UserInput:  m.UserInputMultiContent,
+			MultiParts: m.MultiContent,
+		}
+		for _, tc := range m.ToolCalls {
+			cm.ToolCalls = append(cm.ToolCalls, tc.Function.Name+"("+tc.Function.Arguments+")")
+		}
+		req.Messages = append(req.Messages, cm)
+	}
+	for _, t := range options.Tools {
+		ct := &cacheTool{Name: t.Name, Desc: t.Desc}
+		if t.ParamsOneOf != nil {
+			params, err := t.ParamsOneOf.ToJSONSchema()
+			if err != nil {
+				return "", false
+			}
+			ct.Params = params
+		}
+		req.Tools = append(req.Tools, ct)
+	}
+
+	key, err := hashJSON(req)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+func hashJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cacheID identifies a model of the registry together with the options of its caller,
+// the cached responses of a model are dropped when its config changes.
+func cacheID(name string, config *Config, o *options) string {
+	id, err := hashJSON(map[string]any{
+		"provider":         config.Provider,
+		"model":            config.Model,
+		"base_url":         config.BaseURL,
+		"max_tokens":       o.maxTokens,
+		"temperature":      o.temperature,
+		"top_p":            o.topP,
+		"disable_thinking": o.disableThinking,
+		"json_schema":      o.jsonSchema,
+	})
+	if err != nil {
+		return name
+	}
+	return name + "-" + id[:16]
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package llm
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+func float32Ptr(f float32) *float32 {
+	return &f
+}
+
+// go test -v -run Test_CacheKey ./llm
+func Test_CacheKey(t *testing.T) {
+	input := []*schema.Message{schema.SystemMessage("be brief"), schema.UserMessage("hello")}
+	tool := &schema.ToolInfo{Name: "search", Desc: "search the docs", ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+		"query": {Type: schema.String, Required: true},
+	})}
+	cached := &cachedModel{id: "a", temperature: float32Ptr(0)}
+	withTools := &cachedModel{id: "a", temperature: float32Ptr(0), tools: []*schema.ToolInfo{tool}}
+	key := func(c *cachedModel, input []*schema.Message, opts ...model.Option) string {
+		key, ok := c.key(input, opts...)
+		if !ok {
+			t.Fatalf("%v is not cached", input)
+		}
+		return key
+	}
+	base := key(cached, input)
+
+	for name, other := range map[string]string{
+		"id":       key(&cachedModel{id: "b", temperature: float32Ptr(0)}, input),
+		"message":  key(cached, []*schema.Message{input[0], schema.UserMessage("hello!")}),
+		"role":     key(cached, []*schema.Message{input[0], schema.AssistantMessage("hello", nil)}),
+		"history":  key(cached, input[1:]),
+		"tools":    key(withTools, input),
+		"model":    key(cached, input, model.WithModel("other")),
+		"tokens":   key(cached, input, model.WithMaxTokens(10)),
+		"top_p":    key(cached, input, model.WithTopP(0.5)),
+		"stop":     key(cached, input, model.WithStop([]string{"\n"})),
+		"opt_tool": key(cached, input, model.WithTools([]*schema.ToolInfo{tool})),
+	} {
+		if other == base {
+			t.Errorf("%s does not change the key", name)
+		}
+	}
+	if key(cached, input, model.WithTools([]*schema.ToolInfo{tool})) != key(withTools, input) {
+		t.Error("the tools of the call and of WithTools have different keys")
+	}
+
+	// the ids of the tool calls differ on every run
+	call := func(id string) []*schema.Message {
+		return append(input, schema.AssistantMessage("", []schema.ToolCall{{ID: id, Function: schema.FunctionCall{Name: "search", Arguments: `{"query":"eino"}`}}}))
+	}
+	if key(cached, call("1")) != key(cached, call("2")) {
+		t.Error("the tool call ids change the key")
+	}
+}
+
+// go test -v -run Test_CachedModel ./llm
+func Test_CachedModel(t *testing.T) {
+	ctx := context.Background()
+	input := []*schema.Message{schema.UserMessage("hello")}
+
+	// calls with temperature 0 are answered from the cache
+	stub := &stubModel{}
+	cm := NewCached(stub, NewFileCache(t.TempDir(), 0), "a", float32Ptr(0))
+	for i := 0; i < 2; i++ {
+		if msg, err := cm.Generate(ctx, input); err != nil || msg.Content != "ok" {
+			t.Fatalf("msg: %v, err: %v", msg, err)
+		}
+	}
+	if stub.calls != 1 {
+		t.Fatalf("calls: %d", stub.calls)
+	}
+	if _, err := cm.Generate(ctx, input, WithCacheBypass()); err != nil || stub.calls != 2 {
+		t.Fatalf("calls: %d, err: %v", stub.calls, err)
+	}
+
+	// a temperature above 0, of the call or of the model, or none, always reaches the model
+	for _, c := range []struct {
+		temperature *float32
+		opts        []model.Option
+	}{
+		{float32Ptr(0), []model.Option{model.WithTemperature(0.7)}},
+		{float32Ptr(0.7), nil},
+		{nil, nil},
+	} {
+		stub = &stubModel{}
+		cm = NewCached(stub, NewFileCache(t.TempDir(), 0), "a", c.temperature)
+		for i := 0; i < 2; i++ {
+			if _, err := cm.Generate(ctx, input, c.opts...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if stub.calls != 2 {
+			t.Fatalf("temperature %v, calls: %d", c.temperature, stub.calls)
+		}
+	}
+}
+
+// go test -v -run Test_FileCacheTTL ./llm
+func Test_FileCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	store := NewFileCache(t.TempDir(), time.Hour)
+	if err := store.Set(ctx, "abc", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get(ctx, "abc"); err != nil || string(value) != "value" {
+		t.Fatalf("value: %q, err: %v", value, err)
+	}
+
+	// the expired response is removed when it is read
+	expired := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(store.path("abc"), expired, expired); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get(ctx, "abc"); err != nil || value != nil {
+		t.Fatalf("value: %q, err: %v", value, err)
+	}
+	if _, err := os.Stat(store.path("abc")); !os.IsNotExist(err) {
+		t.Fatalf("expired file: %v", err)
+	}
+}
+
+// go test -v -run Test_CachedStream ./llm
+func Test_CachedStream(t *testing.T) {
+	ctx := context.Background()
+	input := []*schema.Message{schema.UserMessage("hello")}
+	answer := schema.AssistantMessage("a streamed answer of a few chunks", nil)
+	answer.ReasoningContent = "thinking about it"
+	// the script answers once, the second call fails if it reaches the model
+	script := &fake.Script{Responses: []*fake.Response{{Message: answer}}}
+	cm := NewCached(fake.NewChatModel(script), NewFileCache(t.TempDir(), 0), "a", float32Ptr(0))
+
+	read := func() (*schema.Message, int) {
+		sr, err := cm.Stream(ctx, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var chunks []*schema.Message
+		for {
+			chunk, err := sr.Recv()
+			if err != nil {
+				break
+			}
+			chunks = append(chunks, chunk)
+		}
+		msg, err := schema.ConcatMessages(chunks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg, len(chunks)
+	}
+
+	msg, chunks := read()
+	if msg.Content != answer.Content || chunks < 2 {
+		t.Fatalf("msg: %v, chunks: %d", msg, chunks)
+	}
+	// the stream is cached once it is read to its end, by a goroutine
+	deadline := time.Now().Add(time.Second)
+	for {
+		if key, _ := cm.(*cachedModel).key(input); cm.(*cachedModel).get(ctx, key) != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the stream was not cached")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	msg, chunks = read()
+	if msg.Content != answer.Content || msg.ReasoningContent != answer.ReasoningContent || chunks != 1 {
+		t.Fatalf("msg: %v, chunks: %d", msg, chunks)
+	}
+	if msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
+		t.Fatalf("a cached response has usage: %+v", msg.ResponseMeta.Usage)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// fallbackModel calls the models in order until one of them answers. A stream fails over
+// only until its first chunk, after that the error is passed on to the reader.
+// The caller cancelling ctx stops the fail over.
+type fallbackModel struct {
+	names  []string
+	models []model.ToolCallingChatModel
+}
+
+var _ model.ToolCallingChatModel = (*fallbackModel)(nil)
+
+// NewFallback returns models[0] if there is a single model.
+func NewFallback(names []string, models []model.ToolCallingChatModel) model.ToolCallingChatModel {
+	if len(models) == 1 {
+		return models[0]
+	}
+	return &fallbackModel{names: names, models: models}
+}
+
+func (f *fallbackModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	var errs []error
+	for i, m := range f.models {
+		msg, err := m.Generate(ctx, input, opts...)
+		if err == nil {
+			return msg, nil
+		}
+		if errs = f.failed(ctx, errs, i, err); errs == nil {
+			return nil, err
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (f *fallbackModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	var errs []error
+	for i, m := range f.models {
+		sr, err := m.Stream(ctx, input, opts...)
+		if err == nil {
+			sr, err = peek(sr, nil)
+		}
+		if err == nil {
+			return sr, nil
+		}
+		if errs = f.failed(ctx, errs, i, err); errs == nil {
+			return nil, err
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (f *fallbackModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	models := make([]model.ToolCallingChatModel, 0, len(f.models))
+	for i, m := range f.models {
+		tm, err := m.WithTools(tools)
+		if err != nil {
+			return nil, fmt.Errorf("model %s: %w", f.names[i], err)
+		}
+		models = append(models, tm)
+	}
+	return &fallbackModel{names: f.names, models: models}, nil
+}
+
+// failed adds the error of model i to errs, it returns nil if the caller is gone.
+func (f *fallbackModel) failed(ctx context.Context, errs []error, i int, err error) []error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	if i+1 < len(f.models) {
+		log.Printf("[llm] model %s failed, falling back to %s, err=%v", f.names[i], f.names[i+1], err)
+	}
+	return append(errs, fmt.Errorf("model %s: %w", f.names[i], err))
+}
+
+// peek waits for the first chunk of sr, so that a request failing before it answers
+// fails here. The returned stream still starts with the first chunk.
+// done, if not nil, is called once sr is closed.
+func peek(sr *schema.StreamReader[*schema.Message], done func()) (*schema.StreamReader[*schema.Message], error) {
+	if done == nil {
+		done = func() {}
+	}
+	first, err := sr.Recv()
+	if errors.Is(err, io.EOF) {
+		sr.Close()
+		done()
+		return schema.StreamReaderFromArray[*schema.Message](nil), nil
+	}
+	if err != nil {
+		sr.Close()
+		done()
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer done()
+		defer sw.Close()
+		defer sr.Close()
+
+		if sw.Send(first, nil) {
+			return
+		}
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if closed := sw.Send(chunk, err); closed || err != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+// go test -v -run Test_Fallback ./llm
+func Test_Fallback(t *testing.T) {
+	ctx := context.Background()
+	input := []*schema.Message{schema.UserMessage("hello")}
+	names := []string{"primary", "secondary", "tertiary"}
+	// answer scripts one answer per call, a fake model without responses fails every call
+	answer := func(content string, calls int) *fake.Script {
+		s := &fake.Script{}
+		for i := 0; i < calls; i++ {
+			s.Responses = append(s.Responses, &fake.Response{Message: schema.AssistantMessage(content, nil)})
+		}
+		return s
+	}
+	failing := func() model.ToolCallingChatModel { return fake.NewChatModel(&fake.Script{}) }
+
+	// the primary answers, the secondary is not called
+	secondary := answer("secondary", 1)
+	cm := NewFallback(names[:2], []model.ToolCallingChatModel{fake.NewChatModel(answer("primary", 1)), fake.NewChatModel(secondary)})
+	if msg, err := cm.Generate(ctx, input); err != nil || msg.Content != "primary" {
+		t.Fatalf("msg: %v, err: %v", msg, err)
+	}
+	if _, err := fake.NewChatModel(secondary).Generate(ctx, input); err != nil {
+		t.Fatalf("secondary was called: %v", err)
+	}
+
+	// the primary fails, the secondary answers before the tertiary
+	cm = NewFallback(names, []model.ToolCallingChatModel{failing(), fake.NewChatModel(answer("secondary", 2)), fake.NewChatModel(answer("tertiary", 2))})
+	if msg, err := cm.Generate(ctx, input); err != nil || msg.Content != "secondary" {
+		t.Fatalf("msg: %v, err: %v", msg, err)
+	}
+	sr, err := cm.Stream(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := schema.ConcatMessageStream(sr); err != nil || msg.Content != "secondary" {
+		t.Fatalf("msg: %v, err: %v", msg, err)
+	}
+
+	// every model fails, the error names all of them
+	cm = NewFallback(names[:2], []model.ToolCallingChatModel{failing(), failing()})
+	if _, err := cm.Generate(ctx, input); err == nil || !strings.Contains(err.Error(), "model primary") || !strings.Contains(err.Error(), "model secondary") {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the caller is gone, the secondary is not called
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	stub := &stubModel{}
+	cm = NewFallback(names[:2], []model.ToolCallingChatModel{&stubModel{errs: []error{context.Canceled}}, stub})
+	if _, err := cm.Generate(cancelled, input); err != context.Canceled || stub.calls != 0 {
+		t.Fatalf("calls: %d, err: %v", stub.calls, err)
+	}
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package llm builds chat models of several providers from named configs, see Registry.
+// A model can fall back to other models when it fails, and routes pick the model of an
+// agent or a purpose, e.g. a cheap model for query rewriting.
+// Like fake it is in the shared module, ai-code-example and eino_assistant use the same registry.
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/model/ark"
+	"github.com/cloudwego/eino-ext/components/model/ollama"
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino-ext/components/model/qwen"
+	"github.com/cloudwego/eino/components/model"
+	arkmodel "github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+type Provider string
+
+const (
+	ProviderArk    Provider = "ark"
+	ProviderOpenAI Provider = "openai"
+	ProviderAzure  Provider = "azure"
+	ProviderQwen   Provider = "qwen"
+	ProviderOllama Provider = "ollama"
+	ProviderFake   Provider = "fake"
+)
+
+const (
+	defaultQwenBaseURL   = "https://dashscope.aliyuncs.com/compatible-mode/v1"
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultArkTimeout    = 10 * time.Minute
+)
+
+// Config is one named model.
+type Config struct {
+	Provider Provider `yaml:"provider"`
+	Model    string   `yaml:"model"`
+	APIKey   string   `yaml:"api_key"`
+	BaseURL  string   `yaml:"base_url"`
+	// Region is the region of ark, default cn-beijing.
+	Region string `yaml:"region"`
+	// APIVersion is the api version of azure.
+	APIVersion string `yaml:"api_version"`
+	// Timeout of a request, a request that times out fails over like any other error.
+	Timeout     time.Duration `yaml:"timeout"`
+	MaxTokens   *int          `yaml:"max_tokens"`
+	Temperature *float32      `yaml:"temperature"`
+	TopP        *float32      `yaml:"top_p"`
+	// Retry configures the retries and the circuit breaker of the model, see NewResilient.
+	Retry *RetryConfig `yaml:"retry"`
+	// Fallback names the models tried in order when this model fails.
+	Fallback []string `yaml:"fallback"`
+}
+
+func (c *Config) validate() error {
+	switch c.Provider {
+	case ProviderFake:
+		return nil
+	case ProviderArk, ProviderOpenAI, ProviderAzure, ProviderQwen:
+		if c.APIKey == "" {
+			return fmt.Errorf("api_key of provider %s is required", c.Provider)
+		}
+	case ProviderOllama:
+	case "":
+		return fmt.Errorf("provider is required")
+	default:
+		return fmt.Errorf("unknown provider %q", c.Provider)
+	}
+	if c.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if c.Provider == ProviderAzure && c.BaseURL == "" {
+		return fmt.Errorf("base_url of provider azure is required")
+	}
+	return nil
+}
+
+// Option changes the config of a model for one caller, e.g. the sampling of an agent.
+type Option func(o *options)
+
+type options struct {
+	maxTokens       *int
+	temperature     *float32
+	topP            *float32
+	disableThinking bool
+	jsonSchema      *openai.ChatCompletionResponseFormatJSONSchema
+}
+
+func WithMaxTokens(maxTokens int) Option {
+	return func(o *options) {
+		o.maxTokens = &maxTokens
+	}
+}
+
+func WithTemperature(temp float32) Option {
+	return func(o *options) {
+		o.temperature = &temp
+	}
+}
+
+func WithTopP(topP float32) Option {
+	return func(o *options) {
+		o.topP = &topP
+	}
+}
+
+// WithDisableThinking turns off the thinking of ark models, other providers ignore it.
+func WithDisableThinking() Option {
+	return func(o *options) {
+		o.disableThinking = true
+	}
+}
+
+// WithResponseFormatJsonSchema is supported by ark and the openai compatible providers.
+func WithResponseFormatJsonSchema(schema *openai.ChatCompletionResponseFormatJSONSchema) Option {
+	return func(o *options) {
+		o.jsonSchema = schema
+	}
+}
+
+func newOptions(config *Config, opts ...Option) *options {
+	o := &options{maxTokens: config.MaxTokens, temperature: config.Temperature, topP: config.TopP}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// New creates the model of config without retries and fallback, the options override the config.
+func New(ctx context.Context, config *Config, opts ...Option) (model.ToolCallingChatModel, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	o := newOptions(config, opts...)
+
+	switch config.Provider {
+	case ProviderArk:
+		conf := &ark.ChatModelConfig{
+			APIKey:      config.APIKey,
+			BaseURL:     config.BaseURL,
+			Region:      config.Region,
+			Model:       config.Model,
+			MaxTokens:   o.maxTokens,
+			Temperature: o.temperature,
+			TopP:        o.topP,
+			// the requests are retried by NewResilient
+			RetryTimes: new(int),
+		}
+		if config.Timeout > 0 {
+			conf.HTTPClient = newHTTPClient(config.Timeout)
+		} else {
+			conf.HTTPClient = newHTTPClient(defaultArkTimeout)
+		}
+		if o.disableThinking {
+			conf.Thinking = &arkmodel.Thinking{
+				Type: arkmodel.ThinkingTypeDisabled,
+			}
+		}
+		if o.jsonSchema != nil {
+			conf.ResponseFormat = &ark.ResponseFormat{
+				Type: arkmodel.ResponseFormatJSONSchema,
+				JSONSchema: &arkmodel.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:        o.jsonSchema.Name,
+					Description: o.jsonSchema.Description,
+					Schema:      o.jsonSchema.JSONSchema,
+					Strict:      o.jsonSchema.Strict,
+				},
+			}
+		}
+		return ark.NewChatModel(ctx, conf)
+
+	case ProviderOpenAI, ProviderAzure:
+		conf := &openai.ChatModelConfig{
+			APIKey:      config.APIKey,
+			HTTPClient:  newHTTPClient(config.Timeout),
+			ByAzure:     config.Provider == ProviderAzure,
+			BaseURL:     config.BaseURL,
+			APIVersion:  config.APIVersion,
+			Model:       config.Model,
+			MaxTokens:   o.maxTokens,
+			Temperature: o.temperature,
+			TopP:        o.topP,
+		}
+		if o.jsonSchema != nil {
+			conf.ResponseFormat = &openai.ChatCompletionResponseFormat{
+				Type:       openai.ChatCompletionResponseFormatTypeJSONSchema,
+				JSONSchema: o.jsonSchema,
+			}
+		}
+		return openai.NewChatModel(ctx, conf)
+
+	case ProviderQwen:
+		conf := &qwen.ChatModelConfig{
+			APIKey:      config.APIKey,
+			HTTPClient:  newHTTPClient(config.Timeout),
+			BaseURL:     config.BaseURL,
+			Model:       config.Model,
+			MaxTokens:   o.maxTokens,
+			Temperature: o.temperature,
+			TopP:        o.topP,
+		}
+		if conf.BaseURL == "" {
+			conf.BaseURL = defaultQwenBaseURL
+		}
+		if o.jsonSchema != nil {
+			conf.ResponseFormat = &openai.ChatCompletionResponseFormat{
+				Type:       openai.ChatCompletionResponseFormatTypeJSONSchema,
+				JSONSchema: o.jsonSchema,
+			}
+		}
+		return qwen.NewChatModel(ctx, conf)
+
+	case ProviderOllama:
+		conf := &ollama.ChatModelConfig{
+			BaseURL:    config.BaseURL,
+			HTTPClient: newHTTPClient(config.Timeout),
+			Model:      config.Model,
+		}
+		if conf.BaseURL == "" {
+			conf.BaseURL = defaultOllamaBaseURL
+		}
+		if o.maxTokens != nil || o.temperature != nil || o.topP != nil {
+			conf.Options = &ollama.Options{}
+			if o.maxTokens != nil {
+				conf.Options.NumPredict = *o.maxTokens
+			}
+			if o.temperature != nil {
+				conf.Options.Temperature = *o.temperature
+			}
+			if o.topP != nil {
+				conf.Options.TopP = *o.topP
+			}
+		}
+		return ollama.NewChatModel(ctx, conf)
+
+	default: // ProviderFake
+		return fake.NewChatModelFromEnv()
+	}
+}
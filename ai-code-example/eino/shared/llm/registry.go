@@ -0,0 +1,333 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jettjia/ai-code-example/eino/shared/fake"
+)
+
+// ConfigEnv names the models file read by FromEnv.
+const ConfigEnv = "LLM_CONFIG"
+
+// File is the models file, ${VAR} in it is replaced by the env var, e.g.
+//
+//	default: ark
+//	models:
+//	  ark: {provider: ark, model: ep-xxx, api_key: "${ARK_API_KEY}", fallback: [qwen]}
+//	  qwen: {provider: qwen, model: qwen-max, api_key: "${DASHSCOPE_API_KEY}"}
+//	  local: {provider: ollama, model: llama3}
+//	routes:
+//	  query_rewrite: local
+//	cache: {backend: file, ttl: 24h}
+type File struct {
+	// Default is the model of the agents without route, the only model if there is one.
+	Default string             `yaml:"default"`
+	Models  map[string]*Config `yaml:"models"`
+	// Routes maps an agent name or a purpose to a model name.
+	Routes map[string]string `yaml:"routes"`
+	// Cache caches the responses of the calls with temperature 0, nil disables it.
+	Cache *CacheConfig `yaml:"cache"`
+}
+
+// Registry creates the models of a File by name, it is safe for concurrent use.
+type Registry struct {
+	file  *File
+	cache CacheStore
+
+	mu       sync.Mutex
+	models   map[string]model.ToolCallingChatModel
+	breakers map[string]*Breaker
+}
+
+func NewRegistry(file *File) (*Registry, error) {
+	if len(file.Models) == 0 {
+		return nil, errors.New("no model configured")
+	}
+	if file.Default == "" && len(file.Models) == 1 {
+		for name := range file.Models {
+			file.Default = name
+		}
+	}
+
+	var errs []error
+	if _, ok := file.Models[file.Default]; !ok {
+		errs = append(errs, fmt.Errorf("default model %q is not configured", file.Default))
+	}
+	for _, name := range sortedKeys(file.Models) {
+		config := file.Models[name]
+		// the fake model replaces all of them, their keys are not needed
+		if err := config.validate(); err != nil && !fake.Enabled() {
+			errs = append(errs, fmt.Errorf("model %s: %w", name, err))
+		}
+		for _, fallback := range config.Fallback {
+			if _, ok := file.Models[fallback]; !ok || fallback == name {
+				errs = append(errs, fmt.Errorf("model %s: invalid fallback %q", name, fallback))
+			}
+		}
+	}
+	for _, route := range sortedKeys(file.Routes) {
+		if _, ok := file.Models[file.Routes[route]]; !ok {
+			errs = append(errs, fmt.Errorf("route %s: model %q is not configured", route, file.Routes[route]))
+		}
+	}
+	cache, err := NewCacheStore(file.Cache)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return &Registry{
+		file:     file,
+		cache:    cache,
+		models:   make(map[string]model.ToolCallingChatModel),
+		breakers: make(map[string]*Breaker),
+	}, nil
+}
+
+// Load reads the models file at path.
+func Load(path string) (*Registry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read models file: %w", err)
+	}
+	file := &File{}
+	if err = yaml.Unmarshal([]byte(os.ExpandEnv(string(b))), file); err != nil {
+		return nil, fmt.Errorf("invalid models file %s: %w", path, err)
+	}
+	return NewRegistry(file)
+}
+
+// FromEnv loads the file of LLM_CONFIG, without it the models are named after their
+// provider and configured by the env vars the examples used so far:
+//
+//	ark:    ARK_MODEL, ARK_API_KEY, ARK_BASE_URL, ARK_REGION
+//	openai: OPENAI_MODEL, OPENAI_API_KEY, OPENAI_BASE_URL, OPENAI_BY_AZURE
+//	qwen:   QWEN_MODEL, DASHSCOPE_API_KEY, QWEN_BASE_URL
+//	ollama: OLLAMA_MODEL, OLLAMA_BASE_URL
+//
+// The first of them configured is the default, LLM_FALLBACK lists the models the default
+// falls back to and LLM_ROUTES the routes, e.g. query_rewrite=ollama,web_search=qwen.
+// LLM_CACHE=file or redis enables the cache, with LLM_CACHE_DIR, LLM_CACHE_REDIS_ADDR and
+// LLM_CACHE_TTL, e.g. 24h.
+func FromEnv() (*Registry, error) {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return Load(path)
+	}
+
+	file := &File{Models: make(map[string]*Config), Routes: make(map[string]string)}
+	add := func(name string, config *Config) {
+		if config.Model == "" {
+			return
+		}
+		file.Models[name] = config
+		if file.Default == "" {
+			file.Default = name
+		}
+	}
+	add(string(ProviderArk), &Config{
+		Provider: ProviderArk,
+		Model:    os.Getenv("ARK_MODEL"),
+		APIKey:   os.Getenv("ARK_API_KEY"),
+		BaseURL:  os.Getenv("ARK_BASE_URL"),
+		Region:   os.Getenv("ARK_REGION"),
+	})
+	openaiConfig := &Config{
+		Provider: ProviderOpenAI,
+		Model:    os.Getenv("OPENAI_MODEL"),
+		APIKey:   os.Getenv("OPENAI_API_KEY"),
+		BaseURL:  os.Getenv("OPENAI_BASE_URL"),
+	}
+	if os.Getenv("OPENAI_BY_AZURE") == "true" {
+		openaiConfig.Provider = ProviderAzure
+	}
+	add(string(ProviderOpenAI), openaiConfig)
+	add(string(ProviderQwen), &Config{
+		Provider: ProviderQwen,
+		Model:    os.Getenv("QWEN_MODEL"),
+		APIKey:   os.Getenv("DASHSCOPE_API_KEY"),
+		BaseURL:  os.Getenv("QWEN_BASE_URL"),
+	})
+	add(string(ProviderOllama), &Config{
+		Provider: ProviderOllama,
+		Model:    os.Getenv("OLLAMA_MODEL"),
+		BaseURL:  os.Getenv("OLLAMA_BASE_URL"),
+	})
+	if fake.Enabled() && file.Default == "" {
+		add(string(ProviderFake), &Config{Provider: ProviderFake, Model: "fake"})
+	}
+
+	if def := file.Models[file.Default]; def != nil {
+		def.Fallback = splitList(os.Getenv("LLM_FALLBACK"))
+	}
+	for _, route := range splitList(os.Getenv("LLM_ROUTES")) {
+		name, target, ok := strings.Cut(route, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid route %q of LLM_ROUTES, want name=model", route)
+		}
+		file.Routes[strings.TrimSpace(name)] = strings.TrimSpace(target)
+	}
+	if backend := os.Getenv("LLM_CACHE"); backend != "" {
+		file.Cache = &CacheConfig{
+			Backend:   backend,
+			Dir:       os.Getenv("LLM_CACHE_DIR"),
+			RedisAddr: os.Getenv("LLM_CACHE_REDIS_ADDR"),
+		}
+		if ttl := os.Getenv("LLM_CACHE_TTL"); ttl != "" {
+			d, err := time.ParseDuration(ttl)
+			if err != nil {
+				return nil, fmt.Errorf("invalid LLM_CACHE_TTL: %w", err)
+			}
+			file.Cache.TTL = d
+		}
+	}
+	return NewRegistry(file)
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultRegistry *Registry
+	defaultErr      error
+)
+
+// Default is the registry of FromEnv, loaded once.
+func Default() (*Registry, error) {
+	defaultOnce.Do(func() {
+		defaultRegistry, defaultErr = FromEnv()
+	})
+	return defaultRegistry, defaultErr
+}
+
+// Route returns the model name of an agent or purpose, the default model if it has no route.
+func (r *Registry) Route(name string) string {
+	if target, ok := r.file.Routes[name]; ok {
+		return target
+	}
+	return r.file.Default
+}
+
+// For returns the model routed to an agent or purpose, see Route.
+func (r *Registry) For(ctx context.Context, route string, opts ...Option) (model.ToolCallingChatModel, error) {
+	return r.Get(ctx, r.Route(route), opts...)
+}
+
+// Get returns the model of name, the default model if name is empty, together with its
+// fallbacks. Only the fallbacks of the model itself are used, not theirs. Every model retries
+// by itself before falling back, and the instances of a model share its circuit breaker.
+// With a cache, the responses of calls with temperature 0 are cached, see NewCached.
+// Models without options are created once and shared.
+//
+// EINO_FAKE=true replaces every model by the fake model, and FAKE_CASSETTE_MODE records or
+// replays the requests, see the fake package.
+func (r *Registry) Get(ctx context.Context, name string, opts ...Option) (model.ToolCallingChatModel, error) {
+	if name == "" {
+		name = r.file.Default
+	}
+	config, ok := r.file.Models[name]
+	if !ok {
+		return nil, fmt.Errorf("model %q is not configured", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cm, ok := r.models[name]; ok && len(opts) == 0 {
+		return cm, nil
+	}
+
+	cassette, err := fake.NewCassetteFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if cassette.Replaying() {
+		return cassette.WrapChatModel(nil)
+	}
+
+	names := append([]string{name}, config.Fallback...)
+	models := make([]model.ToolCallingChatModel, 0, len(names))
+	for _, n := range names {
+		cm, err := r.create(ctx, n, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create model %s: %w", n, err)
+		}
+		models = append(models, cm)
+	}
+	cm := NewFallback(names, models)
+	if r.cache != nil && !fake.Enabled() {
+		o := newOptions(config, opts...)
+		cm = NewCached(cm, r.cache, cacheID(name, config, o), o.temperature)
+	}
+	if cm, err = cassette.WrapChatModel(cm); err != nil {
+		return nil, err
+	}
+
+	if len(opts) == 0 {
+		r.models[name] = cm
+	}
+	return cm, nil
+}
+
+// create is called with r.mu held.
+func (r *Registry) create(ctx context.Context, name string, opts ...Option) (model.ToolCallingChatModel, error) {
+	config := r.file.Models[name]
+	if fake.Enabled() || config.Provider == ProviderFake {
+		// the fake model answers from a script, retrying would skip its answers
+		return fake.NewChatModelFromEnv()
+	}
+	cm, err := New(ctx, config, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	breaker, ok := r.breakers[name]
+	if !ok {
+		retry := config.Retry.withDefaults()
+		breaker = NewBreaker(retry.FailureThreshold, retry.OpenDuration)
+		r.breakers[name] = breaker
+	}
+	return NewResilient(cm, name, config.Retry, breaker), nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
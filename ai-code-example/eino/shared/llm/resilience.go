@@ -0,0 +1,370 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ErrCircuitOpen is returned without calling the model while its breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// RetryConfig retries the failed requests of a model and stops calling it while it keeps
+// failing, see NewResilient. The zero value uses the defaults.
+type RetryConfig struct {
+	// Timeout is the deadline of one attempt, for a stream only until its first chunk.
+	// Zero means none, the http timeout of Config still applies.
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxAttempts includes the first attempt, default 3, 1 disables retries.
+	MaxAttempts int `yaml:"max_attempts"`
+	// BaseDelay doubles after every attempt up to MaxDelay, the actual delay is a random
+	// duration below it. Default 500ms and 10s. Retry-After of the response takes precedence.
+	BaseDelay time.Duration `yaml:"base_delay"`
+	MaxDelay  time.Duration `yaml:"max_delay"`
+	// FailureThreshold consecutive failed calls open the breaker for OpenDuration, then one
+	// call is let through to probe the model. Default 5 and 30s, a negative threshold disables it.
+	FailureThreshold int           `yaml:"failure_threshold"`
+	OpenDuration     time.Duration `yaml:"open_duration"`
+}
+
+func (c *RetryConfig) withDefaults() *RetryConfig {
+	config := RetryConfig{}
+	if c != nil {
+		config = *c
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = 500 * time.Millisecond
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 10 * time.Second
+	}
+	if config.FailureThreshold == 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = 30 * time.Second
+	}
+	return &config
+}
+
+// Breaker is the circuit breaker of a model, shared by all the instances of the model.
+type Breaker struct {
+	threshold    int
+	openDuration time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewBreaker returns nil, which never opens, if threshold is negative.
+func NewBreaker(threshold int, openDuration time.Duration) *Breaker {
+	if threshold < 0 {
+		return nil
+	}
+	return &Breaker{threshold: threshold, openDuration: openDuration}
+}
+
+// allow reports whether a call may go through, after the open duration only one call
+// probes the model until it is done.
+func (b *Breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// release ends a call that says nothing about the model, e.g. cancelled by the caller or
+// rejected as a bad request: the failures are kept and the next call may probe again.
+func (b *Breaker) release() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *Breaker) done(failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.openDuration)
+	}
+}
+
+// resilientModel retries the retryable errors of cm, see retryable. A stream is only retried
+// until its first chunk, an error after it is passed on to the reader.
+type resilientModel struct {
+	cm      model.ToolCallingChatModel
+	name    string
+	config  *RetryConfig
+	breaker *Breaker
+}
+
+var _ model.ToolCallingChatModel = (*resilientModel)(nil)
+
+// NewResilient wraps cm with per attempt deadlines, retries with exponential backoff and
+// the breaker, which may be nil. name is used in the logs.
+func NewResilient(cm model.ToolCallingChatModel, name string, config *RetryConfig, breaker *Breaker) model.ToolCallingChatModel {
+	return &resilientModel{cm: cm, name: name, config: config.withDefaults(), breaker: breaker}
+}
+
+func (r *resilientModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	var msg *schema.Message
+	err := r.do(ctx, func(a *attempt) (err error) {
+		defer a.done()
+		msg, err = r.cm.Generate(a.ctx, input, opts...)
+		return err
+	})
+	return msg, err
+}
+
+func (r *resilientModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	var sr *schema.StreamReader[*schema.Message]
+	err := r.do(ctx, func(a *attempt) (err error) {
+		sr, err = r.cm.Stream(a.ctx, input, opts...)
+		if err != nil {
+			a.done()
+			return err
+		}
+		// the ctx of the attempt lives until the stream ends, but the timeout stops at its first chunk
+		if sr, err = peek(sr, a.done); err == nil {
+			a.started()
+		}
+		return err
+	})
+	return sr, err
+}
+
+func (r *resilientModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	cm, err := r.cm.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &resilientModel{cm: cm, name: r.name, config: r.config, breaker: r.breaker}, nil
+}
+
+// do calls call until it succeeds, fails with an error that is not retryable or runs out
+// of attempts. call must call attempt.done once it no longer needs the ctx of the attempt.
+func (r *resilientModel) do(ctx context.Context, call func(a *attempt) error) error {
+	if !r.breaker.allow() {
+		return fmt.Errorf("model %s: %w", r.name, ErrCircuitOpen)
+	}
+
+	for i := 1; ; i++ {
+		status := &responseStatus{}
+		err := call(r.newAttempt(context.WithValue(ctx, statusKey{}, status)))
+		if err == nil {
+			r.breaker.done(false)
+			return nil
+		}
+		if ctx.Err() != nil {
+			// the caller is gone, that says nothing about the model
+			r.breaker.release()
+			return err
+		}
+		code, retryAfter := status.get()
+		retry := retryable(err, code)
+		if !retry {
+			r.breaker.release()
+			return err
+		}
+		if i >= r.config.MaxAttempts {
+			r.breaker.done(true)
+			return err
+		}
+
+		delay := r.backoff(i, retryAfter)
+		log.Printf("[llm] model %s failed, retrying in %v (%d/%d), err=%v", r.name, delay, i, r.config.MaxAttempts, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			r.breaker.release()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt is the ctx of one call, cancelled when the timeout of the config passes.
+type attempt struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	timer  *time.Timer
+}
+
+func (r *resilientModel) newAttempt(ctx context.Context) *attempt {
+	a := &attempt{}
+	a.ctx, a.cancel = context.WithCancelCause(ctx)
+	if r.config.Timeout > 0 {
+		a.timer = time.AfterFunc(r.config.Timeout, func() {
+			a.cancel(context.DeadlineExceeded)
+		})
+	}
+	return a
+}
+
+// started stops the timeout.
+func (a *attempt) started() {
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+}
+
+func (a *attempt) done() {
+	a.started()
+	a.cancel(nil)
+}
+
+func (r *resilientModel) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	delay := r.config.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > r.config.MaxDelay {
+		delay = r.config.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// retryable reports whether err is worth another attempt: rate limits, server errors,
+// timeouts and broken connections. Client errors such as a bad request or a wrong key are not.
+// The caller cancelling is handled before, a cancelled attempt has timed out.
+func retryable(err error, code int) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if code == 0 {
+		code = statusCodeOf(err)
+	}
+	if code != 0 {
+		return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// statusCodeOf finds the HTTPStatusCode field the errors of the ark and openai clients have.
+func statusCodeOf(err error) int {
+	for err != nil {
+		v := reflect.ValueOf(err)
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				break
+			}
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct {
+			if f := v.FieldByName("HTTPStatusCode"); f.IsValid() && f.CanInt() {
+				return int(f.Int())
+			}
+		}
+		err = errors.Unwrap(err)
+	}
+	return 0
+}
+
+type statusKey struct{}
+
+// responseStatus is the last response of an attempt, recorded by statusTransport, as the
+// clients of the providers do not return Retry-After.
+type responseStatus struct {
+	mu         sync.Mutex
+	code       int
+	retryAfter time.Duration
+}
+
+func (s *responseStatus) get() (int, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.code, s.retryAfter
+}
+
+// statusTransport records the status and Retry-After of the responses of a request whose
+// context carries a responseStatus.
+type statusTransport struct {
+	base http.RoundTripper
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	status, ok := req.Context().Value(statusKey{}).(*responseStatus)
+	if !ok || err != nil {
+		return resp, err
+	}
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	status.code = resp.StatusCode
+	status.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	return resp, nil
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// newHTTPClient is the client of the providers, it records the responses for the retries.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: &statusTransport{base: http.DefaultTransport}}
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// apiError has the status field of the errors of the ark client.
+type apiError struct {
+	HTTPStatusCode int
+}
+
+func (e *apiError) Error() string {
+	return "status " + strconv.Itoa(e.HTTPStatusCode)
+}
+
+// stubModel fails with the first errors, then answers.
+type stubModel struct {
+	errs  []error
+	calls int
+}
+
+func (s *stubModel) Generate(_ context.Context, _ []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return nil, s.errs[s.calls-1]
+	}
+	return schema.AssistantMessage("ok", nil), nil
+}
+
+func (s *stubModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := s.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (s *stubModel) WithTools(_ []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return s, nil
+}
+
+// go test -v -run Test_Resilient ./llm
+func Test_Resilient(t *testing.T) {
+	ctx := context.Background()
+	config := &RetryConfig{BaseDelay: time.Millisecond, FailureThreshold: 2, OpenDuration: time.Hour}
+	tooMany := &apiError{HTTPStatusCode: 429}
+	badRequest := &apiError{HTTPStatusCode: 400}
+	unavailable := &apiError{HTTPStatusCode: 503}
+
+	// rate limits are retried
+	stub := &stubModel{errs: []error{tooMany, tooMany}}
+	if _, err := NewResilient(stub, "a", config, nil).Generate(ctx, nil); err != nil || stub.calls != 3 {
+		t.Fatalf("calls: %d, err: %v", stub.calls, err)
+	}
+
+	// bad requests are not
+	stub = &stubModel{errs: []error{badRequest}}
+	if _, err := NewResilient(stub, "b", config, nil).Generate(ctx, nil); !errors.Is(err, badRequest) || stub.calls != 1 {
+		t.Fatalf("calls: %d, err: %v", stub.calls, err)
+	}
+
+	// the breaker opens after two failed calls
+	once := *config
+	once.MaxAttempts = 1
+	stub = &stubModel{errs: []error{unavailable, unavailable, unavailable}}
+	cm := NewResilient(stub, "c", &once, NewBreaker(once.FailureThreshold, once.OpenDuration))
+	for i := 0; i < 2; i++ {
+		if _, err := cm.Stream(ctx, nil); !errors.Is(err, unavailable) {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	if _, err := cm.Generate(ctx, nil); !errors.Is(err, ErrCircuitOpen) || stub.calls != 2 {
+		t.Fatalf("calls: %d, err: %v", stub.calls, err)
+	}
+}
+
+// go test -v -run Test_BreakerRelease ./llm
+func Test_BreakerRelease(t *testing.T) {
+	config := &RetryConfig{MaxAttempts: 1, FailureThreshold: 2, OpenDuration: time.Millisecond}
+	unavailable := &apiError{HTTPStatusCode: 503}
+	badRequest := &apiError{HTTPStatusCode: 400}
+
+	// a bad request does not reset the failures
+	breaker := NewBreaker(config.FailureThreshold, time.Hour)
+	stub := &stubModel{errs: []error{unavailable, badRequest, unavailable}}
+	cm := NewResilient(stub, "a", config, breaker)
+	for i := 0; i < 3; i++ {
+		cm.Generate(context.Background(), nil)
+	}
+	if _, err := cm.Generate(context.Background(), nil); !errors.Is(err, ErrCircuitOpen) || stub.calls != 3 {
+		t.Fatalf("calls: %d, err: %v", stub.calls, err)
+	}
+
+	// a cancelled probe keeps the breaker open
+	breaker = NewBreaker(config.FailureThreshold, config.OpenDuration)
+	ctx, cancel := context.WithCancel(context.Background())
+	stub = &stubModel{errs: []error{unavailable, unavailable, context.Canceled}}
+	cm = NewResilient(&cancelModel{stubModel: stub, cancel: cancel}, "b", config, breaker)
+	for i := 0; i < 2; i++ {
+		cm.Generate(context.Background(), nil)
+	}
+	time.Sleep(2 * config.OpenDuration)
+	if _, err := cm.Generate(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("probe: %v", err)
+	}
+	if breaker.failures < config.FailureThreshold || breaker.probing {
+		t.Fatalf("failures: %d, probing: %v after the cancelled probe", breaker.failures, breaker.probing)
+	}
+}
+
+// cancelModel cancels the ctx of the caller on its third call.
+type cancelModel struct {
+	*stubModel
+	cancel context.CancelFunc
+}
+
+func (c *cancelModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	if c.calls == 2 {
+		c.cancel()
+	}
+	return c.stubModel.Generate(ctx, input, opts...)
+}
+
+// httpModel calls url once per request with the http client of the providers.
+type httpModel struct {
+	stubModel
+	client *http.Client
+	url    string
+}
+
+func (h *httpModel) Generate(ctx context.Context, _ []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// like the provider clients, the error says nothing about Retry-After
+		return nil, errors.New(resp.Status)
+	}
+	return schema.AssistantMessage("ok", nil), nil
+}
+
+// go test -v -run Test_RetryAfter ./llm
+func Test_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// the backoff alone would wait up to an hour
+	config := &RetryConfig{BaseDelay: time.Hour, MaxDelay: time.Hour}
+	cm := NewResilient(&httpModel{client: newHTTPClient(0), url: srv.URL}, "a", config, nil)
+	start := time.Now()
+	if _, err := cm.Generate(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); calls.Load() != 2 || elapsed < 900*time.Millisecond || elapsed > 5*time.Second {
+		t.Fatalf("calls: %d, elapsed: %v", calls.Load(), elapsed)
+	}
+}
+
+// go test -v -run Test_ParseRetryAfter ./llm
+func Test_ParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("3"); d != 3*time.Second {
+		t.Fatalf("seconds: %v", d)
+	}
+	if d := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)); d <= 50*time.Second || d > time.Minute {
+		t.Fatalf("http date: %v", d)
+	}
+	for _, value := range []string{"", "0", "-1", "soon"} {
+		if d := parseRetryAfter(value); d != 0 {
+			t.Fatalf("%q: %v", value, d)
+		}
+	}
+}